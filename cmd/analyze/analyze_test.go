@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/gob"
 	"os"
 	"path/filepath"
 	"strings"
@@ -76,6 +75,17 @@ func TestScanPathConcurrentBasic(t *testing.T) {
 	if bytes := atomic.LoadInt64(&bytesScanned); bytes == 0 {
 		t.Fatalf("expected byte counter to increase")
 	}
+	for _, entry := range result.Entries {
+		if entry.Name == "nested" {
+			if entry.FileCount != 2 {
+				t.Fatalf("expected nested dir file count 2, got %d", entry.FileCount)
+			}
+			if entry.ModTime.IsZero() {
+				t.Fatalf("expected nested dir mod time to be recorded")
+			}
+		}
+	}
+
 	foundSymlink := false
 	for _, entry := range result.Entries {
 		if strings.HasSuffix(entry.Name, " →") {
@@ -384,29 +394,15 @@ func TestLoadCacheExpiresWhenDirectoryChanges(t *testing.T) {
 		t.Fatalf("chtimes cache: %v", err)
 	}
 
-	file, err := os.Open(cachePath)
+	entry, err := readCacheFile(cachePath)
 	if err != nil {
-		t.Fatalf("open cache: %v", err)
-	}
-	var entry cacheEntry
-	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
-		t.Fatalf("decode cache: %v", err)
+		t.Fatalf("read cache: %v", err)
 	}
-	_ = file.Close()
 
 	entry.ScanTime = time.Now().Add(-8 * 24 * time.Hour)
 
-	tmp := cachePath + ".tmp"
-	f, err := os.Create(tmp)
-	if err != nil {
-		t.Fatalf("create tmp cache: %v", err)
-	}
-	if err := gob.NewEncoder(f).Encode(&entry); err != nil {
-		t.Fatalf("encode tmp cache: %v", err)
-	}
-	_ = f.Close()
-	if err := os.Rename(tmp, cachePath); err != nil {
-		t.Fatalf("rename tmp cache: %v", err)
+	if err := writeCacheFile(cachePath, *entry); err != nil {
+		t.Fatalf("write cache: %v", err)
 	}
 
 	if _, err := loadCacheFromDisk(target); err == nil {
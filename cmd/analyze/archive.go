@@ -0,0 +1,483 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// archiveSeparator joins an archive file path and a directory inside it.
+// "/x/a.zip::lib/js" browses the "lib/js" folder of /x/a.zip.
+const archiveSeparator = "::"
+
+// archiveMember is one file or directory stored in an archive.
+type archiveMember struct {
+	Name           string // Slash-separated path inside the archive
+	Size           int64  // Uncompressed size
+	CompressedSize int64  // Packed bytes; estimated from the gzip stream for tar.gz, 0 for plain tar
+	ModTime        time.Time
+	IsDir          bool
+}
+
+// archiveIndex is the parsed member list of an archive file.
+type archiveIndex struct {
+	Path      string
+	Format    string
+	DiskSize  int64 // Archive size on disk
+	ModTime   time.Time
+	TotalSize int64 // Sum of uncompressed member sizes
+	Members   []archiveMember
+	lastUse   uint64 // archiveIndexUses when last loaded, for LRU eviction
+}
+
+// Ratio returns uncompressed/compressed size for the whole archive.
+func (idx *archiveIndex) Ratio() float64 {
+	if idx == nil || idx.DiskSize <= 0 || idx.TotalSize <= 0 {
+		return 0
+	}
+	return float64(idx.TotalSize) / float64(idx.DiskSize)
+}
+
+var (
+	archiveIndexMu    sync.Mutex
+	archiveIndexCache = make(map[string]*archiveIndex)
+	archiveIndexUses  uint64
+	archiveRatioCache = make(map[string]archiveRatio) // Ratios of archives seen in listings
+)
+
+// archiveRatio is a whole-archive ratio measured without indexing members.
+type archiveRatio struct {
+	modTime time.Time
+	size    int64
+	ratio   float64
+}
+
+// archiveFormat returns the supported format for a path, or "" when it is not a browsable archive.
+func archiveFormat(p string) string {
+	lower := strings.ToLower(p)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	}
+	if zipArchiveExts[strings.ToLower(path.Ext(lower))] {
+		return "zip"
+	}
+	return ""
+}
+
+// isArchivePath reports whether a real file path can be browsed as an archive.
+func isArchivePath(p string) bool {
+	if _, _, ok := splitArchivePath(p); ok {
+		return false // Nested archives are not supported.
+	}
+	return archiveFormat(p) != ""
+}
+
+// isUnsupportedArchivePath reports archives we recognize but cannot read with the standard library.
+func isUnsupportedArchivePath(p string) bool {
+	return unsupportedArchiveExts[strings.ToLower(path.Ext(p))]
+}
+
+// archiveBrowsePath builds the virtual path for a folder inside an archive.
+func archiveBrowsePath(archivePath, inner string) string {
+	return archivePath + archiveSeparator + inner
+}
+
+// splitArchivePath splits a virtual archive path into the archive file and inner folder.
+func splitArchivePath(p string) (archivePath, inner string, ok bool) {
+	archivePath, inner, ok = strings.Cut(p, archiveSeparator)
+	if !ok || archiveFormat(archivePath) == "" {
+		return "", "", false
+	}
+	return archivePath, strings.Trim(inner, "/"), true
+}
+
+// isArchiveBrowsePath reports whether a path points inside an archive.
+func isArchiveBrowsePath(p string) bool {
+	_, _, ok := splitArchivePath(p)
+	return ok
+}
+
+// cleanMemberName normalizes a member name and drops unsafe prefixes.
+func cleanMemberName(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = path.Clean("/" + name)
+	return strings.TrimPrefix(name, "/")
+}
+
+// loadArchiveIndex reads an archive's member list, reusing the cached index while the file is unchanged.
+func loadArchiveIndex(archivePath string, filesScanned, bytesScanned *int64) (*archiveIndex, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	archiveIndexMu.Lock()
+	if idx, ok := archiveIndexCache[archivePath]; ok && idx.ModTime.Equal(info.ModTime()) && idx.DiskSize == info.Size() {
+		archiveIndexUses++
+		idx.lastUse = archiveIndexUses
+		archiveIndexMu.Unlock()
+		return idx, nil
+	}
+	archiveIndexMu.Unlock()
+
+	idx, err := readArchiveIndex(archivePath, info, filesScanned, bytesScanned)
+	if err != nil {
+		return nil, err
+	}
+
+	archiveIndexMu.Lock()
+	defer archiveIndexMu.Unlock()
+	delete(archiveIndexCache, archivePath) // A stale copy must not count against the limit
+	for len(archiveIndexCache) >= maxCachedArchives {
+		oldest := ""
+		for key, cached := range archiveIndexCache {
+			if oldest == "" || cached.lastUse < archiveIndexCache[oldest].lastUse {
+				oldest = key
+			}
+		}
+		delete(archiveIndexCache, oldest)
+	}
+	archiveIndexUses++
+	idx.lastUse = archiveIndexUses
+	archiveIndexCache[archivePath] = idx
+	return idx, nil
+}
+
+// cachedArchiveIndex returns a previously loaded index without touching disk.
+func cachedArchiveIndex(archivePath string) *archiveIndex {
+	archiveIndexMu.Lock()
+	defer archiveIndexMu.Unlock()
+	return archiveIndexCache[archivePath]
+}
+
+// forgetArchiveIndex drops a cached index so the next scan re-reads the archive.
+func forgetArchiveIndex(archivePath string) {
+	archiveIndexMu.Lock()
+	defer archiveIndexMu.Unlock()
+	delete(archiveIndexCache, archivePath)
+}
+
+func readArchiveIndex(archivePath string, info os.FileInfo, filesScanned, bytesScanned *int64) (*archiveIndex, error) {
+	idx := &archiveIndex{
+		Path:     archivePath,
+		Format:   archiveFormat(archivePath),
+		DiskSize: info.Size(),
+		ModTime:  info.ModTime(),
+	}
+
+	add := func(member archiveMember) {
+		member.Name = cleanMemberName(member.Name)
+		if member.Name == "" {
+			return
+		}
+		idx.Members = append(idx.Members, member)
+		if !member.IsDir {
+			idx.TotalSize += member.Size
+			if filesScanned != nil {
+				atomic.AddInt64(filesScanned, 1)
+			}
+			if bytesScanned != nil {
+				atomic.AddInt64(bytesScanned, member.Size)
+			}
+		}
+	}
+
+	switch idx.Format {
+	case "zip":
+		// The central directory lists sizes, so no member data is decompressed.
+		r, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("open zip: %w", err)
+		}
+		defer r.Close() //nolint:errcheck
+		for _, f := range r.File {
+			add(archiveMember{
+				Name:           f.Name,
+				Size:           int64(f.UncompressedSize64),
+				CompressedSize: int64(f.CompressedSize64),
+				ModTime:        f.Modified,
+				IsDir:          f.FileInfo().IsDir(),
+			})
+		}
+	case "tar", "tar.gz":
+		file, err := os.Open(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close() //nolint:errcheck
+
+		var stream io.Reader = file
+		var packed *countingReader
+		if idx.Format == "tar.gz" {
+			// gzip reads a ByteReader byte by byte, so the count tracks
+			// how much of the compressed stream each member used.
+			packed = &countingReader{r: bufio.NewReader(file)}
+			gz, err := gzip.NewReader(packed)
+			if err != nil {
+				return nil, fmt.Errorf("open gzip: %w", err)
+			}
+			defer gz.Close() //nolint:errcheck
+			stream = gz
+		}
+
+		// tar.Reader skips member data on Next, streaming through the archive once.
+		tr := tar.NewReader(stream)
+		last, mark := -1, int64(0)
+		for {
+			hdr, err := tr.Next()
+			if packed != nil {
+				if last >= 0 {
+					idx.Members[last].CompressedSize = packed.n - mark
+				}
+				last, mark = -1, packed.n
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("read tar: %w", err)
+			}
+			switch hdr.Typeflag {
+			case tar.TypeDir:
+				add(archiveMember{Name: hdr.Name, ModTime: hdr.ModTime, IsDir: true})
+			case tar.TypeReg:
+				before := len(idx.Members)
+				add(archiveMember{Name: hdr.Name, Size: hdr.Size, ModTime: hdr.ModTime})
+				if len(idx.Members) > before {
+					last = before
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported archive: %s", archivePath)
+	}
+
+	return idx, nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+// probeArchiveRatios measures the archives listed in entries so their rows
+// show a ratio before they are opened. Plain tar has none.
+func probeArchiveRatios(entries []dirEntry) {
+	for _, entry := range entries {
+		if entry.IsDir || !isArchivePath(entry.Path) {
+			continue
+		}
+		info, err := os.Stat(entry.Path)
+		if err != nil || info.Size() <= 0 {
+			continue
+		}
+		archiveIndexMu.Lock()
+		cached, ok := archiveRatioCache[entry.Path]
+		archiveIndexMu.Unlock()
+		if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+			continue
+		}
+		ratio := measureArchiveRatio(entry.Path, info.Size())
+		archiveIndexMu.Lock()
+		archiveRatioCache[entry.Path] = archiveRatio{modTime: info.ModTime(), size: info.Size(), ratio: ratio}
+		archiveIndexMu.Unlock()
+	}
+}
+
+// measureArchiveRatio reads only a zip's central directory or a gzip trailer.
+func measureArchiveRatio(archivePath string, diskSize int64) float64 {
+	switch archiveFormat(archivePath) {
+	case "zip":
+		r, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return 0
+		}
+		defer r.Close() //nolint:errcheck
+		var total uint64
+		for _, f := range r.File {
+			total += f.UncompressedSize64
+		}
+		return float64(total) / float64(diskSize)
+	case "tar.gz":
+		// The trailer holds the last member's size modulo 4 GiB. It is only
+		// trusted when even a highly compressed stream could not have wrapped,
+		// and when it fits a single-member stream; otherwise the row waits for
+		// the archive to be indexed.
+		if diskSize < 18 || diskSize*maxPlausibleGzipRatio >= 1<<32 {
+			return 0
+		}
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return 0
+		}
+		defer f.Close() //nolint:errcheck
+		var trailer [4]byte
+		if _, err := f.ReadAt(trailer[:], diskSize-4); err != nil {
+			return 0
+		}
+		ratio := float64(binary.LittleEndian.Uint32(trailer[:])) / float64(diskSize)
+		if ratio < minPlausibleGzipRatio {
+			return 0
+		}
+		return ratio
+	}
+	return 0
+}
+
+// archiveFileRatio returns the ratio for an archive's row in its parent
+// listing, from its index when it was opened, else from the probe.
+func archiveFileRatio(archivePath string) float64 {
+	archiveIndexMu.Lock()
+	defer archiveIndexMu.Unlock()
+	if idx := archiveIndexCache[archivePath]; idx != nil {
+		return idx.Ratio()
+	}
+	return archiveRatioCache[archivePath].ratio
+}
+
+// scanArchive lists one folder inside an archive in the same shape as a directory scan.
+func scanArchive(archivePath, inner string, filesScanned, bytesScanned *int64) (scanResult, error) {
+	idx, err := loadArchiveIndex(archivePath, filesScanned, bytesScanned)
+	if err != nil {
+		return scanResult{}, err
+	}
+	return listArchiveDir(idx, inner), nil
+}
+
+// listArchiveDir groups archive members under inner into immediate children.
+func listArchiveDir(idx *archiveIndex, inner string) scanResult {
+	inner = strings.Trim(inner, "/")
+	prefix := ""
+	if inner != "" {
+		prefix = inner + "/"
+	}
+
+	children := make(map[string]*dirEntry)
+	childDirs := make(map[string]map[string]bool)
+	var order []string
+	var largeFiles []fileEntry
+	var total, totalFiles int64
+
+	for _, member := range idx.Members {
+		rest, ok := strings.CutPrefix(member.Name, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		head, tail, nested := strings.Cut(rest, "/")
+		childName := prefix + head
+
+		entry, exists := children[head]
+		if !exists {
+			entry = &dirEntry{
+				Name:  head,
+				Path:  archiveBrowsePath(idx.Path, childName),
+				IsDir: nested || member.IsDir,
+			}
+			children[head] = entry
+			order = append(order, head)
+		}
+		if nested {
+			entry.IsDir = true
+		}
+		if member.ModTime.After(entry.ModTime) {
+			entry.ModTime = member.ModTime
+		}
+
+		if member.IsDir {
+			if nested {
+				addArchiveSubdir(childDirs, head, tail)
+			}
+			continue
+		}
+
+		entry.Size += member.Size
+		entry.CompressedSize += member.CompressedSize
+		total += member.Size
+		totalFiles++
+		if nested {
+			entry.FileCount++
+			if dir := path.Dir(tail); dir != "." {
+				addArchiveSubdir(childDirs, head, dir)
+			}
+		}
+
+		largeFiles = append(largeFiles, fileEntry{
+			Name: path.Base(member.Name),
+			Path: archiveBrowsePath(idx.Path, member.Name),
+			Size: member.Size,
+		})
+	}
+
+	entries := make([]dirEntry, 0, len(order))
+	for _, name := range order {
+		entry := children[name]
+		entry.DirCount = int64(len(childDirs[name]))
+		entries = append(entries, *entry)
+	}
+	entries = selectTopEntries(entries)
+
+	sortLargeFiles(largeFiles)
+	if len(largeFiles) > maxLargeFiles {
+		largeFiles = largeFiles[:maxLargeFiles]
+	}
+
+	return scanResult{
+		Entries:    entries,
+		LargeFiles: largeFiles,
+		TotalSize:  total,
+		TotalFiles: totalFiles,
+	}
+}
+
+// addArchiveSubdir records dir and its parents as subdirectories of child.
+func addArchiveSubdir(childDirs map[string]map[string]bool, child, dir string) {
+	dir = strings.Trim(dir, "/")
+	if dir == "" {
+		return
+	}
+	set := childDirs[child]
+	if set == nil {
+		set = make(map[string]bool)
+		childDirs[child] = set
+	}
+	for dir != "." && dir != "" && !set[dir] {
+		set[dir] = true
+		dir = path.Dir(dir)
+	}
+}
+
+// archiveRealPath maps a virtual archive path to the archive file on disk.
+func archiveRealPath(p string) string {
+	if archivePath, _, ok := splitArchivePath(p); ok {
+		return archivePath
+	}
+	return p
+}
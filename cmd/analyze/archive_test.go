@@ -0,0 +1,278 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip create %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("zip write %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write zip: %v", err)
+	}
+}
+
+func writeTestTarGz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("tar header %s: %v", name, err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("tar write %s: %v", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar close: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write tar.gz: %v", err)
+	}
+}
+
+func TestArchiveFormat(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/x/a.zip", "zip"},
+		{"/x/A.ZIP", "zip"},
+		{"/x/lib.jar", "zip"},
+		{"/x/a.tar", "tar"},
+		{"/x/a.tar.gz", "tar.gz"},
+		{"/x/a.tgz", "tar.gz"},
+		{"/x/a.7z", ""},
+		{"/x/a.gz", ""},
+		{"/x/readme.txt", ""},
+	}
+	for _, tt := range tests {
+		if got := archiveFormat(tt.path); got != tt.want {
+			t.Errorf("archiveFormat(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	if !isUnsupportedArchivePath("/x/a.7z") {
+		t.Errorf("expected .7z to be recognized as unsupported")
+	}
+}
+
+func TestSplitArchivePath(t *testing.T) {
+	archivePath, inner, ok := splitArchivePath("/x/a.zip::lib/js/")
+	if !ok || archivePath != "/x/a.zip" || inner != "lib/js" {
+		t.Fatalf("splitArchivePath = %q, %q, %v", archivePath, inner, ok)
+	}
+	if _, _, ok := splitArchivePath("/x/plain/dir"); ok {
+		t.Fatalf("plain path should not be an archive path")
+	}
+	if isArchivePath("/x/a.zip::nested.zip") {
+		t.Fatalf("nested archives should not be browsable")
+	}
+	if got := archiveRealPath("/x/a.zip::lib"); got != "/x/a.zip" {
+		t.Fatalf("archiveRealPath = %q", got)
+	}
+}
+
+func TestScanArchiveZip(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "bundle.zip")
+	writeTestZip(t, archive, map[string]string{
+		"readme.txt":       "hello",
+		"lib/a.js":         strings.Repeat("a", 4000),
+		"lib/sub/b.js":     strings.Repeat("b", 2000),
+		"../escape/c.bin":  "c",
+		"assets/logo.png/": "",
+	})
+	t.Cleanup(func() { forgetArchiveIndex(archive) })
+
+	var files, bytesScanned int64
+	result, err := scanArchive(archive, "", &files, &bytesScanned)
+	if err != nil {
+		t.Fatalf("scanArchive: %v", err)
+	}
+	if result.TotalFiles != 4 || files != 4 {
+		t.Fatalf("expected 4 files, got result=%d counter=%d", result.TotalFiles, files)
+	}
+	if result.TotalSize != 6006 {
+		t.Fatalf("expected total 6006, got %d", result.TotalSize)
+	}
+
+	byName := map[string]dirEntry{}
+	for _, e := range result.Entries {
+		byName[e.Name] = e
+	}
+	lib, ok := byName["lib"]
+	if !ok || !lib.IsDir {
+		t.Fatalf("expected lib dir entry, got %+v", result.Entries)
+	}
+	if lib.Size != 6000 || lib.FileCount != 2 || lib.DirCount != 1 {
+		t.Fatalf("unexpected lib entry: %+v", lib)
+	}
+	if lib.CompressedSize <= 0 || lib.CompressedSize >= lib.Size {
+		t.Fatalf("expected repetitive content to compress, got %d of %d", lib.CompressedSize, lib.Size)
+	}
+	if _, ok := byName["escape"]; !ok {
+		t.Fatalf("expected ../ prefix to be stripped, got %v", entryNames(result.Entries))
+	}
+	if lib.Path != archiveBrowsePath(archive, "lib") {
+		t.Fatalf("unexpected lib path %q", lib.Path)
+	}
+
+	nested, err := scanArchive(archive, "lib", nil, nil)
+	if err != nil {
+		t.Fatalf("scanArchive lib: %v", err)
+	}
+	if len(nested.Entries) != 2 || nested.TotalSize != 6000 {
+		t.Fatalf("unexpected lib listing: %+v", nested)
+	}
+	if len(nested.LargeFiles) == 0 || nested.LargeFiles[0].Name != "a.js" {
+		t.Fatalf("expected a.js as largest file, got %+v", nested.LargeFiles)
+	}
+}
+
+func TestScanArchiveTarGz(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "logs.tar.gz")
+	writeTestTarGz(t, archive, map[string]string{
+		"logs/app.log":   strings.Repeat("x", 10000),
+		"logs/old/1.log": strings.Repeat("y", 5000),
+		"VERSION":        "1",
+	})
+	t.Cleanup(func() { forgetArchiveIndex(archive) })
+
+	result, err := scanArchive(archive, "", nil, nil)
+	if err != nil {
+		t.Fatalf("scanArchive: %v", err)
+	}
+	if result.TotalSize != 15001 {
+		t.Fatalf("expected total 15001, got %d", result.TotalSize)
+	}
+	if len(result.Entries) != 2 || result.Entries[0].Name != "logs" {
+		t.Fatalf("unexpected entries: %v", entryNames(result.Entries))
+	}
+
+	idx := cachedArchiveIndex(archive)
+	if idx == nil {
+		t.Fatalf("expected index to be cached")
+	}
+	if idx.Ratio() <= 1 {
+		t.Fatalf("expected compression ratio > 1, got %.2f", idx.Ratio())
+	}
+}
+
+func TestTarGzMembersGetPackedSizes(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "logs.tar.gz")
+	writeTestTarGz(t, archive, map[string]string{
+		"logs/app.log": strings.Repeat("x", 200000),
+		"logs/rand":    strings.Repeat("0123456789abcdef", 4000),
+	})
+	t.Cleanup(func() { forgetArchiveIndex(archive) })
+
+	result, err := scanArchive(archive, "", nil, nil)
+	if err != nil {
+		t.Fatalf("scanArchive: %v", err)
+	}
+	logs := result.Entries[0]
+	if logs.CompressedSize <= 0 || logs.CompressedSize >= logs.Size {
+		t.Errorf("logs packed %d of %d, want a real ratio", logs.CompressedSize, logs.Size)
+	}
+	info, _ := os.Stat(archive)
+	if logs.CompressedSize > info.Size() {
+		t.Errorf("members packed into %d bytes, more than the %d byte archive", logs.CompressedSize, info.Size())
+	}
+}
+
+func TestArchiveRowRatioBeforeOpening(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "site.zip")
+	tgzPath := filepath.Join(dir, "logs.tgz")
+	writeTestZip(t, zipPath, map[string]string{"index.html": strings.Repeat("a", 50000)})
+	writeTestTarGz(t, tgzPath, map[string]string{"app.log": strings.Repeat("b", 50000)})
+
+	probeArchiveRatios([]dirEntry{{Name: "site.zip", Path: zipPath}, {Name: "logs.tgz", Path: tgzPath}, {Name: "sub", Path: dir, IsDir: true}})
+	for _, p := range []string{zipPath, tgzPath} {
+		if ratio := archiveFileRatio(p); ratio <= 10 {
+			t.Errorf("ratio for %s = %.1f, want a highly compressed archive", filepath.Base(p), ratio)
+		}
+	}
+	if ratio := archiveFileRatio(filepath.Join(dir, "missing.zip")); ratio != 0 {
+		t.Errorf("unprobed archive ratio = %v", ratio)
+	}
+}
+
+func TestMeasureArchiveRatioSkipsUntrustedTrailers(t *testing.T) {
+	dir := t.TempDir()
+	multi := filepath.Join(dir, "multi.tar.gz")
+	var buf bytes.Buffer
+	for _, content := range []string{strings.Repeat("x", 200000), "tail"} {
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(multi, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if ratio := measureArchiveRatio(multi, int64(buf.Len())); ratio != 0 {
+		t.Errorf("multi-member ratio = %.2f, want 0 until indexed", ratio)
+	}
+	// Large enough that the 32-bit trailer may have wrapped.
+	if ratio := measureArchiveRatio(multi, 1<<30); ratio != 0 {
+		t.Errorf("large archive ratio = %.2f, want 0 until indexed", ratio)
+	}
+}
+
+func TestArchiveIndexCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for i := range maxCachedArchives + 1 {
+		p := filepath.Join(dir, strings.Repeat("a", i+1)+".zip")
+		writeTestZip(t, p, map[string]string{"f": "x"})
+		paths = append(paths, p)
+		t.Cleanup(func() { forgetArchiveIndex(p) })
+	}
+	for _, p := range paths[:maxCachedArchives] {
+		if _, err := loadArchiveIndex(p, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Touch the oldest so the second one becomes least recently used.
+	if _, err := loadArchiveIndex(paths[0], nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadArchiveIndex(paths[maxCachedArchives], nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if cachedArchiveIndex(paths[0]) == nil || cachedArchiveIndex(paths[1]) != nil {
+		t.Errorf("expected %s evicted and %s kept", filepath.Base(paths[1]), filepath.Base(paths[0]))
+	}
+}
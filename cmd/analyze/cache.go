@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/gob"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -82,6 +81,9 @@ func ensureOverviewSnapshotCacheLocked() error {
 }
 
 func getOverviewSizeStorePath() (string, error) {
+	if diskCacheDisabled {
+		return "", errCacheDisabled
+	}
 	cacheDir, err := getCacheDir()
 	if err != nil {
 		return "", err
@@ -173,6 +175,9 @@ func getCacheDir() (string, error) {
 }
 
 func getCachePath(path string) (string, error) {
+	if diskCacheDisabled {
+		return "", errCacheDisabled
+	}
 	cacheDir, err := getCacheDir()
 	if err != nil {
 		return "", err
@@ -182,21 +187,26 @@ func getCachePath(path string) (string, error) {
 	return filepath.Join(cacheDir, filename), nil
 }
 
-func loadCacheFromDisk(path string) (*cacheEntry, error) {
-	cachePath, err := getCachePath(path)
+// readCacheEntry reads the cache file for path. A file written for another
+// directory, through a hash collision or a copied file, counts as a miss.
+func readCacheEntry(path string) (entry *cacheEntry, cachePath string, err error) {
+	cachePath, err = getCachePath(path)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-
-	file, err := os.Open(cachePath)
+	entry, err = readCacheFile(cachePath)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	defer file.Close() //nolint:errcheck
+	if entry.Path != path {
+		return nil, "", fmt.Errorf("cache file belongs to %s", entry.Path)
+	}
+	return entry, cachePath, nil
+}
 
-	var entry cacheEntry
-	decoder := gob.NewDecoder(file)
-	if err := decoder.Decode(&entry); err != nil {
+func loadCacheFromDisk(path string) (*cacheEntry, error) {
+	entry, cachePath, err := readCacheEntry(path)
+	if err != nil {
 		return nil, err
 	}
 
@@ -212,11 +222,20 @@ func loadCacheFromDisk(path string) (*cacheEntry, error) {
 		}
 	}
 
-	if time.Since(entry.ScanTime) > 7*24*time.Hour {
+	if time.Since(entry.ScanTime) > cacheMaxAge {
 		return nil, fmt.Errorf("cache expired: too old")
 	}
 
-	return &entry, nil
+	touchCacheFile(cachePath)
+	return entry, nil
+}
+
+// cacheDiskUsage is the running size of the scan cache dir. The dir is listed on
+// the first save and again only when the running total goes over budget.
+var cacheDiskUsage struct {
+	sync.Mutex
+	dir   string // Cache dir the total was listed from
+	total int64
 }
 
 func saveCacheToDisk(path string, result scanResult) error {
@@ -231,6 +250,7 @@ func saveCacheToDisk(path string, result scanResult) error {
 	}
 
 	entry := cacheEntry{
+		Path:       path,
 		Entries:    result.Entries,
 		LargeFiles: result.LargeFiles,
 		TotalSize:  result.TotalSize,
@@ -239,40 +259,73 @@ func saveCacheToDisk(path string, result scanResult) error {
 		ScanTime:   time.Now(),
 	}
 
-	file, err := os.Create(cachePath)
-	if err != nil {
+	var oldSize int64
+	if info, err := os.Stat(cachePath); err == nil {
+		oldSize = info.Size()
+	}
+	if err := writeCacheFile(cachePath, entry); err != nil {
 		return err
 	}
-	defer file.Close() //nolint:errcheck
+	var newSize int64
+	if info, err := os.Stat(cachePath); err == nil {
+		newSize = info.Size()
+	}
+	return trackCacheWrite(cachePath, newSize-oldSize)
+}
+
+// trackCacheWrite adds a write to the running cache size and trims the
+// cache dir when it no longer fits the budget.
+func trackCacheWrite(cachePath string, delta int64) error {
+	cacheDiskUsage.Lock()
+	defer cacheDiskUsage.Unlock()
 
-	encoder := gob.NewEncoder(file)
-	return encoder.Encode(entry)
+	dir := filepath.Dir(cachePath)
+	budget := cacheSizeBudget()
+	cacheDiskUsage.total += delta
+	if cacheDiskUsage.dir == dir && cacheDiskUsage.total <= budget {
+		return nil
+	}
+	_, _, remaining, err := trimCacheToBudget(dir, budget, cachePath)
+	if err != nil {
+		cacheDiskUsage.dir = ""
+		return err
+	}
+	cacheDiskUsage.dir = dir
+	cacheDiskUsage.total = remaining
+	return nil
 }
 
 // peekCacheTotalFiles attempts to read the total file count from cache,
 // ignoring expiration. Used for initial scan progress estimates.
 func peekCacheTotalFiles(path string) (int64, error) {
-	cachePath, err := getCachePath(path)
+	entry, _, err := readCacheEntry(path)
 	if err != nil {
 		return 0, err
 	}
 
-	file, err := os.Open(cachePath)
+	return entry.TotalFiles, nil
+}
+
+// peekCacheEntrySizes reads per-entry sizes from cache, ignoring expiration.
+// Used as the baseline for the growth sort.
+func peekCacheEntrySizes(path string) (map[string]int64, error) {
+	entry, _, err := readCacheEntry(path)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	defer file.Close() //nolint:errcheck
 
-	var entry cacheEntry
-	decoder := gob.NewDecoder(file)
-	if err := decoder.Decode(&entry); err != nil {
-		return 0, err
+	sizes := make(map[string]int64, len(entry.Entries))
+	for _, e := range entry.Entries {
+		sizes[e.Path] = e.Size
 	}
-
-	return entry.TotalFiles, nil
+	return sizes, nil
 }
 
 func invalidateCache(path string) {
+	if archivePath, _, ok := splitArchivePath(path); ok {
+		forgetArchiveIndex(archivePath)
+		return
+	}
 	cachePath, err := getCachePath(path)
 	if err == nil {
 		_ = os.Remove(cachePath)
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// diskCacheDisabled is set by --no-cache; scans then neither read nor write ~/.cache/mole.
+var diskCacheDisabled bool
+
+var errCacheDisabled = errors.New("disk cache disabled")
+
+// cacheFileInfo describes one scan cache file for listings and eviction.
+type cacheFileInfo struct {
+	File      string    // Cache file on disk
+	Target    string    // Scanned directory, empty if unreadable
+	Size      int64     // Cache file size
+	LastUsed  time.Time // File mtime, refreshed on every cache hit
+	ScanTime  time.Time
+	TotalSize int64 // Scanned directory size
+	Err       error // Decode error, if any
+}
+
+// cacheSizeBudget returns the total bytes scan caches may use.
+// MO_ANALYZE_CACHE_MAX_MB overrides the default.
+func cacheSizeBudget() int64 {
+	if v := os.Getenv("MO_ANALYZE_CACHE_MAX_MB"); v != "" {
+		if mb, err := strconv.ParseInt(v, 10, 64); err == nil && mb >= 0 {
+			return mb << 20
+		}
+	}
+	return defaultCacheBudget
+}
+
+// touchCacheFile marks a cache file as recently used for LRU eviction.
+func touchCacheFile(cachePath string) {
+	now := time.Now()
+	_ = os.Chtimes(cachePath, now, now)
+}
+
+// statCacheFiles lists scan cache files without decoding them, most recently used first.
+func statCacheFiles(dir string) ([]cacheFileInfo, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []cacheFileInfo
+	for _, de := range dirEntries {
+		if de.IsDir() || filepath.Ext(de.Name()) != ".cache" {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cacheFileInfo{
+			File:     filepath.Join(dir, de.Name()),
+			Size:     info.Size(),
+			LastUsed: info.ModTime(),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].LastUsed.After(files[j].LastUsed)
+	})
+	return files, nil
+}
+
+// listCacheFiles is statCacheFiles plus the decoded target of each file.
+func listCacheFiles(dir string) ([]cacheFileInfo, error) {
+	files, err := statCacheFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		data, err := os.ReadFile(files[i].File)
+		if err != nil {
+			files[i].Err = err
+			continue
+		}
+		entry, _, err := decodeCacheFile(data)
+		if err != nil {
+			files[i].Err = err
+			continue
+		}
+		files[i].Target = entry.Path
+		files[i].ScanTime = entry.ScanTime
+		files[i].TotalSize = entry.TotalSize
+	}
+	return files, nil
+}
+
+// enforceCacheBudget deletes least recently used cache files until the total
+// fits budget. keep, if set, is never evicted.
+func enforceCacheBudget(dir string, budget int64, keep string) (removed int, freed int64, err error) {
+	removed, freed, _, err = trimCacheToBudget(dir, budget, keep)
+	return removed, freed, err
+}
+
+// trimCacheToBudget is enforceCacheBudget that also returns the bytes left on disk.
+func trimCacheToBudget(dir string, budget int64, keep string) (removed int, freed, total int64, err error) {
+	files, err := statCacheFiles(dir)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	for _, f := range files {
+		total += f.Size
+	}
+	for i := len(files) - 1; i >= 0 && total > budget; i-- {
+		if files[i].File == keep {
+			continue
+		}
+		if err := os.Remove(files[i].File); err != nil && !os.IsNotExist(err) {
+			continue
+		}
+		total -= files[i].Size
+		freed += files[i].Size
+		removed++
+	}
+	return removed, freed, total, nil
+}
+
+// pruneCache removes unreadable, expired and orphaned cache files, then enforces the budget.
+func pruneCache(dir string, budget int64, now time.Time) (removed int, freed int64, err error) {
+	files, err := listCacheFiles(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, f := range files {
+		stale := f.Err != nil || f.Target == "" || now.Sub(f.ScanTime) > cacheMaxAge
+		if !stale {
+			if _, statErr := os.Stat(f.Target); os.IsNotExist(statErr) {
+				stale = true
+			}
+		}
+		if stale && os.Remove(f.File) == nil {
+			removed++
+			freed += f.Size
+		}
+	}
+	n, bytes, err := enforceCacheBudget(dir, budget, "")
+	return removed + n, freed + bytes, err
+}
+
+// clearCache removes every scan cache file and the overview size store.
+func clearCache(dir string) (removed int, freed int64, err error) {
+	files, err := statCacheFiles(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, f := range files {
+		if os.Remove(f.File) == nil {
+			removed++
+			freed += f.Size
+		}
+	}
+	overviewPath := filepath.Join(dir, overviewCacheFile)
+	if info, statErr := os.Stat(overviewPath); statErr == nil && os.Remove(overviewPath) == nil {
+		removed++
+		freed += info.Size()
+	}
+	return removed, freed, nil
+}
+
+const cacheUsage = `Usage: analyze cache <command>
+
+Commands:
+  list                  List cached scans, most recently used first
+  stats                 Show cache size and budget
+  prune [--max-mb N]    Remove stale scans and shrink the cache to the budget
+  clear                 Remove all cached scans
+
+The budget defaults to %d MB and can be set with MO_ANALYZE_CACHE_MAX_MB.
+`
+
+// runCacheCommand implements "analyze cache ..." and returns the process exit code.
+func runCacheCommand(args []string, out, errOut io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintf(out, cacheUsage, defaultCacheBudget>>20)
+		return 0
+	}
+
+	dir, err := getCacheDir()
+	if err != nil {
+		fmt.Fprintf(errOut, "cache dir unavailable: %v\n", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "list":
+		files, err := listCacheFiles(dir)
+		if err != nil {
+			fmt.Fprintf(errOut, "list cache: %v\n", err)
+			return 1
+		}
+		if len(files) == 0 {
+			fmt.Fprintln(out, "No cached scans.")
+			return 0
+		}
+		fmt.Fprintf(out, "%-10s  %-10s  %-16s  %s\n", "CACHE", "SCANNED", "LAST USED", "PATH")
+		for _, f := range files {
+			target := displayPath(f.Target)
+			if f.Err != nil {
+				target = "(unreadable: " + f.Err.Error() + ")"
+			}
+			fmt.Fprintf(out, "%-10s  %-10s  %-16s  %s\n",
+				humanizeBytes(f.Size), humanizeBytes(f.TotalSize), f.LastUsed.Format("2006-01-02 15:04"), target)
+		}
+		return 0
+
+	case "stats":
+		files, err := listCacheFiles(dir)
+		if err != nil {
+			fmt.Fprintf(errOut, "read cache: %v\n", err)
+			return 1
+		}
+		var total int64
+		var stale, broken int
+		for _, f := range files {
+			total += f.Size
+			switch {
+			case f.Err != nil:
+				broken++
+			case time.Since(f.ScanTime) > cacheMaxAge:
+				stale++
+			}
+		}
+		budget := cacheSizeBudget()
+		fmt.Fprintf(out, "Directory:  %s\n", displayPath(dir))
+		fmt.Fprintf(out, "Entries:    %d (%d stale, %d unreadable)\n", len(files), stale, broken)
+		fmt.Fprintf(out, "Size:       %s of %s budget\n", humanizeBytes(total), humanizeBytes(budget))
+		if len(files) > 0 {
+			fmt.Fprintf(out, "Last used:  %s\n", files[0].LastUsed.Format("2006-01-02 15:04"))
+		}
+		return 0
+
+	case "prune":
+		fs := flag.NewFlagSet("prune", flag.ContinueOnError)
+		fs.SetOutput(errOut)
+		maxMB := fs.Int64("max-mb", cacheSizeBudget()>>20, "size budget in MB")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+		removed, freed, err := pruneCache(dir, *maxMB<<20, time.Now())
+		if err != nil {
+			fmt.Fprintf(errOut, "prune cache: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(out, "Removed %d cache %s, freed %s.\n", removed, pluralize(removed, "entry", "entries"), humanizeBytes(freed))
+		return 0
+
+	case "clear":
+		removed, freed, err := clearCache(dir)
+		if err != nil {
+			fmt.Fprintf(errOut, "clear cache: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(out, "Removed %d cache %s, freed %s.\n", removed, pluralize(removed, "entry", "entries"), humanizeBytes(freed))
+		return 0
+	}
+
+	fmt.Fprintf(errOut, "unknown cache command %q\n\n", args[0])
+	fmt.Fprintf(errOut, cacheUsage, defaultCacheBudget>>20)
+	return 2
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSizedCacheFile(t *testing.T, dir, name string, size int, lastUsed time.Time) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	if err := os.Chtimes(path, lastUsed, lastUsed); err != nil {
+		t.Fatalf("chtimes %s: %v", name, err)
+	}
+	return path
+}
+
+func TestEnforceCacheBudgetEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	oldest := writeSizedCacheFile(t, dir, "a.cache", 100, now.Add(-3*time.Hour))
+	middle := writeSizedCacheFile(t, dir, "b.cache", 100, now.Add(-2*time.Hour))
+	newest := writeSizedCacheFile(t, dir, "c.cache", 100, now.Add(-1*time.Hour))
+	other := writeSizedCacheFile(t, dir, overviewCacheFile, 500, now.Add(-5*time.Hour))
+
+	removed, freed, err := enforceCacheBudget(dir, 150, "")
+	if err != nil {
+		t.Fatalf("enforceCacheBudget: %v", err)
+	}
+	if removed != 2 || freed != 200 {
+		t.Fatalf("removed %d files, %d bytes; want 2 files, 200 bytes", removed, freed)
+	}
+	for _, path := range []string{oldest, middle} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should have been evicted", filepath.Base(path))
+		}
+	}
+	for _, path := range []string{newest, other} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s should have been kept: %v", filepath.Base(path), err)
+		}
+	}
+}
+
+func TestEnforceCacheBudgetKeepsJustWritten(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	// A coarse mtime can make the file just written look oldest.
+	written := writeSizedCacheFile(t, dir, "a.cache", 100, now.Add(-2*time.Hour))
+	other := writeSizedCacheFile(t, dir, "b.cache", 100, now.Add(-1*time.Hour))
+
+	if removed, _, err := enforceCacheBudget(dir, 150, written); err != nil || removed != 1 {
+		t.Fatalf("removed %d, err %v", removed, err)
+	}
+	if _, err := os.Stat(written); err != nil {
+		t.Errorf("the kept file was evicted: %v", err)
+	}
+	if _, err := os.Stat(other); !os.IsNotExist(err) {
+		t.Errorf("%s should have been evicted", filepath.Base(other))
+	}
+}
+
+func TestSaveCacheEnforcesBudgetOnEverySave(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("MO_ANALYZE_CACHE_MAX_MB", "0")
+
+	first, second := t.TempDir(), t.TempDir()
+	if err := saveCacheToDisk(first, scanResult{TotalSize: 1}); err != nil {
+		t.Fatalf("saveCacheToDisk: %v", err)
+	}
+	if err := saveCacheToDisk(second, scanResult{TotalSize: 2}); err != nil {
+		t.Fatalf("saveCacheToDisk: %v", err)
+	}
+	firstCache, _ := getCachePath(first)
+	if _, err := os.Stat(firstCache); !os.IsNotExist(err) {
+		t.Errorf("the earlier cache file should be evicted by the later save")
+	}
+	secondCache, _ := getCachePath(second)
+	if _, err := os.Stat(secondCache); err != nil {
+		t.Errorf("the file just written was evicted: %v", err)
+	}
+}
+
+func TestLoadCacheRejectsAnotherDirectorysFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	source, target := t.TempDir(), t.TempDir()
+	if err := saveCacheToDisk(source, scanResult{TotalSize: 1}); err != nil {
+		t.Fatalf("saveCacheToDisk: %v", err)
+	}
+	sourceCache, _ := getCachePath(source)
+	targetCache, _ := getCachePath(target)
+	data, err := os.ReadFile(sourceCache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(targetCache, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadCacheFromDisk(target); err == nil {
+		t.Errorf("a cache file copied from %s should not load for %s", source, target)
+	}
+}
+
+func TestCacheHitRefreshesLastUsed(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	target := t.TempDir()
+	if err := saveCacheToDisk(target, scanResult{TotalSize: 1}); err != nil {
+		t.Fatalf("saveCacheToDisk: %v", err)
+	}
+	cachePath, err := getCachePath(target)
+	if err != nil {
+		t.Fatalf("getCachePath: %v", err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(cachePath, old, old); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	if _, err := loadCacheFromDisk(target); err != nil {
+		t.Fatalf("loadCacheFromDisk: %v", err)
+	}
+	info, err := os.Stat(cachePath)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if !info.ModTime().After(old) {
+		t.Errorf("cache hit did not refresh last-used time")
+	}
+}
+
+func TestPruneCacheRemovesOrphanedAndExpired(t *testing.T) {
+	dir := t.TempDir()
+	live := t.TempDir()
+	now := time.Now()
+
+	write := func(name, target string, scanTime time.Time) string {
+		path := filepath.Join(dir, name)
+		if err := writeCacheFile(path, cacheEntry{Path: target, ScanTime: scanTime}); err != nil {
+			t.Fatalf("writeCacheFile: %v", err)
+		}
+		return path
+	}
+	keep := write("keep.cache", live, now)
+	orphan := write("orphan.cache", filepath.Join(live, "gone"), now)
+	expired := write("expired.cache", live, now.Add(-cacheMaxAge-time.Hour))
+	broken := writeSizedCacheFile(t, dir, "broken.cache", 10, now)
+
+	removed, _, err := pruneCache(dir, defaultCacheBudget, now)
+	if err != nil {
+		t.Fatalf("pruneCache: %v", err)
+	}
+	if removed != 3 {
+		t.Errorf("removed %d files, want 3", removed)
+	}
+	if _, err := os.Stat(keep); err != nil {
+		t.Errorf("live cache entry removed: %v", err)
+	}
+	for _, path := range []string{orphan, expired, broken} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should have been pruned", filepath.Base(path))
+		}
+	}
+}
+
+func TestNoCacheDisablesDiskCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	diskCacheDisabled = true
+	defer func() { diskCacheDisabled = false }()
+
+	target := t.TempDir()
+	if err := saveCacheToDisk(target, scanResult{TotalSize: 1}); !errors.Is(err, errCacheDisabled) {
+		t.Fatalf("saveCacheToDisk: got %v, want errCacheDisabled", err)
+	}
+	if _, err := loadCacheFromDisk(target); !errors.Is(err, errCacheDisabled) {
+		t.Fatalf("loadCacheFromDisk: got %v, want errCacheDisabled", err)
+	}
+}
+
+func TestRunCacheCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	target := t.TempDir()
+	if err := saveCacheToDisk(target, scanResult{TotalSize: 2048}); err != nil {
+		t.Fatalf("saveCacheToDisk: %v", err)
+	}
+
+	var out, errOut bytes.Buffer
+	if code := runCacheCommand([]string{"list"}, &out, &errOut); code != 0 {
+		t.Fatalf("list exit %d: %s", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), target) {
+		t.Errorf("list output missing %s:\n%s", target, out.String())
+	}
+
+	out.Reset()
+	if code := runCacheCommand([]string{"clear"}, &out, &errOut); code != 0 {
+		t.Fatalf("clear exit %d: %s", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "Removed 1 cache entry") {
+		t.Errorf("unexpected clear output: %q", out.String())
+	}
+
+	if code := runCacheCommand([]string{"bogus"}, &out, &errOut); code != 2 {
+		t.Errorf("unknown command exit %d, want 2", code)
+	}
+}
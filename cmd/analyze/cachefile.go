@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// Cache file layout:
+//
+//	magic    [8]byte  "MOLECACH"
+//	version  uint32   cacheFormatVersion
+//	length   uint64   payload length
+//	checksum uint64   xxhash64 of payload
+//	payload  []byte   gob-encoded cacheEntry
+//
+// Files written before the header existed are bare gob streams (version 1).
+// Any change to cacheEntry or the types it holds must bump cacheFormatVersion:
+// gob fills missing fields with zeros, so older files are discarded rather
+// than decoded.
+const (
+	cacheFileMagic        = "MOLECACH"
+	cacheFormatVersion    = 3
+	cacheLegacyVersion    = 1
+	cacheFileHeaderLength = len(cacheFileMagic) + 4 + 8 + 8
+)
+
+var (
+	errCacheVersion  = errors.New("cache format version mismatch")
+	errCacheChecksum = errors.New("cache checksum mismatch")
+	errCacheCorrupt  = errors.New("cache file corrupt")
+)
+
+// encodeCacheFile serializes entry with the versioned, checksummed header.
+func encodeCacheFile(entry cacheEntry) ([]byte, error) {
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(entry); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, cacheFileHeaderLength, cacheFileHeaderLength+payload.Len())
+	copy(buf, cacheFileMagic)
+	offset := len(cacheFileMagic)
+	binary.LittleEndian.PutUint32(buf[offset:], cacheFormatVersion)
+	binary.LittleEndian.PutUint64(buf[offset+4:], uint64(payload.Len()))
+	binary.LittleEndian.PutUint64(buf[offset+12:], xxhash.Sum64(payload.Bytes()))
+	return append(buf, payload.Bytes()...), nil
+}
+
+// decodeCacheFile parses a cache file and returns the format version it was stored in.
+// Legacy headerless files report cacheLegacyVersion with a version mismatch.
+func decodeCacheFile(data []byte) (cacheEntry, int, error) {
+	var entry cacheEntry
+
+	if !bytes.HasPrefix(data, []byte(cacheFileMagic)) {
+		return entry, cacheLegacyVersion, fmt.Errorf("%w: got headerless file, want %d", errCacheVersion, cacheFormatVersion)
+	}
+
+	if len(data) < cacheFileHeaderLength {
+		return entry, 0, fmt.Errorf("%w: short header", errCacheCorrupt)
+	}
+	offset := len(cacheFileMagic)
+	version := int(binary.LittleEndian.Uint32(data[offset:]))
+	if version != cacheFormatVersion {
+		return entry, version, fmt.Errorf("%w: got %d, want %d", errCacheVersion, version, cacheFormatVersion)
+	}
+	length := binary.LittleEndian.Uint64(data[offset+4:])
+	checksum := binary.LittleEndian.Uint64(data[offset+12:])
+
+	payload := data[cacheFileHeaderLength:]
+	if uint64(len(payload)) != length {
+		return entry, version, fmt.Errorf("%w: truncated payload", errCacheCorrupt)
+	}
+	if xxhash.Sum64(payload) != checksum {
+		return entry, version, errCacheChecksum
+	}
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&entry); err != nil {
+		return entry, version, fmt.Errorf("%w: %v", errCacheCorrupt, err)
+	}
+	return entry, version, nil
+}
+
+// readCacheFile loads a cache file. Unreadable files and other versions,
+// legacy ones included, are removed so the next scan rewrites them.
+func readCacheFile(cachePath string) (*cacheEntry, error) {
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, _, err := decodeCacheFile(data)
+	if err != nil {
+		_ = os.Remove(cachePath)
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// writeCacheFile writes entry atomically via a temp file and rename,
+// so readers never observe a partially written cache.
+func writeCacheFile(cachePath string, entry cacheEntry) error {
+	data, err := encodeCacheFile(entry)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), filepath.Base(cachePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, cachePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
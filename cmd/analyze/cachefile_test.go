@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func sampleCacheEntry() cacheEntry {
+	return cacheEntry{
+		Entries:    []dirEntry{{Name: "a", Path: "/p/a", Size: 42, IsDir: true, FileCount: 3}},
+		LargeFiles: []fileEntry{{Name: "big", Path: "/p/a/big", Size: 40}},
+		TotalSize:  42,
+		TotalFiles: 3,
+		ModTime:    time.Unix(1700000000, 0),
+		ScanTime:   time.Unix(1700000100, 0),
+	}
+}
+
+func TestCacheFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.cache")
+	want := sampleCacheEntry()
+
+	if err := writeCacheFile(path, want); err != nil {
+		t.Fatalf("writeCacheFile: %v", err)
+	}
+	got, err := readCacheFile(path)
+	if err != nil {
+		t.Fatalf("readCacheFile: %v", err)
+	}
+	if got.TotalSize != want.TotalSize || len(got.Entries) != 1 || got.Entries[0].FileCount != 3 {
+		t.Fatalf("round trip mismatch: %+v", got)
+	}
+
+	matches, _ := filepath.Glob(path + ".*.tmp")
+	if len(matches) != 0 {
+		t.Errorf("temp files left behind: %v", matches)
+	}
+}
+
+func TestCacheFileDetectsCorruption(t *testing.T) {
+	data, err := encodeCacheFile(sampleCacheEntry())
+	if err != nil {
+		t.Fatalf("encodeCacheFile: %v", err)
+	}
+
+	flipped := bytes.Clone(data)
+	flipped[len(flipped)-1] ^= 0xff
+	if _, _, err := decodeCacheFile(flipped); !errors.Is(err, errCacheChecksum) {
+		t.Errorf("flipped byte: got %v, want checksum error", err)
+	}
+
+	if _, _, err := decodeCacheFile(data[:len(data)-5]); !errors.Is(err, errCacheCorrupt) {
+		t.Errorf("truncated file: got %v, want corrupt error", err)
+	}
+}
+
+func TestCacheFileVersionMismatchInvalidates(t *testing.T) {
+	data, err := encodeCacheFile(sampleCacheEntry())
+	if err != nil {
+		t.Fatalf("encodeCacheFile: %v", err)
+	}
+	binary.LittleEndian.PutUint32(data[len(cacheFileMagic):], cacheFormatVersion+1)
+
+	path := filepath.Join(t.TempDir(), "x.cache")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := readCacheFile(path); !errors.Is(err, errCacheVersion) {
+		t.Fatalf("got %v, want version error", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected mismatched cache file to be removed")
+	}
+}
+
+func TestCacheFileDiscardsLegacyGob(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.cache")
+	var legacy bytes.Buffer
+	if err := gob.NewEncoder(&legacy).Encode(sampleCacheEntry()); err != nil {
+		t.Fatalf("encode legacy: %v", err)
+	}
+	if err := os.WriteFile(path, legacy.Bytes(), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	// Legacy files predate fields such as FileCount, so they are a miss.
+	if _, err := readCacheFile(path); !errors.Is(err, errCacheVersion) {
+		t.Fatalf("got %v, want version error", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected legacy cache file to be removed")
+	}
+}
@@ -4,6 +4,13 @@ import "time"
 
 const (
 	maxEntries             = 30
+	maxFileCountEntries    = 10
+	unknownCount           = -1 // FileCount/DirCount value when a subtree was not counted
+	fileCountColumnWidth   = 10
+	maxCachedArchives      = 4
+	maxPlausibleGzipRatio  = 32  // Highest gzip trailer ratio trusted before an archive is indexed
+	minPlausibleGzipRatio  = 0.9 // Lower trailer ratios mean a multi-member stream
+	reportDepth            = 3   // Tree levels scanned for the HTML report
 	maxLargeFiles          = 20
 	barWidth               = 24
 	spotlightMinFileSize   = 100 << 20
@@ -16,6 +23,8 @@ const (
 	maxConcurrentOverview  = 8
 	batchUpdateSize        = 100
 	cacheModTimeGrace      = 30 * time.Minute
+	cacheMaxAge            = 7 * 24 * time.Hour
+	defaultCacheBudget     = 256 << 20 // Total bytes of scan cache files kept on disk
 
 	// Worker pool limits.
 	minWorkers         = 16
@@ -233,9 +242,28 @@ var skipExtensions = map[string]bool{
 	".hx":     true,
 }
 
+// Zip-based formats that can be browsed in place.
+var zipArchiveExts = map[string]bool{
+	".zip": true,
+	".jar": true,
+	".ipa": true,
+	".whl": true,
+}
+
+// Archives we recognize but cannot read with the standard library.
+var unsupportedArchiveExts = map[string]bool{
+	".7z":  true,
+	".rar": true,
+	".xz":  true,
+	".bz2": true,
+	".zst": true,
+	".dmg": true,
+}
+
 var spinnerFrames = []string{"|", "/", "-", "\\", "|", "/", "-", "\\"}
 
-const (
+// Terminal colors; the mono theme clears them.
+var (
 	colorPurple     = "\033[0;35m"
 	colorPurpleBold = "\033[1;35m"
 	colorGray       = "\033[0;90m"
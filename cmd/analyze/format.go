@@ -76,11 +76,14 @@ func formatNumber(n int64) string {
 	return fmt.Sprintf("%.1fM", float64(n)/1000000)
 }
 
+// byteUnit is 1024 for iec units, 1000 for si.
+var byteUnit int64 = 1024
+
 func humanizeBytes(size int64) string {
 	if size < 0 {
 		return "0 B"
 	}
-	const unit = 1024
+	unit := byteUnit
 	if size < unit {
 		return fmt.Sprintf("%d B", size)
 	}
@@ -93,6 +96,29 @@ func humanizeBytes(size int64) string {
 	return fmt.Sprintf("%.1f %cB", value, "KMGTPE"[exp])
 }
 
+// formatGrowth renders a signed size delta, or "" when unchanged.
+func formatGrowth(delta int64) string {
+	switch {
+	case delta > 0:
+		return "+" + humanizeBytes(delta)
+	case delta < 0:
+		return "-" + humanizeBytes(-delta)
+	default:
+		return ""
+	}
+}
+
+// formatCompressionRatio renders an uncompressed/compressed ratio like "3.2x".
+func formatCompressionRatio(ratio float64) string {
+	if ratio <= 0 {
+		return ""
+	}
+	if ratio >= 100 {
+		return fmt.Sprintf("%.0fx", ratio)
+	}
+	return fmt.Sprintf("%.1fx", ratio)
+}
+
 func coloredProgressBar(value, maxValue int64, percent float64) string {
 	if maxValue <= 0 {
 		return colorGray + strings.Repeat("░", barWidth) + colorReset
@@ -4,6 +4,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/tw93/mole/internal/prefs"
 )
 
 func TestRuneWidth(t *testing.T) {
@@ -82,6 +84,37 @@ func TestHumanizeBytes(t *testing.T) {
 	}
 }
 
+func TestHumanizeBytesSIUnits(t *testing.T) {
+	t.Cleanup(func() { byteUnit = 1024 })
+	p := prefs.Defaults()
+	p.Units = "si"
+	applyPrefs(p)
+
+	for input, want := range map[int64]string{999: "999 B", 1000: "1.0 KB", 1500000: "1.5 MB", 2000000000: "2.0 GB"} {
+		if got := humanizeBytes(input); got != want {
+			t.Errorf("si humanizeBytes(%d) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestFormatGrowth(t *testing.T) {
+	tests := []struct {
+		delta int64
+		want  string
+	}{
+		{0, ""},
+		{512, "+512 B"},
+		{-2048, "-2.0 KB"},
+		{3 << 30, "+3.0 GB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatGrowth(tt.delta); got != tt.want {
+			t.Errorf("formatGrowth(%d) = %q, want %q", tt.delta, got, tt.want)
+		}
+	}
+}
+
 func TestFormatNumber(t *testing.T) {
 	tests := []struct {
 		input int64
@@ -19,6 +19,26 @@ func (h *entryHeap) Pop() any {
 	return x
 }
 
+// entryFileCountHeap is a min-heap of dirEntry ordered by file count.
+// It keeps dirs with many tiny files that would miss the size cut.
+type entryFileCountHeap []dirEntry
+
+func (h entryFileCountHeap) Len() int           { return len(h) }
+func (h entryFileCountHeap) Less(i, j int) bool { return h[i].FileCount < h[j].FileCount }
+func (h entryFileCountHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *entryFileCountHeap) Push(x any) {
+	*h = append(*h, x.(dirEntry))
+}
+
+func (h *entryFileCountHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[0 : n-1]
+	return x
+}
+
 // largeFileHeap is a min-heap for fileEntry.
 type largeFileHeap []fileEntry
 
@@ -79,6 +79,24 @@ func TestEntryHeap(t *testing.T) {
 	})
 }
 
+func TestEntryFileCountHeap(t *testing.T) {
+	h := &entryFileCountHeap{}
+	heap.Init(h)
+
+	heap.Push(h, dirEntry{Name: "big", Size: 1 << 30, FileCount: 20})
+	heap.Push(h, dirEntry{Name: "tiny-files", Size: 1 << 10, FileCount: 2_000_000})
+	heap.Push(h, dirEntry{Name: "mid", Size: 1 << 20, FileCount: 500})
+
+	// Ordered by file count, not size.
+	want := []string{"big", "mid", "tiny-files"}
+	for _, name := range want {
+		popped := heap.Pop(h).(dirEntry)
+		if popped.Name != name {
+			t.Fatalf("Pop() = %s, want %s", popped.Name, name)
+		}
+	}
+}
+
 func TestLargeFileHeap(t *testing.T) {
 	t.Run("basic heap operations", func(t *testing.T) {
 		h := &largeFileHeap{}
@@ -4,8 +4,10 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -16,14 +18,20 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/tw93/mole/internal/prefs"
 )
 
 type dirEntry struct {
-	Name       string
-	Path       string
-	Size       int64
-	IsDir      bool
-	LastAccess time.Time
+	Name           string
+	Path           string
+	Size           int64
+	IsDir          bool
+	LastAccess     time.Time
+	ModTime        time.Time
+	FileCount      int64 // Files in the subtree (dirs only); unknownCount when not measured
+	DirCount       int64 // Subdirectories in the subtree (dirs only); unknownCount when not measured
+	CompressedSize int64 // Packed size for archive members, 0 otherwise
 }
 
 type fileEntry struct {
@@ -37,9 +45,11 @@ type scanResult struct {
 	LargeFiles []fileEntry
 	TotalSize  int64
 	TotalFiles int64
+	PrevSizes  map[string]int64 // Entry sizes from the previous cached scan
 }
 
 type cacheEntry struct {
+	Path       string // Scanned directory, for cache listings
 	Entries    []dirEntry
 	LargeFiles []fileEntry
 	TotalSize  int64
@@ -113,23 +123,81 @@ type model struct {
 	overviewBytesScanned *int64
 	overviewCurrentPath  *string
 	overviewScanning     bool
-	overviewScanningSet  map[string]bool // Track which paths are currently being scanned
-	width                int             // Terminal width
-	height               int             // Terminal height
-	multiSelected        map[string]bool // Track multi-selected items by path (safer than index)
-	largeMultiSelected   map[string]bool // Track multi-selected large files by path (safer than index)
-	totalFiles           int64           // Total files found in current/last scan
-	lastTotalFiles       int64           // Total files from previous scan (for progress bar)
+	overviewScanningSet  map[string]bool  // Track which paths are currently being scanned
+	width                int              // Terminal width
+	height               int              // Terminal height
+	multiSelected        map[string]bool  // Track multi-selected items by path (safer than index)
+	largeMultiSelected   map[string]bool  // Track multi-selected large files by path (safer than index)
+	totalFiles           int64            // Total files found in current/last scan
+	lastTotalFiles       int64            // Total files from previous scan (for progress bar)
+	sortMode             sortMode         // Ordering of the directory list
+	prevSizes            map[string]int64 // Entry sizes before the last rescan (for growth sort)
 }
 
 func (m model) inOverviewMode() bool {
 	return m.isOverview && m.path == "/"
 }
 
+// inArchive reports whether the current view lists the contents of an archive.
+func (m model) inArchive() bool {
+	return isArchiveBrowsePath(m.path)
+}
+
+// applyPrefs sets the units and theme preferences shared with status. The
+// light theme needs nothing: the 16 ANSI colors follow the terminal scheme.
+func applyPrefs(p prefs.Prefs) {
+	byteUnit = p.ByteUnit()
+	if p.Theme == "mono" {
+		colorPurple, colorPurpleBold, colorGray = "", colorBold, ""
+		colorRed, colorYellow, colorGreen, colorBlue, colorCyan = "", "", "", "", ""
+	}
+}
+
 func main() {
+	// Only mole config check is strict; a bad file or override must not stop a scan.
+	settings, err := prefs.Load(prefs.Path())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v; using defaults (see mole config check)\n", err)
+	}
+	settings, err = prefs.ApplyEnv(settings, os.Getenv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v; ignored (see mole config check)\n", err)
+	}
+	applyPrefs(settings)
+
+	if len(os.Args) > 1 && os.Args[1] == "cache" {
+		os.Exit(runCacheCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	htmlOut := flag.String("html", "", "write a self-contained HTML report to this file and exit")
+	flag.BoolVar(&diskCacheDisabled, "no-cache", false, "scan without reading or writing the disk cache")
+	flag.Parse()
+	args := flag.Args()
+	if len(args) > 0 {
+		// flag stops at the path; parse what follows it so "analyze ~/src --html out.html" works too.
+		_ = flag.CommandLine.Parse(args[1:])
+	}
+
 	target := os.Getenv("MO_ANALYZE_PATH")
-	if target == "" && len(os.Args) > 1 {
-		target = os.Args[1]
+	if target == "" && len(args) > 0 {
+		target = args[0]
+	}
+
+	if *htmlOut != "" {
+		if target == "" {
+			target = os.Getenv("HOME")
+		}
+		abs, err := filepath.Abs(target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot resolve %q: %v\n", target, err)
+			os.Exit(1)
+		}
+		if err := writeHTMLReport(abs, *htmlOut, os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "report error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Report written to %s\n", *htmlOut)
+		return
 	}
 
 	var abs string
@@ -151,7 +219,9 @@ func main() {
 	// Warm overview cache in background.
 	prefetchCtx, prefetchCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer prefetchCancel()
-	go prefetchOverviewCache(prefetchCtx)
+	if !diskCacheDisabled {
+		go prefetchOverviewCache(prefetchCtx)
+	}
 
 	p := tea.NewProgram(newModel(abs, isOverview), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
@@ -187,6 +257,7 @@ func newModel(path string, isOverview bool) model {
 		overviewScanningSet:  make(map[string]bool),
 		multiSelected:        make(map[string]bool),
 		largeMultiSelected:   make(map[string]bool),
+		prevSizes:            make(map[string]int64),
 	}
 
 	if isOverview {
@@ -290,6 +361,25 @@ func (m *model) sortOverviewEntriesBySize() {
 	})
 }
 
+// applySortMode reorders entries by the current sort mode, keeping the cursor on the same path.
+func (m *model) applySortMode() {
+	if len(m.entries) == 0 {
+		return
+	}
+	selectedPath := ""
+	if m.selected >= 0 && m.selected < len(m.entries) {
+		selectedPath = m.entries[m.selected].Path
+	}
+	sortEntries(m.entries, m.sortMode, m.prevSizes)
+	for i, entry := range m.entries {
+		if entry.Path == selectedPath {
+			m.selected = i
+			break
+		}
+	}
+	m.clampEntrySelection()
+}
+
 func (m *model) scheduleOverviewScans() tea.Cmd {
 	if !m.inOverviewMode() {
 		return nil
@@ -364,6 +454,12 @@ func (m model) Init() tea.Cmd {
 
 func (m model) scanCmd(path string) tea.Cmd {
 	return func() tea.Msg {
+		// Archive contents are listed in memory and never cached to disk.
+		if archivePath, inner, ok := splitArchivePath(path); ok {
+			result, err := scanArchive(archivePath, inner, m.filesScanned, m.bytesScanned)
+			return scanResultMsg{result: result, err: err}
+		}
+
 		if cached, err := loadCacheFromDisk(path); err == nil {
 			result := scanResult{
 				Entries:    cached.Entries,
@@ -371,9 +467,13 @@ func (m model) scanCmd(path string) tea.Cmd {
 				TotalSize:  cached.TotalSize,
 				TotalFiles: 0, // Cache doesn't store file count currently, minor UI limitation
 			}
+			probeArchiveRatios(result.Entries)
 			return scanResultMsg{result: result, err: nil}
 		}
 
+		// Stale cache still provides a baseline for the growth sort.
+		prevSizes, _ := peekCacheEntrySizes(path)
+
 		v, err, _ := scanGroup.Do(path, func() (any, error) {
 			return scanPathConcurrent(path, m.filesScanned, m.dirsScanned, m.bytesScanned, m.currentPath)
 		})
@@ -383,6 +483,8 @@ func (m model) scanCmd(path string) tea.Cmd {
 		}
 
 		result := v.(scanResult)
+		result.PrevSizes = prevSizes
+		probeArchiveRatios(result.Entries)
 
 		go func(p string, r scanResult) {
 			if err := saveCacheToDisk(p, r); err != nil {
@@ -457,11 +559,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.largeFiles = msg.result.LargeFiles
 		m.totalSize = msg.result.TotalSize
 		m.totalFiles = msg.result.TotalFiles
+		if m.prevSizes == nil {
+			m.prevSizes = make(map[string]int64)
+		}
+		maps.Copy(m.prevSizes, msg.result.PrevSizes)
+		m.applySortMode()
 		m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
 		m.clampEntrySelection()
 		m.clampLargeSelection()
 		m.cache[m.path] = cacheSnapshot(m)
-		if m.totalSize > 0 {
+		if m.totalSize > 0 && !m.inArchive() {
 			if m.overviewSizeCache == nil {
 				m.overviewSizeCache = make(map[string]int64)
 			}
@@ -583,6 +690,30 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Archive contents are read-only; open and reveal act on the archive itself.
+	if m.inArchive() {
+		switch msg.String() {
+		case " ", "delete", "backspace":
+			m.status = "Archive contents are read-only"
+			return m, nil
+		case "o", "O", "f", "F":
+			archivePath := archiveRealPath(m.path)
+			args := []string{archivePath}
+			verb := "Opening"
+			if strings.EqualFold(msg.String(), "f") {
+				args = []string{"-R", archivePath}
+				verb = "Showing"
+			}
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), openCommandTimeout)
+				defer cancel()
+				_ = exec.CommandContext(ctx, "open", args...).Run()
+			}()
+			m.status = fmt.Sprintf("%s %s...", verb, filepath.Base(archivePath))
+			return m, nil
+		}
+	}
+
 	switch msg.String() {
 	case "q", "ctrl+c", "Q":
 		return m, tea.Quit
@@ -624,6 +755,10 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	case "enter", "right", "l", "L":
 		if m.showLargeFiles {
+			if m.largeSelected < len(m.largeFiles) && isArchivePath(m.largeFiles[m.largeSelected].Path) {
+				m.showLargeFiles = false
+				return m.enterPath(archiveBrowsePath(m.largeFiles[m.largeSelected].Path, ""))
+			}
 			return m, nil
 		}
 		return m.enterSelectedDir()
@@ -666,6 +801,9 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.entries = last.Entries
 		m.largeFiles = last.LargeFiles
 		m.totalSize = last.TotalSize
+		if !m.inOverviewMode() {
+			m.applySortMode()
+		}
 		m.clampEntrySelection()
 		m.clampLargeSelection()
 		if len(m.entries) == 0 {
@@ -703,6 +841,12 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, tea.Batch(m.scheduleOverviewScans(), tickCmd())
 		}
 
+		if m.prevSizes == nil {
+			m.prevSizes = make(map[string]int64)
+		}
+		for _, entry := range m.entries {
+			m.prevSizes[entry.Path] = entry.Size
+		}
 		invalidateCache(m.path)
 		m.status = "Refreshing..."
 		m.scanning = true
@@ -728,6 +872,13 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
 		}
+	case "s", "S":
+		if m.showLargeFiles || m.inOverviewMode() {
+			return m, nil
+		}
+		m.sortMode = m.sortMode.next()
+		m.applySortMode()
+		m.status = fmt.Sprintf("Sorted by %s", m.sortMode)
 	case "o", "O":
 		// Open selected entries (multi-select aware).
 		const maxBatchOpen = 20
@@ -971,50 +1122,63 @@ func (m model) enterSelectedDir() (tea.Model, tea.Cmd) {
 	}
 	selected := m.entries[m.selected]
 	if selected.IsDir {
-		if len(m.history) == 0 || m.history[len(m.history)-1].Path != m.path {
-			m.history = append(m.history, snapshotFromModel(m))
-		}
-		m.path = selected.Path
-		m.selected = 0
-		m.offset = 0
-		m.status = "Scanning..."
-		m.scanning = true
-		m.isOverview = false
-		m.multiSelected = make(map[string]bool)
-		m.largeMultiSelected = make(map[string]bool)
-
-		atomic.StoreInt64(m.filesScanned, 0)
-		atomic.StoreInt64(m.dirsScanned, 0)
-		atomic.StoreInt64(m.bytesScanned, 0)
-		if m.currentPath != nil {
-			m.currentPath.Store("")
-		}
-
-		if cached, ok := m.cache[m.path]; ok && !cached.Dirty {
-			m.entries = slices.Clone(cached.Entries)
-			m.largeFiles = slices.Clone(cached.LargeFiles)
-			m.totalSize = cached.TotalSize
-			m.totalFiles = cached.TotalFiles
-			m.selected = cached.Selected
-			m.offset = cached.EntryOffset
-			m.largeSelected = cached.LargeSelected
-			m.largeOffset = cached.LargeOffset
-			m.clampEntrySelection()
-			m.clampLargeSelection()
-			m.status = fmt.Sprintf("Cached view for %s", displayPath(m.path))
-			m.scanning = false
-			return m, nil
-		}
-		m.lastTotalFiles = 0
-		if total, err := peekCacheTotalFiles(m.path); err == nil && total > 0 {
-			m.lastTotalFiles = total
-		}
-		return m, tea.Batch(m.scanCmd(m.path), tickCmd())
+		return m.enterPath(selected.Path)
+	}
+	if isArchivePath(selected.Path) {
+		return m.enterPath(archiveBrowsePath(selected.Path, ""))
+	}
+	if isUnsupportedArchivePath(selected.Path) {
+		m.status = fmt.Sprintf("Cannot inspect %s, only zip and tar archives are supported", selected.Name)
+		return m, nil
 	}
 	m.status = fmt.Sprintf("File: %s, %s", selected.Name, humanizeBytes(selected.Size))
 	return m, nil
 }
 
+// enterPath pushes the current view onto history and scans path.
+func (m model) enterPath(path string) (tea.Model, tea.Cmd) {
+	if len(m.history) == 0 || m.history[len(m.history)-1].Path != m.path {
+		m.history = append(m.history, snapshotFromModel(m))
+	}
+	m.path = path
+	m.selected = 0
+	m.offset = 0
+	m.status = "Scanning..."
+	m.scanning = true
+	m.isOverview = false
+	m.multiSelected = make(map[string]bool)
+	m.largeMultiSelected = make(map[string]bool)
+
+	atomic.StoreInt64(m.filesScanned, 0)
+	atomic.StoreInt64(m.dirsScanned, 0)
+	atomic.StoreInt64(m.bytesScanned, 0)
+	if m.currentPath != nil {
+		m.currentPath.Store("")
+	}
+
+	if cached, ok := m.cache[m.path]; ok && !cached.Dirty {
+		m.entries = slices.Clone(cached.Entries)
+		m.largeFiles = slices.Clone(cached.LargeFiles)
+		m.totalSize = cached.TotalSize
+		m.totalFiles = cached.TotalFiles
+		m.selected = cached.Selected
+		m.offset = cached.EntryOffset
+		m.largeSelected = cached.LargeSelected
+		m.largeOffset = cached.LargeOffset
+		m.applySortMode()
+		m.clampEntrySelection()
+		m.clampLargeSelection()
+		m.status = fmt.Sprintf("Cached view for %s", displayPath(m.path))
+		m.scanning = false
+		return m, nil
+	}
+	m.lastTotalFiles = 0
+	if total, err := peekCacheTotalFiles(m.path); err == nil && total > 0 {
+		m.lastTotalFiles = total
+	}
+	return m, tea.Batch(m.scanCmd(m.path), tickCmd())
+}
+
 func (m *model) clampEntrySelection() {
 	if len(m.entries) == 0 {
 		m.selected = 0
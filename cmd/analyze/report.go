@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+//go:embed report.html
+var reportTemplateSource string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportTemplateSource))
+
+// reportNode is one directory or file in the HTML report tree.
+type reportNode struct {
+	Name      string       `json:"name"`
+	Path      string       `json:"path"`
+	Size      int64        `json:"size"`
+	IsDir     bool         `json:"dir,omitempty"`
+	Files     int64        `json:"files,omitempty"`
+	ModTime   int64        `json:"mtime,omitempty"` // Unix seconds
+	Cleanable bool         `json:"cleanable,omitempty"`
+	Children  []reportNode `json:"children,omitempty"`
+}
+
+// reportData is everything the HTML report renders.
+type reportData struct {
+	Title           string       `json:"title"`
+	GeneratedAt     string       `json:"generatedAt"`
+	Root            reportNode   `json:"root"`
+	TotalFiles      int64        `json:"totalFiles"`
+	LargeFiles      []reportNode `json:"largeFiles"`
+	Reclaimable     []reportNode `json:"reclaimable"`
+	ReclaimableSize int64        `json:"reclaimableSize"`
+}
+
+// writeHTMLReport scans root and writes a self-contained HTML report to outPath.
+func writeHTMLReport(root, outPath string, progress io.Writer) error {
+	if progress != nil {
+		fmt.Fprintf(progress, "Scanning %s...\n", displayPath(root))
+	}
+	tree, largeFiles, err := scanReportTree(root, reportDepth)
+	if err != nil {
+		return err
+	}
+	data := buildReportData(root, tree, largeFiles, time.Now())
+
+	var buf bytes.Buffer
+	if err := renderHTMLReport(&buf, data); err != nil {
+		return err
+	}
+
+	tmpPath := outPath + ".tmp"
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, outPath)
+}
+
+// scanReportTree builds the report tree from the same scans the TUI runs.
+// Directories down to depth levels are expanded; cleanable ones stay whole.
+func scanReportTree(root string, depth int) (reportNode, []fileEntry, error) {
+	result, err := scanReportDir(root)
+	if err != nil {
+		return reportNode{}, nil, err
+	}
+	tree := reportNode{
+		Name:     filepath.Base(root),
+		Path:     root,
+		Size:     result.TotalSize,
+		IsDir:    true,
+		Files:    result.TotalFiles,
+		Children: reportChildren(result.Entries, depth-1),
+	}
+	// A subtree sized by du leaves the total count unknown, as in the TUI.
+	for _, entry := range result.Entries {
+		if entry.IsDir && entry.FileCount < 0 {
+			tree.Files = unknownCount
+		}
+	}
+	return tree, result.LargeFiles, nil
+}
+
+// scanReportDir returns the scan of one directory, from the cache when it is
+// still valid, else from a fresh scan that is then cached for the TUI.
+func scanReportDir(path string) (scanResult, error) {
+	if cached, err := loadCacheFromDisk(path); err == nil {
+		return scanResult{
+			Entries:    cached.Entries,
+			LargeFiles: cached.LargeFiles,
+			TotalSize:  cached.TotalSize,
+			TotalFiles: cached.TotalFiles,
+		}, nil
+	}
+
+	var filesScanned, dirsScanned, bytesScanned int64
+	result, err := scanPathConcurrent(path, &filesScanned, &dirsScanned, &bytesScanned, nil)
+	if err != nil {
+		return scanResult{}, err
+	}
+	_ = saveCacheToDisk(path, result) // Cache save failure is not critical
+	return result, nil
+}
+
+// reportChildren converts scan entries to report nodes, scanning directories
+// further while levels remain.
+func reportChildren(entries []dirEntry, levels int) []reportNode {
+	var children []reportNode
+	for _, entry := range entries {
+		if entry.Size <= 0 {
+			continue
+		}
+		child := reportNode{Name: entry.Name, Path: entry.Path, Size: entry.Size, IsDir: entry.IsDir}
+		if !entry.ModTime.IsZero() {
+			child.ModTime = entry.ModTime.Unix()
+		}
+		if entry.IsDir {
+			child.Files = entry.FileCount
+			child.Cleanable = isCleanableDir(entry.Path)
+			if levels > 0 && !child.Cleanable && !isSymlink(entry.Path) {
+				if sub, err := scanReportDir(entry.Path); err == nil {
+					child.Children = reportChildren(sub.Entries, levels-1)
+				}
+			}
+		}
+		children = append(children, child)
+	}
+	sort.SliceStable(children, func(i, j int) bool { return children[i].Size > children[j].Size })
+	return children
+}
+
+// isSymlink reports whether path is a symlink, which the report does not follow.
+func isSymlink(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
+
+// buildReportData wraps a scanned tree with its summary lists.
+func buildReportData(root string, tree reportNode, largeFiles []fileEntry, now time.Time) reportData {
+	data := reportData{
+		Title:       displayPath(root),
+		GeneratedAt: now.Format("2006-01-02 15:04"),
+		Root:        tree,
+		TotalFiles:  tree.Files,
+	}
+
+	for _, file := range largeFiles {
+		data.LargeFiles = append(data.LargeFiles, reportNode{Name: file.Name, Path: file.Path, Size: file.Size})
+	}
+
+	collectReclaimable(tree.Children, &data.Reclaimable)
+	sort.Slice(data.Reclaimable, func(i, j int) bool {
+		return data.Reclaimable[i].Size > data.Reclaimable[j].Size
+	})
+	for _, node := range data.Reclaimable {
+		data.ReclaimableSize += node.Size
+	}
+
+	return data
+}
+
+// collectReclaimable gathers cleanable dirs, without descending into them.
+func collectReclaimable(nodes []reportNode, out *[]reportNode) {
+	for _, node := range nodes {
+		if node.Cleanable {
+			*out = append(*out, reportNode{Name: node.Name, Path: node.Path, Size: node.Size, IsDir: true, Files: node.Files})
+			continue
+		}
+		collectReclaimable(node.Children, out)
+	}
+}
+
+func renderHTMLReport(w io.Writer, data reportData) error {
+	return reportTemplate.Execute(w, data)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func findReportChild(node reportNode, name string) *reportNode {
+	for i := range node.Children {
+		if node.Children[i].Name == name {
+			return &node.Children[i]
+		}
+	}
+	return nil
+}
+
+func TestScanReportTree(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	writeFileWithSize(t, filepath.Join(root, "app", "big.bin"), 2<<20)
+	writeFileWithSize(t, filepath.Join(root, "app", "target", "out.o"), 3<<19)
+	writeFileWithSize(t, filepath.Join(root, "node_modules", "pkg", "index.js"), 32<<10)
+	writeFileWithSize(t, filepath.Join(root, "node_modules", "pkg", "lib", "util.js"), 8<<10)
+	writeFileWithSize(t, filepath.Join(root, "notes.txt"), 4<<10)
+
+	tree, largeFiles, err := scanReportTree(root, 2)
+	if err != nil {
+		t.Fatalf("scanReportTree: %v", err)
+	}
+	data := buildReportData(root, tree, largeFiles, time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC))
+
+	if data.TotalFiles != unknownCount || data.Root.Size < 7<<19 {
+		t.Fatalf("root totals = %d bytes, %d files", data.Root.Size, data.TotalFiles)
+	}
+	if len(data.Root.Children) != 3 || data.Root.Children[0].Name != "app" {
+		t.Fatalf("children should be sorted by size, got %+v", data.Root.Children)
+	}
+	app := findReportChild(data.Root, "app")
+	if app.Files != unknownCount || findReportChild(*app, "target") == nil || findReportChild(*app, "big.bin") == nil {
+		t.Errorf("app should be expanded with its counts, got %+v", app)
+	}
+	modules := findReportChild(data.Root, "node_modules")
+	if modules.Children != nil || modules.Files != unknownCount {
+		t.Errorf("cleanable dir should be sized whole without expanding, got %+v", modules)
+	}
+
+	if len(data.Reclaimable) != 2 || data.Reclaimable[0].Name != "target" || data.Reclaimable[1].Name != "node_modules" {
+		t.Errorf("reclaimable = %+v", data.Reclaimable)
+	}
+	if data.ReclaimableSize != data.Reclaimable[0].Size+data.Reclaimable[1].Size {
+		t.Errorf("ReclaimableSize = %d", data.ReclaimableSize)
+	}
+	// out.o sits in a folded dir, which du sizes without listing its files.
+	if len(data.LargeFiles) != 1 || data.LargeFiles[0].Name != "big.bin" {
+		t.Errorf("large files = %+v", data.LargeFiles)
+	}
+}
+
+func TestScanReportTreeRespectsDepth(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	writeFileWithSize(t, filepath.Join(root, "a", "b", "c.txt"), 4<<10)
+
+	tree, _, err := scanReportTree(root, 1)
+	if err != nil {
+		t.Fatalf("scanReportTree: %v", err)
+	}
+	a := findReportChild(tree, "a")
+	if a == nil || a.Children != nil || a.Files != 1 || a.Size <= 0 {
+		t.Errorf("depth 1 should fold everything under a into it, got %+v", a)
+	}
+}
+
+func TestRenderHTMLReportSelfContained(t *testing.T) {
+	data := reportData{
+		Title: "~/p",
+		Root: reportNode{
+			Name: "p", Path: "/p", Size: 10, IsDir: true,
+			Children: []reportNode{{Name: "</script><b>x", Path: "/p/x", Size: 10}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := renderHTMLReport(&buf, data); err != nil {
+		t.Fatalf("renderHTMLReport: %v", err)
+	}
+	out := buf.String()
+
+	for _, external := range []string{"<script src", "<link ", "http://", "https://"} {
+		if strings.Contains(out, external) {
+			t.Errorf("report references external asset %q", external)
+		}
+	}
+	if strings.Contains(out, "</script><b>x") {
+		t.Errorf("entry names must be escaped inside the embedded data")
+	}
+	if !strings.Contains(out, `"path":"/p/x"`) {
+		t.Errorf("report is missing embedded entry data")
+	}
+}
@@ -50,6 +50,9 @@ func scanPathConcurrent(root string, filesScanned, dirsScanned, bytesScanned *in
 	entriesHeap := &entryHeap{}
 	heap.Init(entriesHeap)
 
+	countHeap := &entryFileCountHeap{}
+	heap.Init(countHeap)
+
 	largeFilesHeap := &largeFileHeap{}
 	heap.Init(largeFilesHeap)
 	largeFileMinSize := int64(largeFileWarmupMinSize)
@@ -93,6 +96,15 @@ func scanPathConcurrent(root string, filesScanned, dirsScanned, bytesScanned *in
 				heap.Pop(entriesHeap)
 				heap.Push(entriesHeap, entry)
 			}
+			if !entry.IsDir || entry.FileCount <= 0 {
+				continue
+			}
+			if countHeap.Len() < maxFileCountEntries {
+				heap.Push(countHeap, entry)
+			} else if entry.FileCount > (*countHeap)[0].FileCount {
+				heap.Pop(countHeap)
+				heap.Push(countHeap, entry)
+			}
 		}
 	}()
 	go func() {
@@ -140,6 +152,7 @@ func scanPathConcurrent(root string, filesScanned, dirsScanned, bytesScanned *in
 				Size:       size,
 				IsDir:      isDir,
 				LastAccess: getLastAccessTimeFromInfo(info),
+				ModTime:    info.ModTime(),
 			}, 100*time.Millisecond)
 			continue
 
@@ -159,29 +172,33 @@ func scanPathConcurrent(root string, filesScanned, dirsScanned, bytesScanned *in
 			if isHomeDir && child.Name() == "Library" {
 				sem <- struct{}{}
 				wg.Add(1)
-				go func(name, path string) {
+				go func(name, path string, modTime time.Time) {
 					defer wg.Done()
 					defer func() { <-sem }()
 
-					var size int64
+					var totals dirTotals
 					if cached, err := loadStoredOverviewSize(path); err == nil && cached > 0 {
-						size = cached
+						totals = dirTotals{Size: cached, Files: unknownCount, Dirs: unknownCount}
 					} else if cached, err := loadCacheFromDisk(path); err == nil {
-						size = cached.TotalSize
+						totals.Size = cached.TotalSize
+						totals.Files = cached.TotalFiles
 					} else {
-						size = calculateDirSizeConcurrent(path, largeFileChan, &largeFileMinSize, duSem, duQueueSem, filesScanned, dirsScanned, bytesScanned, currentPath)
+						totals = calculateDirSizeConcurrent(path, largeFileChan, &largeFileMinSize, duSem, duQueueSem, filesScanned, dirsScanned, bytesScanned, currentPath)
 					}
-					atomic.AddInt64(&total, size)
+					atomic.AddInt64(&total, totals.Size)
 					atomic.AddInt64(dirsScanned, 1)
 
 					trySend(entryChan, dirEntry{
 						Name:       name,
 						Path:       path,
-						Size:       size,
+						Size:       totals.Size,
 						IsDir:      true,
 						LastAccess: time.Time{},
+						ModTime:    modTime,
+						FileCount:  totals.Files,
+						DirCount:   totals.Dirs,
 					}, 100*time.Millisecond)
-				}(child.Name(), fullPath)
+				}(child.Name(), fullPath, dirModTime(child))
 				continue
 			}
 
@@ -189,50 +206,49 @@ func scanPathConcurrent(root string, filesScanned, dirsScanned, bytesScanned *in
 			if shouldFoldDirWithPath(child.Name(), fullPath) {
 				duQueueSem <- struct{}{}
 				wg.Add(1)
-				go func(name, path string) {
+				go func(name, path string, modTime time.Time) {
 					defer wg.Done()
 					defer func() { <-duQueueSem }()
 
-					size, err := func() (int64, error) {
-						duSem <- struct{}{}
-						defer func() { <-duSem }()
-						return getDirectorySizeFromDu(path)
-					}()
-					if err != nil || size <= 0 {
-						size = calculateDirSizeFast(path, filesScanned, dirsScanned, bytesScanned, currentPath)
-					}
-					atomic.AddInt64(&total, size)
+					totals := measureFoldedDir(path, duSem, filesScanned, dirsScanned, bytesScanned, currentPath)
+					atomic.AddInt64(&total, totals.Size)
 					atomic.AddInt64(dirsScanned, 1)
 
 					trySend(entryChan, dirEntry{
 						Name:       name,
 						Path:       path,
-						Size:       size,
+						Size:       totals.Size,
 						IsDir:      true,
 						LastAccess: time.Time{},
+						ModTime:    modTime,
+						FileCount:  totals.Files,
+						DirCount:   totals.Dirs,
 					}, 100*time.Millisecond)
-				}(child.Name(), fullPath)
+				}(child.Name(), fullPath, dirModTime(child))
 				continue
 			}
 
 			sem <- struct{}{}
 			wg.Add(1)
-			go func(name, path string) {
+			go func(name, path string, modTime time.Time) {
 				defer wg.Done()
 				defer func() { <-sem }()
 
-				size := calculateDirSizeConcurrent(path, largeFileChan, &largeFileMinSize, duSem, duQueueSem, filesScanned, dirsScanned, bytesScanned, currentPath)
-				atomic.AddInt64(&total, size)
+				totals := calculateDirSizeConcurrent(path, largeFileChan, &largeFileMinSize, duSem, duQueueSem, filesScanned, dirsScanned, bytesScanned, currentPath)
+				atomic.AddInt64(&total, totals.Size)
 				atomic.AddInt64(dirsScanned, 1)
 
 				trySend(entryChan, dirEntry{
 					Name:       name,
 					Path:       path,
-					Size:       size,
+					Size:       totals.Size,
 					IsDir:      true,
 					LastAccess: time.Time{},
+					ModTime:    modTime,
+					FileCount:  totals.Files,
+					DirCount:   totals.Dirs,
 				}, 100*time.Millisecond)
-			}(child.Name(), fullPath)
+			}(child.Name(), fullPath, dirModTime(child))
 			continue
 		}
 
@@ -252,6 +268,7 @@ func scanPathConcurrent(root string, filesScanned, dirsScanned, bytesScanned *in
 			Size:       size,
 			IsDir:      false,
 			LastAccess: getLastAccessTimeFromInfo(info),
+			ModTime:    info.ModTime(),
 		}, 100*time.Millisecond)
 
 		// Track large files only.
@@ -276,6 +293,18 @@ func scanPathConcurrent(root string, filesScanned, dirsScanned, bytesScanned *in
 		entries[i] = heap.Pop(entriesHeap).(dirEntry)
 	}
 
+	// Add dirs with the most files that fell outside the size cut.
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		seen[entry.Path] = true
+	}
+	for _, entry := range *countHeap {
+		if !seen[entry.Path] {
+			entries = append(entries, entry)
+		}
+	}
+	sortEntries(entries, sortBySize, nil)
+
 	largeFiles := make([]fileEntry, largeFilesHeap.Len())
 	for i := len(largeFiles) - 1; i >= 0; i-- {
 		largeFiles[i] = heap.Pop(largeFilesHeap).(fileEntry)
@@ -319,8 +348,8 @@ func shouldSkipFileForLargeTracking(path string) bool {
 }
 
 // calculateDirSizeFast performs concurrent dir sizing using os.ReadDir.
-func calculateDirSizeFast(root string, filesScanned, dirsScanned, bytesScanned *int64, currentPath *atomic.Value) int64 {
-	var total int64
+func calculateDirSizeFast(root string, filesScanned, dirsScanned, bytesScanned *int64, currentPath *atomic.Value) dirTotals {
+	var totals dirTotals
 	var wg sync.WaitGroup
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
@@ -346,7 +375,7 @@ func calculateDirSizeFast(root string, filesScanned, dirsScanned, bytesScanned *
 			return
 		}
 
-		var localBytes, localFiles int64
+		var localBytes, localFiles, localDirs int64
 
 		for _, entry := range entries {
 			if entry.IsDir() {
@@ -359,6 +388,7 @@ func calculateDirSizeFast(root string, filesScanned, dirsScanned, bytesScanned *
 					walk(p)
 				}(subDir)
 				atomic.AddInt64(dirsScanned, 1)
+				localDirs++
 			} else {
 				info, err := entry.Info()
 				if err == nil {
@@ -370,18 +400,64 @@ func calculateDirSizeFast(root string, filesScanned, dirsScanned, bytesScanned *
 		}
 
 		if localBytes > 0 {
-			atomic.AddInt64(&total, localBytes)
+			atomic.AddInt64(&totals.Size, localBytes)
 			atomic.AddInt64(bytesScanned, localBytes)
 		}
 		if localFiles > 0 {
+			atomic.AddInt64(&totals.Files, localFiles)
 			atomic.AddInt64(filesScanned, localFiles)
 		}
+		if localDirs > 0 {
+			atomic.AddInt64(&totals.Dirs, localDirs)
+		}
 	}
 
 	walk(root)
 	wg.Wait()
 
-	return total
+	return totals
+}
+
+// measureFoldedDir sizes a folded dir with du, falling back to a full walk.
+// du reports no counts and folding exists to avoid listing the tree, so the
+// counts stay unknown unless the fallback walk ran.
+func measureFoldedDir(path string, duSem chan struct{}, filesScanned, dirsScanned, bytesScanned *int64, currentPath *atomic.Value) dirTotals {
+	size, err := func() (int64, error) {
+		duSem <- struct{}{}
+		defer func() { <-duSem }()
+		return getDirectorySizeFromDu(path)
+	}()
+	if err != nil || size <= 0 {
+		return calculateDirSizeFast(path, filesScanned, dirsScanned, bytesScanned, currentPath)
+	}
+	atomic.AddInt64(bytesScanned, size)
+	return dirTotals{Size: size, Files: unknownCount, Dirs: unknownCount}
+}
+
+// addCount adds n to a subtree count. Once either side is unknown the total stays unknown.
+func addCount(dst *int64, n int64) {
+	for {
+		cur := atomic.LoadInt64(dst)
+		if cur < 0 {
+			return
+		}
+		next := cur + n
+		if n < 0 {
+			next = unknownCount
+		}
+		if atomic.CompareAndSwapInt64(dst, cur, next) {
+			return
+		}
+	}
+}
+
+// dirModTime returns the modification time of a directory entry, or zero on error.
+func dirModTime(entry fs.DirEntry) time.Time {
+	info, err := entry.Info()
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
 }
 
 // Use Spotlight (mdfind) to quickly find large files.
@@ -455,13 +531,20 @@ func isInFoldedDir(path string) bool {
 	return false
 }
 
-func calculateDirSizeConcurrent(root string, largeFileChan chan<- fileEntry, largeFileMinSize *int64, duSem, duQueueSem chan struct{}, filesScanned, dirsScanned, bytesScanned *int64, currentPath *atomic.Value) int64 {
+// dirTotals accumulates size and item counts for a directory subtree.
+type dirTotals struct {
+	Size  int64
+	Files int64
+	Dirs  int64
+}
+
+func calculateDirSizeConcurrent(root string, largeFileChan chan<- fileEntry, largeFileMinSize *int64, duSem, duQueueSem chan struct{}, filesScanned, dirsScanned, bytesScanned *int64, currentPath *atomic.Value) dirTotals {
 	children, err := os.ReadDir(root)
 	if err != nil {
-		return 0
+		return dirTotals{}
 	}
 
-	var total int64
+	var totals dirTotals
 	var wg sync.WaitGroup
 
 	// Limit concurrent subdirectory scans.
@@ -477,13 +560,16 @@ func calculateDirSizeConcurrent(root string, largeFileChan chan<- fileEntry, lar
 				continue
 			}
 			size := getActualFileSize(fullPath, info)
-			total += size
+			atomic.AddInt64(&totals.Size, size)
+			addCount(&totals.Files, 1)
 			atomic.AddInt64(filesScanned, 1)
 			atomic.AddInt64(bytesScanned, size)
 			continue
 		}
 
 		if child.IsDir() {
+			addCount(&totals.Dirs, 1)
+
 			if shouldFoldDirWithPath(child.Name(), fullPath) {
 				duQueueSem <- struct{}{}
 				wg.Add(1)
@@ -491,17 +577,10 @@ func calculateDirSizeConcurrent(root string, largeFileChan chan<- fileEntry, lar
 					defer wg.Done()
 					defer func() { <-duQueueSem }()
 
-					size, err := func() (int64, error) {
-						duSem <- struct{}{}
-						defer func() { <-duSem }()
-						return getDirectorySizeFromDu(path)
-					}()
-					if err != nil || size <= 0 {
-						size = calculateDirSizeFast(path, filesScanned, dirsScanned, bytesScanned, currentPath)
-					} else {
-						atomic.AddInt64(bytesScanned, size)
-					}
-					atomic.AddInt64(&total, size)
+					sub := measureFoldedDir(path, duSem, filesScanned, dirsScanned, bytesScanned, currentPath)
+					atomic.AddInt64(&totals.Size, sub.Size)
+					addCount(&totals.Files, sub.Files)
+					addCount(&totals.Dirs, sub.Dirs)
 					atomic.AddInt64(dirsScanned, 1)
 				}(fullPath)
 				continue
@@ -513,8 +592,10 @@ func calculateDirSizeConcurrent(root string, largeFileChan chan<- fileEntry, lar
 				defer wg.Done()
 				defer func() { <-sem }()
 
-				size := calculateDirSizeConcurrent(path, largeFileChan, largeFileMinSize, duSem, duQueueSem, filesScanned, dirsScanned, bytesScanned, currentPath)
-				atomic.AddInt64(&total, size)
+				sub := calculateDirSizeConcurrent(path, largeFileChan, largeFileMinSize, duSem, duQueueSem, filesScanned, dirsScanned, bytesScanned, currentPath)
+				atomic.AddInt64(&totals.Size, sub.Size)
+				addCount(&totals.Files, sub.Files)
+				addCount(&totals.Dirs, sub.Dirs)
 				atomic.AddInt64(dirsScanned, 1)
 			}(fullPath)
 			continue
@@ -526,7 +607,8 @@ func calculateDirSizeConcurrent(root string, largeFileChan chan<- fileEntry, lar
 		}
 
 		size := getActualFileSize(fullPath, info)
-		total += size
+		atomic.AddInt64(&totals.Size, size)
+		addCount(&totals.Files, 1)
 		atomic.AddInt64(filesScanned, 1)
 		atomic.AddInt64(bytesScanned, size)
 
@@ -544,7 +626,7 @@ func calculateDirSizeConcurrent(root string, largeFileChan chan<- fileEntry, lar
 	}
 
 	wg.Wait()
-	return total
+	return totals
 }
 
 // measureOverviewSize calculates the size of a directory using multiple strategies.
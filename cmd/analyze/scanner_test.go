@@ -43,3 +43,13 @@ func TestGetDirectoryLogicalSizeWithExclude(t *testing.T) {
 		t.Fatalf("expected 400 bytes when excluding top-level Library, got %d", excluding)
 	}
 }
+
+func TestAddCountKeepsUnknown(t *testing.T) {
+	var total int64
+	addCount(&total, 5)
+	addCount(&total, unknownCount)
+	addCount(&total, 7)
+	if total != unknownCount {
+		t.Fatalf("expected unknown total after an unknown subtree, got %d", total)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// sortMode selects how the directory list is ordered.
+type sortMode int
+
+const (
+	sortBySize sortMode = iota
+	sortByFileCount
+	sortByName
+	sortByModTime
+	sortByGrowth
+	sortModeCount
+)
+
+func (s sortMode) String() string {
+	switch s {
+	case sortByFileCount:
+		return "files"
+	case sortByName:
+		return "name"
+	case sortByModTime:
+		return "modified"
+	case sortByGrowth:
+		return "growth"
+	default:
+		return "size"
+	}
+}
+
+// next cycles to the following sort mode.
+func (s sortMode) next() sortMode {
+	return (s + 1) % sortModeCount
+}
+
+// entryGrowth returns the size change since the previous scan, or 0 when unknown.
+func entryGrowth(entry dirEntry, prevSizes map[string]int64) int64 {
+	prev, ok := prevSizes[entry.Path]
+	if !ok {
+		return 0
+	}
+	return entry.Size - prev
+}
+
+// sortEntries orders entries in place. Ties fall back to size, then name.
+func sortEntries(entries []dirEntry, mode sortMode, prevSizes map[string]int64) {
+	bySize := func(a, b dirEntry) bool {
+		if a.Size != b.Size {
+			return a.Size > b.Size
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		switch mode {
+		case sortByFileCount:
+			if a.FileCount != b.FileCount {
+				return a.FileCount > b.FileCount
+			}
+		case sortByName:
+			an, bn := strings.ToLower(a.Name), strings.ToLower(b.Name)
+			if an != bn {
+				return an < bn
+			}
+		case sortByModTime:
+			if !a.ModTime.Equal(b.ModTime) {
+				return a.ModTime.After(b.ModTime)
+			}
+		case sortByGrowth:
+			ag, bg := entryGrowth(a, prevSizes), entryGrowth(b, prevSizes)
+			if ag != bg {
+				return ag > bg
+			}
+		}
+		return bySize(a, b)
+	})
+}
+
+// selectTopEntries keeps the largest entries plus the dirs with the most files, ordered by size.
+// Mirrors the heap-based Top N selection used during filesystem scans.
+func selectTopEntries(entries []dirEntry) []dirEntry {
+	sortEntries(entries, sortBySize, nil)
+	if len(entries) <= maxEntries {
+		return entries
+	}
+
+	top := slices.Clone(entries[:maxEntries])
+	rest := slices.Clone(entries[maxEntries:])
+	sortEntries(rest, sortByFileCount, nil)
+	for _, entry := range rest[:min(maxFileCountEntries, len(rest))] {
+		if entry.IsDir && entry.FileCount > 0 {
+			top = append(top, entry)
+		}
+	}
+	sortEntries(top, sortBySize, nil)
+	return top
+}
+
+// sortLargeFiles orders files by size, largest first.
+func sortLargeFiles(files []fileEntry) {
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Size > files[j].Size
+	})
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func entryNames(entries []dirEntry) []string {
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name
+	}
+	return names
+}
+
+func TestSortEntries(t *testing.T) {
+	now := time.Now()
+	base := []dirEntry{
+		{Name: "big", Path: "/p/big", Size: 900, IsDir: true, FileCount: 10, ModTime: now.Add(-48 * time.Hour)},
+		{Name: "many", Path: "/p/many", Size: 100, IsDir: true, FileCount: 2_000_000, ModTime: now.Add(-time.Hour)},
+		{Name: "Alpha", Path: "/p/Alpha", Size: 500, IsDir: true, FileCount: 50, ModTime: now},
+		{Name: "file.bin", Path: "/p/file.bin", Size: 300, ModTime: now.Add(-72 * time.Hour)},
+	}
+	prevSizes := map[string]int64{
+		"/p/big":   800, // +100
+		"/p/many":  10,  // +90
+		"/p/Alpha": 600, // -100
+	}
+
+	tests := []struct {
+		name string
+		mode sortMode
+		want []string
+	}{
+		{"size", sortBySize, []string{"big", "Alpha", "file.bin", "many"}},
+		{"file count", sortByFileCount, []string{"many", "Alpha", "big", "file.bin"}},
+		{"name", sortByName, []string{"Alpha", "big", "file.bin", "many"}},
+		{"modified", sortByModTime, []string{"Alpha", "many", "big", "file.bin"}},
+		{"growth", sortByGrowth, []string{"big", "many", "file.bin", "Alpha"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := append([]dirEntry(nil), base...)
+			sortEntries(entries, tt.mode, prevSizes)
+			got := entryNames(entries)
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("sortEntries(%s) = %v, want %v", tt.mode, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSortModeNextCycles(t *testing.T) {
+	mode := sortBySize
+	seen := map[string]bool{}
+	for range int(sortModeCount) {
+		seen[mode.String()] = true
+		mode = mode.next()
+	}
+	if mode != sortBySize {
+		t.Fatalf("expected cycle to return to size, got %s", mode)
+	}
+	if len(seen) != int(sortModeCount) {
+		t.Fatalf("expected %d distinct mode labels, got %v", sortModeCount, seen)
+	}
+}
+
+func TestEntryGrowthUnknownBaseline(t *testing.T) {
+	entry := dirEntry{Path: "/p/new", Size: 42}
+	if got := entryGrowth(entry, nil); got != 0 {
+		t.Fatalf("entryGrowth without baseline = %d, want 0", got)
+	}
+	if got := entryGrowth(entry, map[string]int64{"/p/new": 50}); got != -8 {
+		t.Fatalf("entryGrowth = %d, want -8", got)
+	}
+}
@@ -53,6 +53,15 @@ func (m model) View() string {
 		fmt.Fprintf(&b, "%sAnalyze Disk%s  %s%s%s", colorPurpleBold, colorReset, colorGray, displayPath(m.path), colorReset)
 		if !m.scanning {
 			fmt.Fprintf(&b, "  |  Total: %s", humanizeBytes(m.totalSize))
+			if idx := cachedArchiveIndex(archiveRealPath(m.path)); m.inArchive() && idx != nil {
+				fmt.Fprintf(&b, "  |  Packed: %s", humanizeBytes(idx.DiskSize))
+				if ratio := idx.Ratio(); ratio > 0 {
+					fmt.Fprintf(&b, ", %s", formatCompressionRatio(ratio))
+				}
+			}
+			if m.sortMode != sortBySize && !m.showLargeFiles {
+				fmt.Fprintf(&b, "  |  Sort: %s", m.sortMode)
+			}
 		}
 		fmt.Fprintf(&b, "\n\n")
 	}
@@ -151,8 +160,12 @@ func (m model) View() string {
 				}
 				size := humanizeBytes(file.Size)
 				bar := coloredProgressBar(file.Size, maxLargeSize, 0)
-				fmt.Fprintf(&b, "%s%s %s%2d.%s %s  |  📄 %s%s%s  %s%10s%s\n",
-					entryPrefix, selectIcon, numColor, idx+1, colorReset, bar, nameColor, paddedPath, colorReset, sizeColor, size, colorReset)
+				icon := "📄"
+				if isArchivePath(file.Path) {
+					icon = "📦"
+				}
+				fmt.Fprintf(&b, "%s%s %s%2d.%s %s  |  %s %s%s%s  %s%10s%s\n",
+					entryPrefix, selectIcon, numColor, idx+1, colorReset, bar, icon, nameColor, paddedPath, colorReset, sizeColor, size, colorReset)
 			}
 		}
 	} else {
@@ -248,7 +261,7 @@ func (m model) View() string {
 				}
 
 				viewport := calculateViewport(m.height, false)
-				nameWidth := calculateNameWidth(m.width)
+				nameWidth := calculateNameWidth(m.width - fileCountColumnWidth)
 				start := max(m.offset, 0)
 				end := min(start+viewport, len(m.entries))
 
@@ -257,6 +270,8 @@ func (m model) View() string {
 					icon := "📄"
 					if entry.IsDir {
 						icon = "📁"
+					} else if isArchivePath(entry.Path) {
+						icon = "📦"
 					}
 					size := humanizeBytes(entry.Size)
 					name := trimNameWithWidth(entry.Name, nameWidth)
@@ -305,6 +320,26 @@ func (m model) View() string {
 
 					displayIndex := idx + 1
 
+					// File count column; growth replaces it in growth sort.
+					countText := ""
+					if m.sortMode == sortByGrowth {
+						countText = formatGrowth(entryGrowth(entry, m.prevSizes))
+					} else {
+						var parts []string
+						if entry.IsDir && entry.FileCount > 0 {
+							parts = append(parts, formatNumber(entry.FileCount))
+						} else if entry.IsDir && entry.FileCount < 0 {
+							parts = append(parts, "?")
+						}
+						if entry.CompressedSize > 0 {
+							parts = append(parts, formatCompressionRatio(float64(entry.Size)/float64(entry.CompressedSize)))
+						} else if ratio := archiveFileRatio(entry.Path); ratio > 0 && !entry.IsDir {
+							parts = append(parts, formatCompressionRatio(ratio))
+						}
+						countText = strings.Join(parts, " ")
+					}
+					countColumn := fmt.Sprintf("%s%*s%s", colorGray, fileCountColumnWidth, countText, colorReset)
+
 					var hintLabel string
 					if entry.IsDir && isCleanableDir(entry.Path) {
 						hintLabel = fmt.Sprintf("%s🧹%s", colorYellow, colorReset)
@@ -319,13 +354,13 @@ func (m model) View() string {
 					}
 
 					if hintLabel == "" {
-						fmt.Fprintf(&b, "%s%s %s%2d.%s %s %s%s%s  |  %s %s%10s%s\n",
+						fmt.Fprintf(&b, "%s%s %s%2d.%s %s %s%s%s  |  %s %s%10s%s%s\n",
 							entryPrefix, selectIcon, numColor, displayIndex, colorReset, bar, percentColor, percentStr, colorReset,
-							nameSegment, sizeColor, size, colorReset)
+							nameSegment, sizeColor, size, colorReset, countColumn)
 					} else {
-						fmt.Fprintf(&b, "%s%s %s%2d.%s %s %s%s%s  |  %s %s%10s%s  %s\n",
+						fmt.Fprintf(&b, "%s%s %s%2d.%s %s %s%s%s  |  %s %s%10s%s%s  %s\n",
 							entryPrefix, selectIcon, numColor, displayIndex, colorReset, bar, percentColor, percentStr, colorReset,
-							nameSegment, sizeColor, size, colorReset, hintLabel)
+							nameSegment, sizeColor, size, colorReset, countColumn, hintLabel)
 					}
 				}
 			}
@@ -351,15 +386,15 @@ func (m model) View() string {
 		selectCount := len(m.multiSelected)
 		if selectCount > 0 {
 			if largeFileCount > 0 {
-				fmt.Fprintf(&b, "%s↑↓←→ | Space Select | Enter | R Refresh | O Open | F File | ⌫ Del %d | T Top %d | Q Quit%s\n", colorGray, selectCount, largeFileCount, colorReset)
+				fmt.Fprintf(&b, "%s↑↓←→ | Space Select | Enter | R Refresh | O Open | F File | ⌫ Del %d | T Top %d | S Sort | Q Quit%s\n", colorGray, selectCount, largeFileCount, colorReset)
 			} else {
-				fmt.Fprintf(&b, "%s↑↓←→ | Space Select | Enter | R Refresh | O Open | F File | ⌫ Del %d | Q Quit%s\n", colorGray, selectCount, colorReset)
+				fmt.Fprintf(&b, "%s↑↓←→ | Space Select | Enter | R Refresh | O Open | F File | ⌫ Del %d | S Sort | Q Quit%s\n", colorGray, selectCount, colorReset)
 			}
 		} else {
 			if largeFileCount > 0 {
-				fmt.Fprintf(&b, "%s↑↓←→ | Space Select | Enter | R Refresh | O Open | F File | ⌫ Del | T Top %d | Q Quit%s\n", colorGray, largeFileCount, colorReset)
+				fmt.Fprintf(&b, "%s↑↓←→ | Space Select | Enter | R Refresh | O Open | F File | ⌫ Del | T Top %d | S Sort | Q Quit%s\n", colorGray, largeFileCount, colorReset)
 			} else {
-				fmt.Fprintf(&b, "%s↑↓←→ | Space Select | Enter | R Refresh | O Open | F File | ⌫ Del | Q Quit%s\n", colorGray, colorReset)
+				fmt.Fprintf(&b, "%s↑↓←→ | Space Select | Enter | R Refresh | O Open | F File | ⌫ Del | S Sort | Q Quit%s\n", colorGray, colorReset)
 			}
 		}
 	}
@@ -0,0 +1,671 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Alert rules live in a plain text file, one rule or sink per line:
+//
+//	# Fire when / has been over 90% for five minutes; resolve below 85%.
+//	disk["/"].used_percent > 90 for 5m clear 85
+//	cpu.usage > 95 for 2m
+//	battery.percent < 10
+//
+//	notify                          # desktop notification
+//	notify /usr/local/bin/page-me   # or any command
+//	webhook https://example.com/hook
+//	log ~/.cache/mole/status_alerts.log
+//
+// A # starts a comment at the start of a line or after a space, so URLs
+// with fragments are kept whole.
+//
+// Without "clear", a rule resolves once the value is 5% of the threshold back
+// on the safe side, so a metric hovering at the threshold does not flap.
+
+const (
+	alertHysteresis    = 0.05
+	alertSinkTimeout   = 10 * time.Second
+	alertQueueSize     = 64 // Pending dispatches before new events are dropped
+	alertDefaultLogRel = ".cache/mole/status_alerts.log"
+)
+
+// alertMetric reads one value from a snapshot. key is the text in brackets,
+// empty when the rule has none.
+type alertMetric struct {
+	keyed bool // Accepts a [key]
+	read  func(m MetricsSnapshot, key string) (float64, bool)
+}
+
+func unkeyed(read func(m MetricsSnapshot) float64) alertMetric {
+	return alertMetric{read: func(m MetricsSnapshot, _ string) (float64, bool) { return read(m), true }}
+}
+
+var alertMetrics = map[string]alertMetric{
+	"cpu.usage":  unkeyed(func(m MetricsSnapshot) float64 { return m.CPU.Usage }),
+	"cpu.load1":  unkeyed(func(m MetricsSnapshot) float64 { return m.CPU.Load1 }),
+	"cpu.load5":  unkeyed(func(m MetricsSnapshot) float64 { return m.CPU.Load5 }),
+	"cpu.load15": unkeyed(func(m MetricsSnapshot) float64 { return m.CPU.Load15 }),
+
+	"memory.used_percent": unkeyed(func(m MetricsSnapshot) float64 { return m.Memory.UsedPercent }),
+	"memory.swap_percent": unkeyed(func(m MetricsSnapshot) float64 { return sampleFromSnapshot(m).Values[histSwap] }),
+
+	// Without a mount, the fullest data volume; with one, any mount.
+	"disk.used_percent": {keyed: true, read: func(m MetricsSnapshot, mount string) (float64, bool) {
+		disks := m.Disks
+		if mount != "" {
+			disks = m.allMounts()
+		}
+		found, fullest := false, 0.0
+		for _, d := range disks {
+			if mount == "" || d.Mount == mount {
+				found, fullest = true, max(fullest, d.UsedPercent)
+			}
+		}
+		return fullest, found
+	}},
+	"diskio.read_rate_mbs":  unkeyed(func(m MetricsSnapshot) float64 { return m.DiskIO.ReadRate }),
+	"diskio.write_rate_mbs": unkeyed(func(m MetricsSnapshot) float64 { return m.DiskIO.WriteRate }),
+
+	// Without an interface, the total across interfaces.
+	"net.rx_rate_mbs": {keyed: true, read: func(m MetricsSnapshot, name string) (float64, bool) {
+		return sumNetwork(m.Network, name, func(n NetworkStatus) float64 { return n.RxRateMBs })
+	}},
+	"net.tx_rate_mbs": {keyed: true, read: func(m MetricsSnapshot, name string) (float64, bool) {
+		return sumNetwork(m.Network, name, func(n NetworkStatus) float64 { return n.TxRateMBs })
+	}},
+
+	"gpu.usage": {read: func(m MetricsSnapshot, _ string) (float64, bool) {
+		if len(m.GPU) == 0 || m.GPU[0].Usage < 0 {
+			return 0, false
+		}
+		return m.GPU[0].Usage, true
+	}},
+	"battery.percent": {read: func(m MetricsSnapshot, _ string) (float64, bool) {
+		if len(m.Batteries) == 0 {
+			return 0, false
+		}
+		return m.Batteries[0].Percent, true
+	}},
+
+	"thermal.cpu_temp":     unkeyed(func(m MetricsSnapshot) float64 { return m.Thermal.CPUTemp }),
+	"thermal.gpu_temp":     unkeyed(func(m MetricsSnapshot) float64 { return m.Thermal.GPUTemp }),
+	"thermal.fan_speed":    unkeyed(func(m MetricsSnapshot) float64 { return float64(m.Thermal.FanSpeed) }),
+	"thermal.system_power": unkeyed(func(m MetricsSnapshot) float64 { return m.Thermal.SystemPower }),
+
+	"health.score": unkeyed(func(m MetricsSnapshot) float64 { return float64(m.HealthScore) }),
+}
+
+func sumNetwork(nets []NetworkStatus, name string, rate func(NetworkStatus) float64) (float64, bool) {
+	found, total := name == "", 0.0
+	for _, n := range nets {
+		if name == "" || n.Name == name {
+			found = true
+			total += rate(n)
+		}
+	}
+	return total, found
+}
+
+// alertRule is one parsed rule line.
+type alertRule struct {
+	text      string
+	metric    alertMetric
+	key       string
+	op        string // >, >=, < or <=
+	threshold float64
+	clear     float64
+	hold      time.Duration
+}
+
+func (r alertRule) above() bool { return r.op == ">" || r.op == ">=" }
+
+func (r alertRule) breached(v float64) bool {
+	switch r.op {
+	case ">":
+		return v > r.threshold
+	case ">=":
+		return v >= r.threshold
+	case "<":
+		return v < r.threshold
+	default:
+		return v <= r.threshold
+	}
+}
+
+func (r alertRule) cleared(v float64) bool {
+	if r.above() {
+		return v < r.clear
+	}
+	return v > r.clear
+}
+
+var alertRuleRe = regexp.MustCompile(`^([a-z_]+)(?:\[\s*"([^"]*)"\s*\])?\.([a-z_0-9]+)\s*(>=|<=|>|<)\s*(\S+)(?:\s+for\s+(\S+))?(?:\s+clear\s+(\S+))?$`)
+
+func parseAlertRule(line string) (alertRule, error) {
+	match := alertRuleRe.FindStringSubmatch(line)
+	if match == nil {
+		return alertRule{}, errors.New(`expected <metric> <op> <value> [for <duration>] [clear <value>]`)
+	}
+	name := match[1] + "." + match[3]
+	metric, ok := alertMetrics[name]
+	if !ok {
+		return alertRule{}, fmt.Errorf("unknown metric %q", name)
+	}
+	if match[2] != "" && !metric.keyed {
+		return alertRule{}, fmt.Errorf("%s does not take a [key]", name)
+	}
+	rule := alertRule{text: line, metric: metric, key: match[2], op: match[4]}
+
+	var err error
+	if rule.threshold, err = strconv.ParseFloat(match[5], 64); err != nil {
+		return alertRule{}, fmt.Errorf("invalid threshold %q", match[5])
+	}
+	if match[6] != "" {
+		if rule.hold, err = time.ParseDuration(match[6]); err != nil || rule.hold < 0 {
+			return alertRule{}, fmt.Errorf("invalid duration %q", match[6])
+		}
+	}
+
+	margin := abs(rule.threshold) * alertHysteresis
+	if !rule.above() {
+		margin = -margin
+	}
+	rule.clear = rule.threshold - margin
+	if match[7] != "" {
+		if rule.clear, err = strconv.ParseFloat(match[7], 64); err != nil {
+			return alertRule{}, fmt.Errorf("invalid clear value %q", match[7])
+		}
+		if rule.breached(rule.clear) {
+			return alertRule{}, fmt.Errorf("clear value %s would keep the rule firing", match[7])
+		}
+	}
+	return rule, nil
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// alertEvent is a rule changing state. It is also the webhook payload.
+type alertEvent struct {
+	Rule      string    `json:"rule"`
+	State     string    `json:"state"` // firing or resolved
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Time      time.Time `json:"time"`
+}
+
+const (
+	alertFiring   = "firing"
+	alertResolved = "resolved"
+)
+
+func (e alertEvent) message() string {
+	return fmt.Sprintf("%s: %s (now %s)", strings.ToUpper(e.State), e.Rule, formatHistoryValue(e.Value))
+}
+
+// alertSink receives rule state changes.
+type alertSink interface {
+	Notify(e alertEvent) error
+}
+
+// alertBanner keeps the currently firing alerts for the TUI.
+type alertBanner struct {
+	mu      sync.Mutex
+	active  map[string]alertEvent
+	err     error // Last delivery error, cleared once a delivery succeeds
+	dropped error // Events dropped while the sinks were behind, cleared once they catch up
+}
+
+func newAlertBanner() *alertBanner {
+	return &alertBanner{active: make(map[string]alertEvent)}
+}
+
+func (b *alertBanner) Notify(e alertEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if e.State == alertFiring {
+		b.active[e.Rule] = e
+	} else {
+		delete(b.active, e.Rule)
+	}
+	return nil
+}
+
+func (b *alertBanner) setErr(err error) {
+	b.mu.Lock()
+	b.err = err
+	b.mu.Unlock()
+}
+
+func (b *alertBanner) setDropped(err error) {
+	b.mu.Lock()
+	b.dropped = err
+	b.mu.Unlock()
+}
+
+// refresh updates the current value of a firing alert.
+func (b *alertBanner) refresh(rule string, value float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if a, ok := b.active[rule]; ok {
+		a.Value = value
+		b.active[rule] = a
+	}
+}
+
+// Active returns firing alerts, oldest first.
+func (b *alertBanner) Active() ([]alertEvent, error) {
+	if b == nil {
+		return nil, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]alertEvent, 0, len(b.active))
+	for _, e := range b.active {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Time.Equal(out[j].Time) {
+			return out[i].Time.Before(out[j].Time)
+		}
+		return out[i].Rule < out[j].Rule
+	})
+	return out, errors.Join(b.dropped, b.err)
+}
+
+// commandSink runs a command per event. The message is the last argument and
+// the event is also passed as MOLE_ALERT_* environment variables.
+// A nil command uses the platform's desktop notifier.
+type commandSink struct {
+	command []string
+}
+
+func (s commandSink) Notify(e alertEvent) error {
+	argv := s.command
+	if argv == nil {
+		argv = desktopNotifyCommand("Mole", e.message())
+		if argv == nil {
+			return fmt.Errorf("no desktop notifier on %s", runtime.GOOS)
+		}
+	} else {
+		argv = append(append([]string{}, argv...), e.message())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), alertSinkTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Env = append(os.Environ(),
+		"MOLE_ALERT_RULE="+e.Rule,
+		"MOLE_ALERT_STATE="+e.State,
+		"MOLE_ALERT_VALUE="+formatHistoryValue(e.Value),
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notify %s: %v: %s", argv[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func desktopNotifyCommand(title, msg string) []string {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(msg), strconv.Quote(title))
+		return []string{"osascript", "-e", script}
+	case "linux":
+		return []string{"notify-send", title, msg}
+	}
+	return nil
+}
+
+// webhookSink posts each event as JSON.
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s webhookSink) Notify(e alertEvent) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()               //nolint:errcheck
+	_, _ = io.Copy(io.Discard, resp.Body) // Drain for connection reuse.
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s: %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+// logSink appends one line per event.
+type logSink struct {
+	path string
+}
+
+func (s logSink) Notify(e alertEvent) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%s %s\n", e.Time.Format(time.RFC3339), e.message())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+type alertState struct {
+	breachedSince time.Time
+	firing        bool
+	value         float64 // Latest reading while firing
+}
+
+// alertEngine evaluates rules against each snapshot and fans state changes
+// out to its sinks. The banner is kept apart from the sinks and always
+// follows the latest evaluation.
+type alertEngine struct {
+	rules  []alertRule
+	states []alertState
+	banner *alertBanner
+	sinks  []alertSink
+	errLog io.Writer // Where delivery errors go without a banner on screen
+
+	mu     sync.Mutex        // Guards the fields below and states for Observe
+	queue  chan []alertEvent // Feeds the single dispatcher, in order
+	done   chan struct{}
+	closed bool
+}
+
+// alertRulesPath returns ~/.config/mole/status_alerts.
+func alertRulesPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "mole", "status_alerts")
+}
+
+// openAlertEngine loads the rules file for a run. A missing file is fine
+// unless the user named it.
+func openAlertEngine(path string, named bool) (*alertEngine, error) {
+	if path == "" {
+		return nil, nil
+	}
+	engine, err := loadAlertEngine(path)
+	if os.IsNotExist(err) && !named {
+		return nil, nil
+	}
+	return engine, err
+}
+
+// loadAlertEngine parses a rules file.
+func loadAlertEngine(path string) (*alertEngine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+	engine, err := parseAlertEngine(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", path, err)
+	}
+	return engine, nil
+}
+
+func parseAlertEngine(r io.Reader) (*alertEngine, error) {
+	engine := &alertEngine{banner: newAlertBanner()}
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		fields := strings.Fields(stripAlertComment(scanner.Text()))
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "notify":
+			sink := commandSink{}
+			if len(fields) > 1 {
+				sink.command = fields[1:]
+			} else if desktopNotifyCommand("", "") == nil {
+				return nil, fmt.Errorf("%d: no desktop notifier on %s; give notify a command", lineNo, runtime.GOOS)
+			}
+			engine.sinks = append(engine.sinks, sink)
+		case "webhook":
+			if len(fields) != 2 || !strings.HasPrefix(fields[1], "http") {
+				return nil, fmt.Errorf("%d: webhook needs one http(s) URL", lineNo)
+			}
+			engine.sinks = append(engine.sinks, webhookSink{url: fields[1], client: &http.Client{Timeout: alertSinkTimeout}})
+		case "log":
+			path := ""
+			switch len(fields) {
+			case 1:
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return nil, fmt.Errorf("%d: %v", lineNo, err)
+				}
+				path = filepath.Join(home, alertDefaultLogRel)
+			case 2:
+				path = expandHome(fields[1])
+			default:
+				return nil, fmt.Errorf("%d: log takes at most one path", lineNo)
+			}
+			engine.sinks = append(engine.sinks, logSink{path: path})
+		default:
+			rule, err := parseAlertRule(strings.Join(fields, " "))
+			if err != nil {
+				return nil, fmt.Errorf("%d: %v", lineNo, err)
+			}
+			engine.rules = append(engine.rules, rule)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	engine.states = make([]alertState, len(engine.rules))
+	return engine, nil
+}
+
+// stripAlertComment drops a # comment that starts the line or follows a space.
+func stripAlertComment(line string) string {
+	for i := range len(line) {
+		if line[i] == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// Evaluate updates rule states from a snapshot and returns the transitions.
+// A rule fires once breached for its whole "for" duration and resolves when
+// it crosses back over its clear value. Missing values leave the state as is.
+func (e *alertEngine) Evaluate(m MetricsSnapshot, now time.Time) []alertEvent {
+	if e == nil {
+		return nil
+	}
+	var events []alertEvent
+	for i, rule := range e.rules {
+		v, ok := rule.metric.read(m, rule.key)
+		if !ok {
+			continue
+		}
+		st := &e.states[i]
+		st.value = v
+		event := alertEvent{Rule: rule.text, Value: v, Threshold: rule.threshold, Time: now}
+		switch {
+		case st.firing:
+			if rule.cleared(v) {
+				st.firing = false
+				st.breachedSince = time.Time{}
+				event.State = alertResolved
+				events = append(events, event)
+			}
+		case rule.breached(v):
+			if st.breachedSince.IsZero() {
+				st.breachedSince = now
+			}
+			if now.Sub(st.breachedSince) >= rule.hold {
+				st.firing = true
+				event.State = alertFiring
+				events = append(events, event)
+			}
+		default:
+			st.breachedSince = time.Time{}
+		}
+	}
+	return events
+}
+
+// Observe evaluates a snapshot and hands any transitions to the dispatcher.
+// The banner is updated here, so a slow sink never delays it, and a single
+// dispatcher goroutine delivers to the other sinks in order.
+func (e *alertEngine) Observe(m MetricsSnapshot) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	events := e.Evaluate(m, m.CollectedAt)
+	for _, event := range events {
+		_ = e.banner.Notify(event)
+	}
+	for i, st := range e.states {
+		if st.firing {
+			e.banner.refresh(e.rules[i].text, st.value)
+		}
+	}
+	if len(events) == 0 || e.closed {
+		return
+	}
+
+	if e.queue == nil {
+		e.queue = make(chan []alertEvent, alertQueueSize)
+		e.done = make(chan struct{})
+		go e.dispatchLoop(e.queue, e.done)
+	}
+	select {
+	case e.queue <- events:
+	default:
+		e.banner.setDropped(fmt.Errorf("sinks are behind; dropped %d events", len(events)))
+	}
+}
+
+func (e *alertEngine) dispatchLoop(queue <-chan []alertEvent, done chan<- struct{}) {
+	defer close(done)
+	for events := range queue {
+		err := e.Dispatch(events)
+		if err != nil && e.errLog != nil {
+			fmt.Fprintf(e.errLog, "alert delivery: %v\n", err)
+		}
+		if err == nil && len(queue) == 0 {
+			e.banner.setDropped(nil)
+		}
+	}
+}
+
+// watch returns collect with every sample passed to Observe.
+func (e *alertEngine) watch(collect snapshotSource) snapshotSource {
+	if e == nil {
+		return collect
+	}
+	return func() (MetricsSnapshot, error) {
+		m, err := collect()
+		e.Observe(m)
+		return m, err
+	}
+}
+
+// Close waits for queued events to be delivered. Later snapshots are
+// still evaluated but no longer dispatched.
+func (e *alertEngine) Close() {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	queue, done := e.queue, e.done
+	if e.closed {
+		queue = nil
+	}
+	e.closed = true
+	e.mu.Unlock()
+	if queue != nil {
+		close(queue)
+		<-done
+	}
+}
+
+// Dispatch delivers events to every sink, in order, and returns once they
+// all have. Sink errors are shown under the banner until a delivery succeeds.
+func (e *alertEngine) Dispatch(events []alertEvent) error {
+	if e == nil || len(events) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, sink := range e.sinks {
+		for _, event := range events {
+			if err := sink.Notify(event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	err := errors.Join(errs...)
+	e.banner.setErr(err)
+	return err
+}
+
+// Active returns the firing alerts and the last sink error.
+func (e *alertEngine) Active() ([]alertEvent, error) {
+	if e == nil {
+		return nil, nil
+	}
+	return e.banner.Active()
+}
+
+// renderAlertBanner draws one line per firing alert.
+func renderAlertBanner(active []alertEvent, sinkErr error, width int) string {
+	var lines []string
+	for _, a := range active {
+		since := a.Time.Format("15:04")
+		lines = append(lines, dangerStyle.Render("▲ "+shorten(a.Rule, max(width-24, 20)))+subtleStyle.Render(fmt.Sprintf("  since %s · now %s", since, strconv.FormatFloat(a.Value, 'f', 1, 64))))
+	}
+	if sinkErr != nil {
+		lines = append(lines, warnStyle.Render("alert delivery: "+shorten(strings.ReplaceAll(sinkErr.Error(), "\n", "; "), max(width-18, 20))))
+	}
+	return strings.Join(lines, "\n")
+}
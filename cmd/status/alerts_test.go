@@ -0,0 +1,278 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAlertRule(t *testing.T) {
+	tests := []struct {
+		line      string
+		wantErr   string
+		key       string
+		threshold float64
+		clear     float64
+		hold      time.Duration
+	}{
+		{line: `disk["/"].used_percent > 90 for 5m`, key: "/", threshold: 90, clear: 85.5, hold: 5 * time.Minute},
+		{line: `cpu.usage > 95 for 2m clear 80`, threshold: 95, clear: 80, hold: 2 * time.Minute},
+		{line: `battery.percent < 10`, threshold: 10, clear: 10.5},
+		{line: `net["en0"].rx_rate_mbs >= 50`, key: "en0", threshold: 50, clear: 47.5},
+		{line: `cpu.temperature > 90`, wantErr: "unknown metric"},
+		{line: `cpu["0"].usage > 90`, wantErr: "does not take a [key]"},
+		{line: `cpu.usage > high`, wantErr: "invalid threshold"},
+		{line: `cpu.usage > 90 for soon`, wantErr: "invalid duration"},
+		{line: `cpu.usage > 90 clear 95`, wantErr: "keep the rule firing"},
+		{line: `cpu.usage is high`, wantErr: "expected"},
+	}
+	for _, tt := range tests {
+		rule, err := parseAlertRule(tt.line)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseAlertRule(%q) error = %v, want %q", tt.line, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseAlertRule(%q): %v", tt.line, err)
+			continue
+		}
+		if rule.key != tt.key || rule.threshold != tt.threshold || rule.clear != tt.clear || rule.hold != tt.hold {
+			t.Errorf("parseAlertRule(%q) = key %q threshold %v clear %v hold %v", tt.line, rule.key, rule.threshold, rule.clear, rule.hold)
+		}
+	}
+}
+
+func TestParseAlertEngineReportsLine(t *testing.T) {
+	_, err := parseAlertEngine(strings.NewReader("# comment\ncpu.usage > 90\nwebhook\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "3:") {
+		t.Errorf("expected an error on line 3, got %v", err)
+	}
+}
+
+func diskSnapshot(used float64) MetricsSnapshot {
+	return MetricsSnapshot{Disks: []DiskStatus{{Mount: "/", UsedPercent: used}, {Mount: "/data", UsedPercent: 10}}}
+}
+
+func TestAlertEngineHoldAndHysteresis(t *testing.T) {
+	engine, err := parseAlertEngine(strings.NewReader(`disk["/"].used_percent > 90 for 5m`))
+	if err != nil {
+		t.Fatalf("parseAlertEngine: %v", err)
+	}
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		after time.Duration
+		used  float64
+		want  string // Expected transition, empty for none
+	}{
+		{0, 95, ""},
+		{2 * time.Minute, 80, ""}, // Dipped: the hold restarts.
+		{3 * time.Minute, 95, ""},
+		{7 * time.Minute, 95, ""},
+		{8 * time.Minute, 96, alertFiring},
+		{9 * time.Minute, 88, ""}, // Below the threshold but above the clear value.
+		{10 * time.Minute, 99, ""},
+		{11 * time.Minute, 85, alertResolved},
+	}
+	for _, step := range steps {
+		events := engine.Evaluate(diskSnapshot(step.used), start.Add(step.after))
+		got := ""
+		if len(events) == 1 {
+			got = events[0].State
+		} else if len(events) > 1 {
+			t.Fatalf("at %v: %d events", step.after, len(events))
+		}
+		if got != step.want {
+			t.Errorf("at %v used %v: transition %q, want %q", step.after, step.used, got, step.want)
+		}
+	}
+
+	// Missing data leaves the state untouched.
+	if events := engine.Evaluate(MetricsSnapshot{}, start.Add(time.Hour)); len(events) != 0 {
+		t.Errorf("missing disk should not transition: %+v", events)
+	}
+}
+
+type recordingSink struct {
+	events []alertEvent
+	err    error
+}
+
+func (s *recordingSink) Notify(e alertEvent) error {
+	s.events = append(s.events, e)
+	return s.err
+}
+
+func TestAlertEngineObserveUpdatesBanner(t *testing.T) {
+	engine, err := parseAlertEngine(strings.NewReader("cpu.usage > 90\n"))
+	if err != nil {
+		t.Fatalf("parseAlertEngine: %v", err)
+	}
+	failing := &recordingSink{err: errors.New("offline")}
+	engine.sinks = append(engine.sinks, failing)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	engine.Observe(MetricsSnapshot{CPU: CPUStatus{Usage: 97}, CollectedAt: now})
+	engine.Close()
+	active, sinkErr := engine.Active()
+	if len(active) != 1 || active[0].Value != 97 || sinkErr == nil {
+		t.Fatalf("Active() = %+v, %v", active, sinkErr)
+	}
+	if len(failing.events) != 1 {
+		t.Errorf("other sinks should still receive the event")
+	}
+	if banner := renderAlertBanner(active, sinkErr, 100); !strings.Contains(banner, "cpu.usage > 90") || !strings.Contains(banner, "offline") {
+		t.Errorf("banner missing rule or error:\n%s", banner)
+	}
+
+	// A late delivery of the firing event must not bring the alert back.
+	resolved := MetricsSnapshot{CPU: CPUStatus{Usage: 10}, CollectedAt: now.Add(time.Second)}
+	engine.Observe(resolved)
+	failing.err = nil
+	if err := engine.Dispatch([]alertEvent{active[0]}); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if active, sinkErr := engine.Active(); len(active) != 0 || sinkErr != nil {
+		t.Errorf("banner should stay clear and drop the error once delivery succeeds: %+v, %v", active, sinkErr)
+	}
+}
+
+func TestAlertBannerShowsLatestValue(t *testing.T) {
+	engine, err := parseAlertEngine(strings.NewReader("cpu.usage > 90\n"))
+	if err != nil {
+		t.Fatalf("parseAlertEngine: %v", err)
+	}
+	defer engine.Close()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	engine.Observe(MetricsSnapshot{CPU: CPUStatus{Usage: 97}, CollectedAt: now})
+	engine.Observe(MetricsSnapshot{CPU: CPUStatus{Usage: 93}, CollectedAt: now.Add(time.Second)})
+	active, _ := engine.Active()
+	if len(active) != 1 || active[0].Value != 93 || !active[0].Time.Equal(now) {
+		t.Errorf("banner should keep the firing time and show the latest value, got %+v", active)
+	}
+}
+
+func TestAlertEngineObserveDeliversInOrder(t *testing.T) {
+	engine, err := parseAlertEngine(strings.NewReader("cpu.usage > 90 # webhook https://example.com/hook#frag\n"))
+	if err != nil {
+		t.Fatalf("parseAlertEngine: %v", err)
+	}
+	sink := &recordingSink{}
+	engine.sinks = append(engine.sinks, sink)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	collect := engine.watch(func() (MetricsSnapshot, error) {
+		now = now.Add(time.Second)
+		usage := 97.0
+		if now.Second()%2 == 0 {
+			usage = 10
+		}
+		return MetricsSnapshot{CPU: CPUStatus{Usage: usage}, CollectedAt: now}, nil
+	})
+	for range 20 {
+		_, _ = collect()
+	}
+	if active, _ := engine.Active(); len(active) != 0 {
+		t.Errorf("the banner should follow the last event at once, got %+v", active)
+	}
+	engine.Close()
+
+	if len(sink.events) != 20 {
+		t.Fatalf("delivered %d events, want 20", len(sink.events))
+	}
+	for i, e := range sink.events {
+		if want := []string{alertFiring, alertResolved}[i%2]; e.State != want {
+			t.Fatalf("event %d is %s, want %s: events were reordered", i, e.State, want)
+		}
+	}
+}
+
+func TestStripAlertComment(t *testing.T) {
+	tests := map[string]string{
+		"# comment":                           "",
+		"cpu.usage > 90  # busy":              "cpu.usage > 90  ",
+		"webhook https://example.com/hook#me": "webhook https://example.com/hook#me",
+	}
+	for line, want := range tests {
+		if got := stripAlertComment(line); got != want {
+			t.Errorf("stripAlertComment(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got alertEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	engine, err := parseAlertEngine(strings.NewReader("cpu.usage > 90\nwebhook " + server.URL + "\n"))
+	if err != nil {
+		t.Fatalf("parseAlertEngine: %v", err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := engine.Dispatch(engine.Evaluate(MetricsSnapshot{CPU: CPUStatus{Usage: 99}}, now)); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if got.Rule != "cpu.usage > 90" || got.State != alertFiring || got.Value != 99 || !got.Time.Equal(now) {
+		t.Errorf("webhook payload = %+v", got)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	sink := webhookSink{url: failing.URL, client: failing.Client()}
+	if err := sink.Notify(got); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("expected status error, got %v", err)
+	}
+}
+
+func TestLogSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "alerts.log")
+	sink := logSink{path: path}
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	_ = sink.Notify(alertEvent{Rule: "cpu.usage > 90", State: alertFiring, Value: 93.456, Time: at})
+	_ = sink.Notify(alertEvent{Rule: "cpu.usage > 90", State: alertResolved, Value: 40, Time: at.Add(time.Minute)})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	want := "2026-03-01T12:00:00Z FIRING: cpu.usage > 90 (now 93.46)\n" +
+		"2026-03-01T12:01:00Z RESOLVED: cpu.usage > 90 (now 40)\n"
+	if string(data) != want {
+		t.Errorf("log =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestCommandSink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	sink := commandSink{command: []string{"sh", "-c", `printf '%s|%s|%s' "$MOLE_ALERT_STATE" "$MOLE_ALERT_VALUE" "$1" > "$0"`, out}}
+	if err := sink.Notify(alertEvent{Rule: "cpu.usage > 90", State: alertFiring, Value: 91}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	if string(data) != "firing|91|FIRING: cpu.usage > 90 (now 91)" {
+		t.Errorf("command saw %q", data)
+	}
+}
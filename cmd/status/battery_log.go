@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	batteryLogHeader   = "# mole status battery v1"
+	batteryWearDays    = 730             // Daily capacity samples kept
+	batterySessionsMax = 100             // Closed sessions kept per battery
+	batterySessionMin  = 2 * time.Minute // Shorter sessions are plug flapping
+	batteryRateTau     = 5 * time.Minute // Time constant of the smoothed discharge rate
+	batteryWearMinSpan = 14 * 24 * time.Hour
+	batteryResumeGap   = 10 * time.Minute // A logged open session older than this ended while mole was not running
+)
+
+// batteryLogPath returns ~/.cache/mole/status_battery.csv.
+func batteryLogPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".cache", "mole", "status_battery.csv")
+}
+
+// batteryTracker smooths the discharge rate, records charge sessions and
+// keeps one capacity sample per day, persisted so wear shows across runs.
+// The log is read on the first battery seen, so desktops never touch it.
+// Open sessions are logged too, so one that spans a restart resumes.
+type batteryTracker struct {
+	mu       sync.Mutex
+	path     string // Empty keeps everything in memory
+	loaded   bool
+	days     map[string][]BatteryCapacitySample
+	sessions map[string][]ChargeSession // Closed, oldest first
+	open     map[string]*ChargeSession  // End and To are the last reading
+	rates    map[string]*batteryRate
+}
+
+type batteryRate struct {
+	watts    float64
+	at       time.Time // When watts last changed
+	energy   float64   // Wh, for batteries without power_now
+	energyAt time.Time // When energy last changed
+}
+
+func newBatteryTracker(path string) *batteryTracker {
+	return &batteryTracker{
+		path:     path,
+		days:     make(map[string][]BatteryCapacitySample),
+		sessions: make(map[string][]ChargeSession),
+		open:     make(map[string]*ChargeSession),
+		rates:    make(map[string]*batteryRate),
+	}
+}
+
+// batterySessionKind maps a charger status to charge, discharge or idle ("").
+func batterySessionKind(status string) string {
+	switch s := strings.ToLower(status); {
+	case strings.Contains(s, "discharging"):
+		return "discharge"
+	case strings.Contains(s, "charging") && !strings.Contains(s, "not charging"):
+		return "charge"
+	}
+	return ""
+}
+
+// Annotate fills time to empty, wear and sessions on freshly collected
+// batteries. Writing the log is best effort; its error is returned.
+func (t *batteryTracker) Annotate(batts []BatteryStatus, now time.Time) error {
+	if len(batts) == 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	dirty := false
+	if !t.loaded {
+		t.loaded = true
+		t.load()
+		for name, open := range t.open {
+			if now.Sub(open.End) > batteryResumeGap {
+				dirty = t.closeSession(name, *open) || dirty
+				delete(t.open, name)
+			}
+		}
+	}
+
+	for i := range batts {
+		b := &batts[i]
+		kind := batterySessionKind(b.Status)
+		t.updateRate(b, kind, now)
+		if t.trackSession(b, kind, now) {
+			dirty = true
+		}
+		if t.sampleCapacity(b, now) {
+			dirty = true
+		}
+
+		if days := t.days[b.Name]; len(days) > 0 {
+			b.Wear = &BatteryWear{Latest: days[len(days)-1], Days: len(days), PerMonth: wearPerMonth(days), Samples: slices.Clone(days)}
+		}
+		closed := t.sessions[b.Name]
+		if n := len(closed); n > 0 {
+			last := closed[n-1]
+			b.LastSession = &last
+		}
+		sessions := slices.Clone(closed)
+		if s := t.open[b.Name]; s != nil {
+			ongoing := *s
+			ongoing.Ongoing = true
+			b.Session = &ongoing
+			sessions = append(sessions, ongoing)
+		}
+		if len(sessions) > 0 {
+			b.Sessions = sessions
+		}
+	}
+	if dirty && t.path != "" {
+		return t.save()
+	}
+	return nil
+}
+
+// updateRate folds the discharge power into an exponential average and
+// derives time to empty from it. Power comes from power_now, or from the
+// energy drop between samples when the driver does not report it.
+func (t *batteryTracker) updateRate(b *BatteryStatus, kind string, now time.Time) {
+	r := t.rates[b.Name]
+	if kind != "discharge" {
+		delete(t.rates, b.Name)
+		return
+	}
+	watts := b.Power
+	if r == nil {
+		r = &batteryRate{watts: max(watts, 0), at: now, energy: b.EnergyNow, energyAt: now}
+		t.rates[b.Name] = r
+	} else {
+		if watts <= 0 && b.EnergyNow > 0 && b.EnergyNow < r.energy {
+			if hours := now.Sub(r.energyAt).Hours(); hours > 0 {
+				watts = (r.energy - b.EnergyNow) / hours
+			}
+		}
+		if b.EnergyNow != r.energy {
+			r.energy, r.energyAt = b.EnergyNow, now
+		}
+		if watts > 0 {
+			alpha := 1 - math.Exp(-now.Sub(r.at).Seconds()/batteryRateTau.Seconds())
+			if r.watts <= 0 {
+				alpha = 1
+			}
+			r.watts += alpha * (watts - r.watts)
+			r.at = now
+		}
+	}
+
+	b.DischargeRate = r.watts
+	if b.TimeLeft == "" && r.watts > 0 && b.EnergyNow > 0 {
+		b.TimeLeft = formatBatteryTime(time.Duration(b.EnergyNow / r.watts * float64(time.Hour)))
+	}
+}
+
+// formatBatteryTime matches pmset's h:mm.
+func formatBatteryTime(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+}
+
+// trackSession extends the open session, or closes it when the charger
+// state changes. It reports whether a session was recorded.
+func (t *batteryTracker) trackSession(b *BatteryStatus, kind string, now time.Time) bool {
+	open := t.open[b.Name]
+	if open != nil {
+		open.End, open.To = now, b.Percent
+		if open.Kind == kind {
+			return false
+		}
+	}
+	recorded := false
+	if open != nil {
+		recorded = t.closeSession(b.Name, *open)
+		delete(t.open, b.Name)
+	}
+	if kind != "" {
+		t.open[b.Name] = &ChargeSession{Kind: kind, Start: now, End: now, From: b.Percent, To: b.Percent}
+	}
+	return recorded
+}
+
+// closeSession keeps a finished session unless it is too short to matter.
+func (t *batteryTracker) closeSession(name string, s ChargeSession) bool {
+	if s.End.Sub(s.Start) < batterySessionMin {
+		return false
+	}
+	sessions := append(t.sessions[name], s)
+	if len(sessions) > batterySessionsMax {
+		sessions = sessions[len(sessions)-batterySessionsMax:]
+	}
+	t.sessions[name] = sessions
+	return true
+}
+
+// Close logs the open sessions with their last reading.
+func (t *batteryTracker) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.path == "" || len(t.open) == 0 {
+		return nil
+	}
+	return t.save()
+}
+
+// sampleCapacity records the first capacity reading of each local day.
+func (t *batteryTracker) sampleCapacity(b *BatteryStatus, now time.Time) bool {
+	capacity := float64(b.Capacity)
+	if b.EnergyFull > 0 && b.EnergyDesign > 0 {
+		capacity = math.Round(b.EnergyFull/b.EnergyDesign*1000) / 10
+	}
+	if capacity <= 0 {
+		return false
+	}
+	date := now.Local().Format(time.DateOnly)
+	days := t.days[b.Name]
+	if n := len(days); n > 0 && days[n-1].Date >= date {
+		return false
+	}
+	days = append(days, BatteryCapacitySample{Date: date, Capacity: capacity, Cycles: b.CycleCount})
+	if len(days) > batteryWearDays {
+		days = days[len(days)-batteryWearDays:]
+	}
+	t.days[b.Name] = days
+	return true
+}
+
+// wearPerMonth is the least-squares slope of capacity over 30 days.
+func wearPerMonth(days []BatteryCapacitySample) float64 {
+	var xs, ys []float64
+	for _, d := range days {
+		at, err := time.Parse(time.DateOnly, d.Date)
+		if err != nil {
+			continue
+		}
+		xs = append(xs, float64(at.Unix())/86400)
+		ys = append(ys, d.Capacity)
+	}
+	if len(xs) < 2 || (xs[len(xs)-1]-xs[0])*24 < batteryWearMinSpan.Hours() {
+		return 0
+	}
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+	var num, den float64
+	for i := range xs {
+		num += (xs[i] - meanX) * (ys[i] - meanY)
+		den += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	if den == 0 {
+		return 0
+	}
+	return num / den * 30
+}
+
+// load reads the log; a missing or unreadable log starts empty.
+func (t *batteryTracker) load() {
+	if t.path == "" {
+		return
+	}
+	f, err := os.Open(t.path)
+	if err != nil {
+		return
+	}
+	defer f.Close() //nolint:errcheck
+	days, sessions, open := parseBatteryLog(f)
+	for name, d := range days {
+		t.days[name] = d[max(len(d)-batteryWearDays, 0):]
+	}
+	for name, s := range sessions {
+		t.sessions[name] = s[max(len(s)-batterySessionsMax, 0):]
+	}
+	for name, s := range open {
+		t.open[name] = &s
+	}
+}
+
+// parseBatteryLog reads capacity, session and open session records,
+// skipping bad lines.
+func parseBatteryLog(r io.Reader) (map[string][]BatteryCapacitySample, map[string][]ChargeSession, map[string]ChargeSession) {
+	days := make(map[string][]BatteryCapacitySample)
+	sessions := make(map[string][]ChargeSession)
+	open := make(map[string]ChargeSession)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rec, err := csv.NewReader(strings.NewReader(line)).Read()
+		if err != nil || len(rec) < 2 {
+			continue
+		}
+		name := rec[1]
+		switch {
+		case rec[0] == "capacity" && len(rec) == 5:
+			capacity, err1 := strconv.ParseFloat(rec[3], 64)
+			cycles, err2 := strconv.Atoi(rec[4])
+			if _, err3 := time.Parse(time.DateOnly, rec[2]); err1 != nil || err2 != nil || err3 != nil {
+				continue
+			}
+			days[name] = append(days[name], BatteryCapacitySample{Date: rec[2], Capacity: capacity, Cycles: cycles})
+		case (rec[0] == "session" || rec[0] == "open") && len(rec) == 7:
+			start, err1 := strconv.ParseInt(rec[3], 10, 64)
+			end, err2 := strconv.ParseInt(rec[4], 10, 64)
+			from, err3 := strconv.ParseFloat(rec[5], 64)
+			to, err4 := strconv.ParseFloat(rec[6], 64)
+			if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+				continue
+			}
+			s := ChargeSession{Kind: rec[2], Start: time.Unix(start, 0), End: time.Unix(end, 0), From: from, To: to}
+			if rec[0] == "open" {
+				open[name] = s
+			} else {
+				sessions[name] = append(sessions[name], s)
+			}
+		}
+	}
+	return days, sessions, open
+}
+
+// save rewrites the log with the retained records.
+func (t *batteryTracker) save() error {
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return err
+	}
+	tmp := t.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	fmt.Fprintln(bw, batteryLogHeader)
+	w := csv.NewWriter(bw)
+	for _, name := range slices.Sorted(maps.Keys(t.days)) {
+		for _, d := range t.days[name] {
+			_ = w.Write([]string{"capacity", name, d.Date, formatHistoryValue(d.Capacity), strconv.Itoa(d.Cycles)})
+		}
+	}
+	writeSession := func(kind, name string, s ChargeSession) {
+		_ = w.Write([]string{kind, name, s.Kind, strconv.FormatInt(s.Start.Unix(), 10),
+			strconv.FormatInt(s.End.Unix(), 10), formatHistoryValue(s.From), formatHistoryValue(s.To)})
+	}
+	for _, name := range slices.Sorted(maps.Keys(t.sessions)) {
+		for _, s := range t.sessions[name] {
+			writeSession("session", name, s)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(t.open)) {
+		writeSession("open", name, *t.open[name])
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.path)
+}
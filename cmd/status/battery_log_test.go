@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func discharging(percent, energy, power float64) []BatteryStatus {
+	return []BatteryStatus{{Name: "BAT0", Percent: percent, Status: "Discharging",
+		EnergyNow: energy, EnergyFull: 49, EnergyDesign: 57, Power: power, CycleCount: 312}}
+}
+
+func TestBatteryTrackerSmoothsDischargeRate(t *testing.T) {
+	tr := newBatteryTracker("")
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	batts := discharging(60, 30, 10)
+	_ = tr.Annotate(batts, start)
+	if batts[0].DischargeRate != 10 || batts[0].TimeLeft != "3:00" {
+		t.Errorf("first sample = rate %v, left %q; want 10 W, 3:00", batts[0].DischargeRate, batts[0].TimeLeft)
+	}
+
+	// A one-off spike moves the average by a fraction of the step.
+	batts = discharging(60, 30, 40)
+	_ = tr.Annotate(batts, start.Add(30*time.Second))
+	if rate := batts[0].DischargeRate; rate <= 10 || rate >= 15 {
+		t.Errorf("rate after a 30s spike to 40 W = %v, want a small rise", rate)
+	}
+
+	// Without power_now the rate comes from the energy drop: 1 Wh in 6 minutes.
+	tr = newBatteryTracker("")
+	_ = tr.Annotate(discharging(60, 30, 0), start)
+	_ = tr.Annotate(discharging(60, 30, 0), start.Add(3*time.Minute))
+	batts = discharging(58, 29, 0)
+	_ = tr.Annotate(batts, start.Add(6*time.Minute))
+	if math.Abs(batts[0].DischargeRate-10) > 1e-9 || batts[0].TimeLeft != "2:54" {
+		t.Errorf("energy-derived rate = %v, left %q; want 10 W, 2:54", batts[0].DischargeRate, batts[0].TimeLeft)
+	}
+
+	// Plugging in drops the estimate.
+	batts = []BatteryStatus{{Name: "BAT0", Percent: 58, Status: "Charging", EnergyNow: 29, Power: 30}}
+	_ = tr.Annotate(batts, start.Add(7*time.Minute))
+	if batts[0].DischargeRate != 0 || batts[0].TimeLeft != "" {
+		t.Errorf("charging battery = rate %v, left %q", batts[0].DischargeRate, batts[0].TimeLeft)
+	}
+}
+
+func TestBatteryTrackerSessionsAndWearPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status_battery.csv")
+	tr := newBatteryTracker(path)
+	day := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+
+	_ = tr.Annotate(discharging(90, 44, 10), day)
+	_ = tr.Annotate([]BatteryStatus{{Name: "BAT0", Percent: 40, Status: "Charging"}}, day.Add(3*time.Hour))
+	// Unplugged again after a minute: too short to keep.
+	_ = tr.Annotate(discharging(41, 20, 10), day.Add(3*time.Hour+time.Minute))
+	batts := []BatteryStatus{{Name: "BAT0", Percent: 45, Status: "Charging", EnergyFull: 49, EnergyDesign: 57}}
+	if err := tr.Annotate(batts, day.Add(3*time.Hour+2*time.Minute)); err != nil {
+		t.Fatalf("Annotate: %v", err)
+	}
+
+	sessions := batts[0].Sessions
+	if len(sessions) != 2 {
+		t.Fatalf("sessions = %+v, want the drain and an ongoing charge", sessions)
+	}
+	if s := sessions[0]; s.Kind != "discharge" || s.From != 90 || s.To != 40 || s.End.Sub(s.Start) != 3*time.Hour || s.Ongoing {
+		t.Errorf("drain session = %+v", s)
+	}
+	if s := sessions[1]; s.Kind != "charge" || s.From != 45 || !s.Ongoing {
+		t.Errorf("ongoing session = %+v", s)
+	}
+
+	// One capacity sample per day; two weeks later the trend appears.
+	for i := 1; i <= 14; i++ {
+		batts = []BatteryStatus{{Name: "BAT0", Percent: 80, Status: "Full", EnergyFull: 49 - float64(i)*0.1, EnergyDesign: 57}}
+		_ = tr.Annotate(batts, day.AddDate(0, 0, i))
+		_ = tr.Annotate(batts, day.AddDate(0, 0, i).Add(time.Hour))
+	}
+	wear := batts[0].Wear
+	if wear == nil || len(wear.Samples) != 15 || wear.Samples[0].Capacity != 86 || wear.Samples[0].Cycles != 312 {
+		t.Fatalf("wear = %+v", wear)
+	}
+	// 0.1 Wh of 57 per day is about 5.3 points a month.
+	if math.Abs(wear.PerMonth-(-0.1/57*100*30)) > 0.05 {
+		t.Errorf("PerMonth = %v", wear.PerMonth)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.HasPrefix(string(data), batteryLogHeader) {
+		t.Fatalf("log = %q, %v", data, err)
+	}
+	reopened := newBatteryTracker(path)
+	batts = []BatteryStatus{{Name: "BAT0", Percent: 80, Status: "Full", EnergyFull: 47, EnergyDesign: 57}}
+	_ = reopened.Annotate(batts, day.AddDate(0, 0, 14).Add(2*time.Hour))
+	if len(batts[0].Wear.Samples) != 15 || len(batts[0].Sessions) != 2 || batts[0].Sessions[1].Kind != "charge" {
+		t.Errorf("reopened log = wear %d, sessions %+v", len(batts[0].Wear.Samples), batts[0].Sessions)
+	}
+}
+
+func TestBatteryTrackerResumesOpenSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status_battery.csv")
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+
+	tr := newBatteryTracker(path)
+	_ = tr.Annotate(discharging(90, 44, 10), start)
+	_ = tr.Annotate(discharging(80, 40, 10), start.Add(time.Hour))
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Restarted within a few minutes: the drain carries on.
+	tr = newBatteryTracker(path)
+	batts := discharging(78, 39, 10)
+	_ = tr.Annotate(batts, start.Add(time.Hour+5*time.Minute))
+	if s := batts[0].Session; s == nil || !s.Start.Equal(start) || s.From != 90 || s.To != 78 {
+		t.Errorf("resumed session = %+v", s)
+	}
+	_ = tr.Close()
+
+	// Restarted much later: the drain ended at its last reading.
+	tr = newBatteryTracker(path)
+	batts = discharging(50, 25, 10)
+	_ = tr.Annotate(batts, start.Add(5*time.Hour))
+	if s := batts[0].LastSession; s == nil || !s.End.Equal(start.Add(time.Hour+5*time.Minute)) || s.To != 78 {
+		t.Errorf("session closed across a long gap = %+v", s)
+	}
+	if s := batts[0].Session; s == nil || !s.Start.Equal(start.Add(5*time.Hour)) {
+		t.Errorf("new session = %+v", s)
+	}
+}
+
+func TestBatterySnapshotJSONCarriesSummary(t *testing.T) {
+	tr := newBatteryTracker("")
+	day := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+	var batts []BatteryStatus
+	for i := range 30 {
+		batts = []BatteryStatus{{Name: "BAT0", Percent: 80, Status: "Discharging", EnergyFull: 49, EnergyDesign: 57}}
+		_ = tr.Annotate(batts, day.AddDate(0, 0, i))
+	}
+	data, err := json.Marshal(batts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batts[0].Wear.Samples) != 30 || strings.Contains(string(data), `"samples"`) || strings.Contains(string(data), `"sessions"`) {
+		t.Errorf("snapshot JSON should leave the log to the battery screen: %s", data)
+	}
+	for _, want := range []string{`"days_logged":30`, `"latest":{"date":"2026-03-30"`, `"session":{"kind":"discharge"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("snapshot JSON missing %s: %s", want, data)
+		}
+	}
+}
+
+func TestParseBatteryLogSkipsBadLines(t *testing.T) {
+	days, sessions, open := parseBatteryLog(strings.NewReader(batteryLogHeader + `
+capacity,BAT0,2026-03-01,86,312
+capacity,BAT0,yesterday,86,312
+capacity,BAT0,2026-03-02,8
+session,BAT0,charge,1772355600,1772359200,20,80
+session,BAT0,charge,soon,1772359200,20,80
+open,BAT0,discharge,1772359200,1772362800,80,70
+garbage
+`))
+	if len(days["BAT0"]) != 1 || len(sessions["BAT0"]) != 1 || sessions["BAT0"][0].To != 80 || open["BAT0"].To != 70 {
+		t.Errorf("parsed = %+v, %+v, %+v", days, sessions, open)
+	}
+}
+
+func TestBatteryCardAndScreen(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	b := BatteryStatus{Name: "BAT0", Percent: 62, Status: "Discharging", TimeLeft: "3:05", Capacity: 86, CycleCount: 312,
+		EnergyNow: 30.4, EnergyFull: 49, EnergyDesign: 57, Power: 9.8, DischargeRate: 9.9,
+		Wear: &BatteryWear{Samples: []BatteryCapacitySample{{Date: "2026-01-01", Capacity: 88}, {Date: "2026-03-01", Capacity: 86}}, PerMonth: -1.2},
+		Sessions: []ChargeSession{
+			{Kind: "charge", Start: start.Add(-2 * time.Hour), End: start.Add(-time.Hour), From: 20, To: 80},
+			{Kind: "discharge", Start: start.Add(-time.Hour), End: start, From: 80, To: 62, Ongoing: true},
+		}}
+	b.LastSession = &b.Sessions[0]
+
+	card := ansiRe.ReplaceAllString(strings.Join(renderBatteryCard([]BatteryStatus{b}, ThermalStatus{}, MetricsHistory{}).lines, "\n"), "")
+	for _, want := range []string{"Wear   ", "-1.2%/mo", "Discharging · 3:05 · 10W", "Last   Charged 20→80% in 1h 0m"} {
+		if !strings.Contains(card, want) {
+			t.Errorf("card missing %q:\n%s", want, card)
+		}
+	}
+
+	screen := ansiRe.ReplaceAllString(renderBatteryView([]BatteryStatus{b}, 100), "")
+	for _, want := range []string{
+		"62% · Discharging · 3:05 left",
+		"Now 30.4 Wh · Full 49.0 Wh · Design 57.0 Wh · Power 9.8 W · Avg drain 9.9 W",
+		"88.0% → 86.0%  -1.2%/mo",
+		"2026-01-01 to 2026-03-01, 2 days logged",
+		"discharge*",
+		"80% → 62%",
+		"+60%/h",
+	} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("screen missing %q:\n%s", want, screen)
+		}
+	}
+	if strings.Index(screen, "discharge*") > strings.Index(screen, "charge   ") {
+		t.Errorf("sessions should be newest first:\n%s", screen)
+	}
+}
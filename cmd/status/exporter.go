@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	defaultListenAddr      = ":9110"
+	defaultScrapeCacheTTL  = 2 * time.Second
+)
+
+// metricsExporter serves the latest snapshot in OpenMetrics text format.
+// Collection is expensive, so scrapes within ttl of each other share one sample.
+type metricsExporter struct {
+	collect snapshotSource
+	ttl     time.Duration
+	warmup  time.Duration // Gap between priming and the first sample, so rate providers rerun
+	now     func() time.Time
+
+	mu       sync.Mutex
+	primedAt time.Time
+	last     MetricsSnapshot
+	lastErr  error
+	lastAt   time.Time
+	duration time.Duration
+}
+
+func newMetricsExporter(collect snapshotSource, ttl, warmup time.Duration) *metricsExporter {
+	return &metricsExporter{collect: collect, ttl: ttl, warmup: warmup, now: time.Now}
+}
+
+// prime takes the first sample, which has no rate baseline, and discards it.
+func (e *metricsExporter) prime() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.primeLocked()
+}
+
+func (e *metricsExporter) primeLocked() {
+	if !e.primedAt.IsZero() {
+		return
+	}
+	_, _ = e.collect()
+	e.primedAt = time.Now()
+}
+
+// snapshot returns a cached sample, collecting a fresh one once the cache expires.
+func (e *metricsExporter) snapshot() (MetricsSnapshot, time.Duration, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	if !e.lastAt.IsZero() && now.Sub(e.lastAt) < e.ttl {
+		return e.last, e.duration, e.lastErr
+	}
+	e.primeLocked()
+	// A scrape right after priming would find the rate providers fresh and
+	// get zero rates, so it waits out the rest of one refresh.
+	if wait := e.warmup - time.Since(e.primedAt); wait > 0 {
+		time.Sleep(wait)
+	}
+	start := time.Now()
+	e.last, e.lastErr = e.collect()
+	e.duration = time.Since(start)
+	e.lastAt = e.now()
+	return e.last, e.duration, e.lastErr
+}
+
+func (e *metricsExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snapshot, took, err := e.snapshot()
+	w.Header().Set("Content-Type", openMetricsContentType)
+	writeOpenMetrics(w, snapshot, err, took)
+}
+
+// openMetricsWriter emits metric families, writing each family header once.
+type openMetricsWriter struct {
+	w    io.Writer
+	seen map[string]bool
+}
+
+func (o *openMetricsWriter) family(name, typ, unit, help string) {
+	if o.seen[name] {
+		return
+	}
+	o.seen[name] = true
+	fmt.Fprintf(o.w, "# TYPE %s %s\n", name, typ)
+	if unit != "" {
+		fmt.Fprintf(o.w, "# UNIT %s %s\n", name, unit)
+	}
+	fmt.Fprintf(o.w, "# HELP %s %s\n", name, help)
+}
+
+func (o *openMetricsWriter) sample(name string, value float64, labels ...string) {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(labels[i])
+			b.WriteString(`="`)
+			b.WriteString(escapeLabelValue(labels[i+1]))
+			b.WriteByte('"')
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	b.WriteByte('\n')
+	io.WriteString(o.w, b.String()) //nolint:errcheck
+}
+
+func (o *openMetricsWriter) gauge(name, unit, help string, value float64, labels ...string) {
+	o.family(name, "gauge", unit, help)
+	o.sample(name, value, labels...)
+}
+
+func escapeLabelValue(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return strings.ReplaceAll(v, "\n", `\n`)
+}
+
+// writeOpenMetrics renders a snapshot as an OpenMetrics exposition, terminated by "# EOF".
+func writeOpenMetrics(w io.Writer, m MetricsSnapshot, collectErr error, took time.Duration) {
+	o := &openMetricsWriter{w: w, seen: make(map[string]bool)}
+	const mb = 1024 * 1024
+
+	o.gauge("mole_cpu_usage_percent", "percent", "Total CPU usage.", m.CPU.Usage)
+	for i, v := range m.CPU.PerCore {
+		o.gauge("mole_cpu_core_usage_percent", "percent", "Per-core CPU usage.", v, "core", strconv.Itoa(i))
+	}
+	o.gauge("mole_load_average", "", "System load average.", m.CPU.Load1, "period", "1m")
+	o.gauge("mole_load_average", "", "System load average.", m.CPU.Load5, "period", "5m")
+	o.gauge("mole_load_average", "", "System load average.", m.CPU.Load15, "period", "15m")
+
+	o.gauge("mole_memory_used_bytes", "bytes", "Used physical memory.", float64(m.Memory.Used))
+	o.gauge("mole_memory_total_bytes", "bytes", "Total physical memory.", float64(m.Memory.Total))
+	o.gauge("mole_memory_cached_bytes", "bytes", "File cache that can be reclaimed.", float64(m.Memory.Cached))
+	o.gauge("mole_swap_used_bytes", "bytes", "Used swap.", float64(m.Memory.SwapUsed))
+	o.gauge("mole_swap_total_bytes", "bytes", "Total swap.", float64(m.Memory.SwapTotal))
+
+	for _, d := range m.Disks {
+		o.gauge("mole_disk_used_bytes", "bytes", "Used space per mount.", float64(d.Used), "mount", d.Mount, "device", d.Device)
+	}
+	for _, d := range m.Disks {
+		o.gauge("mole_disk_total_bytes", "bytes", "Total space per mount.", float64(d.Total), "mount", d.Mount, "device", d.Device)
+	}
+	for _, d := range m.Disks {
+		if d.InodesTotal > 0 {
+			o.gauge("mole_disk_inodes_used_percent", "percent", "Used inodes per mount.", d.InodesPercent, "mount", d.Mount, "device", d.Device)
+		}
+	}
+	o.gauge("mole_disk_read_bytes_per_second", "bytes_per_second", "Disk read rate across all devices.", m.DiskIO.ReadRate*mb)
+	o.gauge("mole_disk_write_bytes_per_second", "bytes_per_second", "Disk write rate across all devices.", m.DiskIO.WriteRate*mb)
+
+	for _, n := range m.Network {
+		o.gauge("mole_network_receive_bytes_per_second", "bytes_per_second", "Network receive rate per interface.", n.RxRateMBs*mb, "interface", n.Name)
+	}
+	for _, n := range m.Network {
+		o.gauge("mole_network_transmit_bytes_per_second", "bytes_per_second", "Network transmit rate per interface.", n.TxRateMBs*mb, "interface", n.Name)
+	}
+
+	for i, b := range m.Batteries {
+		o.gauge("mole_battery_charge_percent", "percent", "Battery charge level.", b.Percent, "battery", strconv.Itoa(i))
+	}
+	for i, b := range m.Batteries {
+		if b.Capacity > 0 {
+			o.gauge("mole_battery_capacity_percent", "percent", "Battery maximum capacity versus design.", float64(b.Capacity), "battery", strconv.Itoa(i))
+		}
+	}
+	for i, b := range m.Batteries {
+		if b.CycleCount > 0 {
+			o.gauge("mole_battery_cycles", "", "Battery charge cycle count.", float64(b.CycleCount), "battery", strconv.Itoa(i))
+		}
+	}
+
+	if m.Thermal.CPUTemp > 0 {
+		o.gauge("mole_thermal_celsius", "celsius", "Temperature by sensor.", m.Thermal.CPUTemp, "sensor", "cpu")
+	}
+	if m.Thermal.GPUTemp > 0 {
+		o.gauge("mole_thermal_celsius", "celsius", "Temperature by sensor.", m.Thermal.GPUTemp, "sensor", "gpu")
+	}
+	if m.Thermal.FanSpeed > 0 {
+		o.gauge("mole_fan_speed_rpm", "rpm", "Fan speed.", float64(m.Thermal.FanSpeed))
+	}
+	if m.Thermal.SystemPower > 0 {
+		o.gauge("mole_system_power_watts", "watts", "System power draw.", m.Thermal.SystemPower)
+	}
+
+	o.gauge("mole_health_score", "", "Overall system health score from 0 to 100.", float64(m.HealthScore))
+	for _, c := range m.HealthBreakdown {
+		o.gauge("mole_health_penalty", "", "Health score points deducted per component.", c.Penalty, "component", c.Name)
+	}
+
+	collectFailed := 0.0
+	if collectErr != nil {
+		collectFailed = 1
+	}
+	o.gauge("mole_collect_errors", "", "Whether the last collection had partial failures.", collectFailed)
+	o.gauge("mole_collect_duration_seconds", "seconds", "Time spent collecting the last sample.", took.Seconds())
+	if !m.CollectedAt.IsZero() {
+		o.gauge("mole_collect_timestamp_seconds", "seconds", "Unix time of the last sample.", float64(m.CollectedAt.UnixNano())/1e9)
+	}
+
+	io.WriteString(w, "# EOF\n") //nolint:errcheck
+}
+
+// runServe implements "status serve" and returns the process exit code.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := fs.String("listen", defaultListenAddr, "address to serve /metrics on")
+	ttl := fs.Duration("cache-ttl", defaultScrapeCacheTTL, "reuse a sample for scrapes within this window")
+	alertsPath := fs.String("alerts", alertRulesPath(), "alert rules file, checked on every sample")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	named := false
+	fs.Visit(func(f *flag.Flag) { named = named || f.Name == "alerts" })
+	alerts, err := openAlertEngine(*alertsPath, named)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "alert rules: %v\n", err)
+		return 2
+	}
+	if alerts != nil {
+		alerts.errLog = os.Stderr
+	}
+	defer alerts.Close()
+
+	collector := NewCollector()
+	defer collector.Close() //nolint:errcheck
+
+	exporter := newMetricsExporter(alerts.watch(collector.Collect), *ttl, refreshInterval)
+	exporter.prime()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, "Mole status exporter. Metrics are served at /metrics.")
+	})
+
+	server := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving metrics on %s/metrics\n", *listen)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "serve: %v\n", err)
+		return 1
+	}
+	return 0
+}
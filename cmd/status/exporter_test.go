@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteOpenMetrics(t *testing.T) {
+	snapshot := MetricsSnapshot{
+		CollectedAt: time.Unix(1700000000, 0),
+		HealthScore: 87,
+		CPU:         CPUStatus{Usage: 25, PerCore: []float64{20, 30}, Load1: 1.5},
+		Memory:      MemoryStatus{Used: 4 << 30, Total: 16 << 30, SwapUsed: 1 << 20},
+		Disks:       []DiskStatus{{Mount: "/", Device: "/dev/disk\"1", Used: 100, Total: 200, InodesTotal: 10, InodesPercent: 40}},
+		DiskIO:      DiskIOStatus{ReadRate: 2},
+		Network:     []NetworkStatus{{Name: "en0", RxRateMBs: 1, TxRateMBs: 0.5}},
+		Batteries:   []BatteryStatus{{Percent: 80, CycleCount: 120}},
+		Thermal:     ThermalStatus{CPUTemp: 55.5},
+	}
+
+	var buf bytes.Buffer
+	writeOpenMetrics(&buf, snapshot, errors.New("partial"), 250*time.Millisecond)
+	out := buf.String()
+
+	wantLines := []string{
+		"# TYPE mole_cpu_usage_percent gauge",
+		"# UNIT mole_cpu_usage_percent percent",
+		"mole_cpu_usage_percent 25",
+		`mole_cpu_core_usage_percent{core="1"} 30`,
+		`mole_load_average{period="1m"} 1.5`,
+		"mole_memory_total_bytes 1.7179869184e+10",
+		"mole_swap_used_bytes 1.048576e+06",
+		`mole_disk_used_bytes{mount="/",device="/dev/disk\"1"} 100`,
+		`mole_disk_inodes_used_percent{mount="/",device="/dev/disk\"1"} 40`,
+		"mole_disk_read_bytes_per_second 2.097152e+06",
+		`mole_network_receive_bytes_per_second{interface="en0"} 1.048576e+06`,
+		`mole_network_transmit_bytes_per_second{interface="en0"} 524288`,
+		`mole_battery_charge_percent{battery="0"} 80`,
+		`mole_battery_cycles{battery="0"} 120`,
+		`mole_thermal_celsius{sensor="cpu"} 55.5`,
+		"mole_health_score 87",
+		"mole_collect_errors 1",
+		"mole_collect_duration_seconds 0.25",
+		"mole_collect_timestamp_seconds 1.7e+09",
+	}
+	lines := strings.Split(out, "\n")
+	for _, want := range wantLines {
+		found := false
+		for _, line := range lines {
+			if line == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("missing line %q", want)
+		}
+	}
+
+	if strings.Count(out, "# TYPE mole_load_average gauge") != 1 {
+		t.Errorf("family header should be written once")
+	}
+	if !strings.HasSuffix(out, "# EOF\n") {
+		t.Errorf("exposition must end with # EOF")
+	}
+	if strings.Contains(out, "mole_fan_speed_rpm") {
+		t.Errorf("unavailable sensors should be omitted")
+	}
+}
+
+func TestMetricsExporterServesCachedSample(t *testing.T) {
+	calls := 0
+	collect := func() (MetricsSnapshot, error) {
+		calls++
+		return MetricsSnapshot{HealthScore: calls}, nil
+	}
+	exporter := newMetricsExporter(collect, time.Minute, 0)
+	clock := time.Unix(1700000000, 0)
+	exporter.now = func() time.Time { return clock }
+
+	server := httptest.NewServer(exporter)
+	defer server.Close()
+
+	scrape := func() string {
+		t.Helper()
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			t.Fatalf("scrape: %v", err)
+		}
+		defer resp.Body.Close() //nolint:errcheck
+		if ct := resp.Header.Get("Content-Type"); ct != openMetricsContentType {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	// First scrape primes rate counters and collects once more.
+	if body := scrape(); !strings.Contains(body, "mole_health_score 2\n") {
+		t.Fatalf("unexpected first scrape:\n%s", body)
+	}
+	if body := scrape(); !strings.Contains(body, "mole_health_score 2\n") || calls != 2 {
+		t.Errorf("scrape within ttl should reuse the sample (calls=%d)", calls)
+	}
+
+	clock = clock.Add(2 * time.Minute)
+	if body := scrape(); !strings.Contains(body, "mole_health_score 3\n") {
+		t.Errorf("expired cache should trigger a new collection:\n%s", body)
+	}
+}
+
+func TestMetricsExporterFirstScrapeHasRates(t *testing.T) {
+	const warmup = 50 * time.Millisecond
+	// Like the scheduler, rates need a baseline and a rate provider only
+	// reruns once its interval has passed since the last sample.
+	var last time.Time
+	rate := 0.0
+	collect := func() (MetricsSnapshot, error) {
+		now := time.Now()
+		if !last.IsZero() && now.Sub(last) >= warmup {
+			rate = 1.5
+		}
+		if last.IsZero() || now.Sub(last) >= warmup {
+			last = now
+		}
+		return MetricsSnapshot{Network: []NetworkStatus{{Name: "en0", RxRateMBs: rate}}, DiskIO: DiskIOStatus{ReadRate: rate}}, nil
+	}
+	exporter := newMetricsExporter(collect, time.Minute, warmup)
+	exporter.prime()
+
+	m, _, _ := exporter.snapshot()
+	if m.Network[0].RxRateMBs == 0 || m.DiskIO.ReadRate == 0 {
+		t.Errorf("first scrape after priming has zero rates: %+v, %+v", m.Network, m.DiskIO)
+	}
+}
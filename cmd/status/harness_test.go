@@ -0,0 +1,322 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// scriptedCollector is a metricsSource that plays a fixed snapshot sequence
+// and then repeats the last one.
+type scriptedCollector struct {
+	frames      []MetricsSnapshot
+	errs        map[int]error // Error returned with frame i
+	pos         int
+	detail      bool
+	connections bool
+	history     *historyBuffers
+}
+
+func newScriptedCollector(frames []MetricsSnapshot) *scriptedCollector {
+	return &scriptedCollector{frames: frames, history: newHistoryBuffers()}
+}
+
+func (s *scriptedCollector) Collect() (MetricsSnapshot, error) {
+	i := min(s.pos, len(s.frames)-1)
+	s.pos++
+	m := s.frames[i]
+	if !s.detail {
+		m.Processes = nil
+	}
+	if !s.connections {
+		m.Connections = nil
+	}
+	m.History = s.history.Add(m)
+	return m, s.errs[i]
+}
+
+func (s *scriptedCollector) SetProcessDetail(on bool)    { s.detail = on }
+func (s *scriptedCollector) SetConnectionDetail(on bool) { s.connections = on }
+
+var scriptStart = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// baseSnapshot is an idle laptop at step i of a script.
+func baseSnapshot(i int) MetricsSnapshot {
+	m := MetricsSnapshot{
+		CollectedAt: scriptStart.Add(time.Duration(i) * time.Second),
+		Host:        "devbox",
+		Uptime:      "2d 3h",
+		Hardware: HardwareInfo{Model: "MacBook Air", CPUModel: "Apple M2", TotalRAM: "16 GB",
+			DiskSize: "512 GB", OSVersion: "macOS 15.3"},
+		CPU:    CPUStatus{Usage: 8, PerCore: []float64{10, 6, 9, 7}, Load1: 0.8, Load5: 0.7, Load15: 0.6, CoreCount: 4, LogicalCPU: 4},
+		Memory: MemoryStatus{Used: 6 << 30, Total: 16 << 30, UsedPercent: 37.5, SwapTotal: 2 << 30, Cached: 3 << 30, Pressure: "normal"},
+		Disks:  []DiskStatus{{Mount: "/", Device: "disk3s1", Used: 200e9, Total: 500e9, UsedPercent: 40, Fstype: "apfs"}},
+		Network: []NetworkStatus{
+			{Name: "en0", RxRateMBs: 0.4, TxRateMBs: 0.1, IP: "10.0.0.7"},
+		},
+		Batteries: []BatteryStatus{{Percent: 100, Status: "charged", Health: "Normal", CycleCount: 88, Capacity: 97}},
+		Thermal:   ThermalStatus{CPUTemp: 41},
+		TopProcesses: []ProcessInfo{
+			{Name: "WindowServer", CPU: 3.1, Memory: 1.2},
+			{Name: "Code Helper", CPU: 1.4, Memory: 4.8},
+		},
+		Processes: []ProcessDetail{
+			{PID: 1, Name: "launchd", User: "root", RSS: 20 << 20, OpenFiles: -1, Threads: 4},
+			{PID: 310, PPID: 1, Name: "WindowServer", User: "_windowserver", CPU: 3.1, RSS: 200 << 20, OpenFiles: 512, Threads: 20},
+			{PID: 920, PPID: 1, Name: "Code Helper", User: "dev", CPU: 1.4, RSS: 780 << 20, OpenFiles: 64, Threads: 18, Cmdline: "Code Helper --type=renderer"},
+		},
+	}
+	m.NetworkHistory = NetworkHistory{RxHistory: []float64{0.4}, TxHistory: []float64{0.1}}
+	return m
+}
+
+// withHealth scores the snapshot the way the collector does.
+func withHealth(m MetricsSnapshot) MetricsSnapshot {
+	m.HealthScore, m.HealthScoreMsg, m.HealthBreakdown = defaultHealthModel().score(m.CPU, m.Memory, m.Disks, m.DiskIO, m.Thermal)
+	return m
+}
+
+// cpuSpikeScript ramps a build to full load and back.
+func cpuSpikeScript() []MetricsSnapshot {
+	usage := []float64{8, 12, 65, 97, 99, 96, 40, 15}
+	var frames []MetricsSnapshot
+	for i, u := range usage {
+		m := baseSnapshot(i)
+		m.CPU.Usage = u
+		m.CPU.PerCore = []float64{u, u - 5, u * 0.9, u * 0.8}
+		m.CPU.Load1 = u / 20
+		m.Thermal.CPUTemp = 41 + u/3
+		m.TopProcesses = append([]ProcessInfo{{Name: "swift-frontend", CPU: u * 3.5, Memory: 6}}, m.TopProcesses...)
+		frames = append(frames, withHealth(m))
+	}
+	return frames
+}
+
+// diskFillingScript writes 8 GB a step until the boot disk is nearly full.
+func diskFillingScript() []MetricsSnapshot {
+	var frames []MetricsSnapshot
+	for i := range 8 {
+		m := baseSnapshot(i)
+		d := &m.Disks[0]
+		d.Used = uint64(440e9 + float64(i)*8e9)
+		d.UsedPercent = float64(d.Used) / float64(d.Total) * 100
+		m.DiskIO = DiskIOStatus{WriteRate: 410, ReadRate: 3}
+		m.TopIO = []ProcessIO{{PID: 4242, Name: "docker", WriteRate: 400}}
+		frames = append(frames, withHealth(m))
+	}
+	return frames
+}
+
+// batteryDrainingScript unplugs the laptop and runs it down.
+func batteryDrainingScript() []MetricsSnapshot {
+	var frames []MetricsSnapshot
+	for i := range 8 {
+		m := baseSnapshot(i)
+		percent := 100 - float64(i)*13
+		m.Batteries[0] = BatteryStatus{Percent: percent, Status: "discharging",
+			TimeLeft: fmt.Sprintf("%d:%02d", int(percent)/20, int(percent)*3%60), Health: "Normal", CycleCount: 88, Capacity: 97}
+		m.Thermal.BatteryPower = 14.5
+		m.Thermal.SystemPower = 14.5
+		frames = append(frames, withHealth(m))
+	}
+	return frames
+}
+
+// tuiHarness drives a model the way Bubble Tea would, without a terminal.
+// Timer commands are not run; collect stands in for a refresh tick.
+type tuiHarness struct {
+	t *testing.T
+	m model
+}
+
+func newTUIHarness(t *testing.T, source metricsSource, width, height int) *tuiHarness {
+	t.Helper()
+	h := &tuiHarness{t: t, m: newModel(source, nil, nil)}
+	h.m.catHidden = true // The cat animates; keep snapshots stable.
+	h.send(tea.WindowSizeMsg{Width: width, Height: height})
+	return h
+}
+
+func (h *tuiHarness) send(msg tea.Msg) tea.Cmd {
+	h.t.Helper()
+	next, cmd := h.m.Update(msg)
+	h.m = next.(model)
+	return cmd
+}
+
+// collect runs n refresh cycles: tick, collect, deliver the metrics.
+func (h *tuiHarness) collect(n int) {
+	h.t.Helper()
+	for range n {
+		cmd := h.send(tickMsg{})
+		if cmd == nil {
+			h.t.Fatal("tick did not start a collection")
+		}
+		msg, ok := cmd().(metricsMsg)
+		if !ok {
+			h.t.Fatal("collection did not return metrics")
+		}
+		h.send(msg)
+	}
+}
+
+func (h *tuiHarness) press(keys ...string) {
+	h.t.Helper()
+	for _, k := range keys {
+		h.send(keyRunes(k))
+	}
+}
+
+// Widths cover the stacked layout (≤80 columns) and the two-column one.
+var harnessWidths = []int{72, 80, 120}
+
+func TestDashboardSnapshots(t *testing.T) {
+	scripts := []struct {
+		name   string
+		frames func() []MetricsSnapshot
+		steps  int
+	}{
+		{"cpu_spike", cpuSpikeScript, 5},
+		{"disk_filling", diskFillingScript, 8},
+		{"battery_draining", batteryDrainingScript, 8},
+	}
+	for _, sc := range scripts {
+		for _, width := range harnessWidths {
+			t.Run(fmt.Sprintf("%s_%d", sc.name, width), func(t *testing.T) {
+				h := newTUIHarness(t, newScriptedCollector(sc.frames()), width, 40)
+				h.collect(sc.steps)
+				checkGolden(t, fmt.Sprintf("dashboard_%s_%d.txt", sc.name, width), h.m.View())
+			})
+		}
+	}
+}
+
+func TestDashboardShowsCollectionError(t *testing.T) {
+	source := newScriptedCollector(cpuSpikeScript())
+	source.errs = map[int]error{1: fmt.Errorf("disks: timeout")}
+	h := newTUIHarness(t, source, 100, 40)
+
+	h.collect(2)
+	if view := h.m.View(); !strings.Contains(view, "ERROR: disks: timeout") {
+		t.Errorf("error missing from header:\n%s", view)
+	}
+	h.collect(1)
+	if view := h.m.View(); strings.Contains(view, "ERROR") {
+		t.Errorf("error should clear on the next good snapshot:\n%s", view)
+	}
+}
+
+func TestProcessScreenSnapshot(t *testing.T) {
+	source := newScriptedCollector(cpuSpikeScript())
+	h := newTUIHarness(t, source, 100, 20)
+	h.collect(1)
+	h.press("p")
+	if !source.detail {
+		t.Fatal("opening the process screen should request process detail")
+	}
+	h.collect(1)
+	checkGolden(t, "process_screen_100.txt", h.m.View())
+
+	h.press("p")
+	if source.detail || h.m.showProcs {
+		t.Errorf("closing the process screen should stop detail collection")
+	}
+}
+
+// multiMountSnapshot is a Linux server whose data volume is filling up.
+func multiMountSnapshot() MetricsSnapshot {
+	m := baseSnapshot(0)
+	m.Mounts = []DiskStatus{
+		{Mount: "/srv/data", Device: "/dev/sdb1", Fstype: "xfs", Used: 3600e9, Total: 4000e9, UsedPercent: 90,
+			InodesUsed: 9e6, InodesTotal: 10e6, InodesPercent: 90, WriteRate: 120, GrowthRate: 100e9, FullInDays: 4},
+		{Mount: "/", Device: "/dev/nvme0n1p2", Fstype: "ext4", Used: 200e9, Total: 500e9, UsedPercent: 40,
+			InodesUsed: 2e6, InodesTotal: 30e6, InodesPercent: 6.7, ReadRate: 1.5, GrowthRate: 2e9, FullInDays: 150},
+		{Mount: "/home", Device: "/dev/mapper/home", Fstype: "btrfs", Used: 100e9, Total: 250e9, UsedPercent: 40, GrowthRate: -5e9},
+		{Mount: "/boot/efi", Device: "/dev/nvme0n1p1", Fstype: "vfat", Used: 30e6, Total: 500e6, UsedPercent: 6},
+	}
+	m.Disks = dataVolumes(m.Mounts)
+	return withHealth(m)
+}
+
+func TestDiskScreenSnapshot(t *testing.T) {
+	h := newTUIHarness(t, newScriptedCollector([]MetricsSnapshot{multiMountSnapshot()}), 110, 20)
+	h.collect(1)
+	if card := h.m.View(); !strings.Contains(card, "/srv/data") || strings.Contains(card, "/boot/efi") {
+		t.Errorf("the dashboard card should list only the largest mounts:\n%s", card)
+	}
+
+	h.press("d", "j")
+	checkGolden(t, "disk_screen_110.txt", h.m.View())
+
+	var opened string
+	analyzeCommand = func(mount string) (*exec.Cmd, error) {
+		opened = mount
+		return nil, errors.New("not installed")
+	}
+	t.Cleanup(func() { analyzeCommand = defaultAnalyzeCommand })
+	cmd := h.send(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("enter should open analyze")
+	}
+	h.send(cmd())
+	if opened != "/" || !strings.Contains(h.m.View(), "analyze /: not installed") {
+		t.Errorf("analyze opened %q, view:\n%s", opened, h.m.View())
+	}
+
+	h.press("d")
+	if h.m.showDisks {
+		t.Errorf("d should close the disk screen")
+	}
+}
+
+// networkSnapshot is a workstation on wired and Wi-Fi with a few services.
+func networkSnapshot() MetricsSnapshot {
+	m := baseSnapshot(0)
+	m.Network = []NetworkStatus{
+		{Name: "eno1", RxRateMBs: 12.5, TxRateMBs: 1.2, IP: "192.168.1.20", IPv6: "2001:db8::20", SpeedMbps: 1000, DropsIn: 3},
+		{Name: "wlp3s0", RxRateMBs: 0.2, TxRateMBs: 0.05, IP: "192.168.1.31", ErrorsIn: 14},
+	}
+	m.NetworkHistory.Interfaces = map[string]NetworkHistory{
+		"eno1":   {RxHistory: []float64{1, 4, 9, 12.5}, TxHistory: []float64{0.5, 1, 1, 1.2}},
+		"wlp3s0": {RxHistory: []float64{0.2, 0.2, 0.2, 0.2}, TxHistory: []float64{0, 0, 0.1, 0.05}},
+	}
+	m.NetworkConfig = NetworkConfig{Gateway: "192.168.1.1", GatewayInterface: "eno1", DNS: []string{"192.168.1.1", "1.1.1.1"}}
+	m.Connections = []ConnectionInfo{
+		{PID: 812, Process: "sshd", Proto: "tcp", LocalAddr: "0.0.0.0", LocalPort: 22, Status: "LISTEN"},
+		{PID: 4410, Process: "node", Proto: "tcp6", LocalAddr: "::", LocalPort: 3000, Status: "LISTEN"},
+		{Proto: "udp", LocalAddr: "0.0.0.0", LocalPort: 5353},
+		{PID: 920, Process: "Code Helper", Proto: "tcp", RemoteAddr: "140.82.112.21", RemotePort: 443, Status: "ESTABLISHED"},
+		{PID: 920, Process: "Code Helper", Proto: "tcp", RemoteAddr: "13.107.42.16", RemotePort: 443, Status: "ESTABLISHED"},
+		{PID: 812, Process: "sshd", Proto: "tcp", RemoteAddr: "192.168.1.7", RemotePort: 50412, Status: "ESTABLISHED"},
+	}
+	return withHealth(m)
+}
+
+func TestNetworkScreenSnapshot(t *testing.T) {
+	source := newScriptedCollector([]MetricsSnapshot{networkSnapshot()})
+	h := newTUIHarness(t, source, 120, 30)
+	h.collect(1)
+	dashboard := ansiRe.ReplaceAllString(h.m.View(), "")
+	for _, want := range []string{"wlp3s0 ↓ 0.20 MB/s", "GW 192.168.1.1 (eno1) · DNS 192.168.1.1, 1.1.1.1"} {
+		if !strings.Contains(dashboard, want) {
+			t.Errorf("network card missing %q:\n%s", want, dashboard)
+		}
+	}
+
+	h.press("n")
+	if !source.connections {
+		t.Fatal("opening the network screen should request connections")
+	}
+	h.collect(1)
+	checkGolden(t, "network_screen_120.txt", h.m.View())
+
+	h.press("n")
+	if source.connections || h.m.showNetwork {
+		t.Errorf("closing the network screen should stop collecting connections")
+	}
+}
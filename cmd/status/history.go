@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// historyMetric indexes the numeric series kept in metrics history.
+type historyMetric int
+
+const (
+	histCPU       historyMetric = iota // Total CPU usage, percent
+	histMemory                         // Memory used, percent
+	histSwap                           // Swap used, percent
+	histDiskRead                       // MB/s
+	histDiskWrite                      // MB/s
+	histNetRx                          // MB/s
+	histNetTx                          // MB/s
+	histGPU                            // Usage of the first GPU, percent
+	histBattery                        // Charge of the first battery, percent
+	histMetricCount
+)
+
+// historySample is one downsampled point. Values are averages over the bucket;
+// TopProc is the process with the highest CPU seen during the bucket.
+type historySample struct {
+	Time       time.Time
+	Count      int // Raw samples averaged into this point
+	Values     [histMetricCount]float64
+	TopProc    string
+	TopProcCPU float64
+}
+
+// historyTier keeps points at one resolution for a bounded time span.
+type historyTier struct {
+	name      string
+	step      time.Duration
+	retention time.Duration
+
+	samples  []historySample
+	pending  historySample // Bucket being filled; Values hold sums until flushed
+	appended int           // Lines appended to the file since the last rewrite
+}
+
+// defaultHistoryTiers are the retention tiers of the on-disk store.
+func defaultHistoryTiers() []*historyTier {
+	return []*historyTier{
+		{name: "1s", step: time.Second, retention: time.Hour},
+		{name: "1m", step: time.Minute, retention: 7 * 24 * time.Hour},
+		{name: "1h", step: time.Hour, retention: 90 * 24 * time.Hour},
+	}
+}
+
+const historyFileHeader = "# mole status history v1"
+
+// historyStore records snapshots into tiered CSV files under the mole cache dir.
+type historyStore struct {
+	mu     sync.Mutex
+	dir    string
+	tiers  []*historyTier
+	latest time.Time // Newest recorded sample; retention is measured from here
+}
+
+// historyDir returns ~/.cache/mole/status_history.
+func historyDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cache", "mole", "status_history"), nil
+}
+
+// historyEnabled reports whether MO_STATUS_HISTORY asks for recording.
+func historyEnabled() bool {
+	switch strings.ToLower(os.Getenv("MO_STATUS_HISTORY")) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// openHistoryStore loads existing tiers from dir, dropping expired points.
+func openHistoryStore(dir string, now time.Time) (*historyStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	s := &historyStore{dir: dir, tiers: defaultHistoryTiers()}
+	for _, tier := range s.tiers {
+		samples, err := readHistoryFile(s.tierPath(tier))
+		if err != nil && !os.IsNotExist(err) {
+			// A corrupt tier is not worth failing the dashboard over.
+			_ = os.Rename(s.tierPath(tier), s.tierPath(tier)+".corrupt")
+		}
+		tier.samples = samples
+		tier.trim(now)
+
+		// Resume a bucket that was still open when the last session exited.
+		if n := len(tier.samples); n > 0 && tier.samples[n-1].Time.Equal(now.Truncate(tier.step)) {
+			last := tier.samples[n-1]
+			tier.samples = tier.samples[:n-1]
+			tier.pending = last
+			for i := range last.Values {
+				tier.pending.Values[i] = last.Values[i] * float64(last.Count)
+			}
+		}
+		if err := s.rewriteTier(tier); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *historyStore) tierPath(tier *historyTier) string {
+	return filepath.Join(s.dir, "history_"+tier.name+".csv")
+}
+
+// sampleFromSnapshot extracts the recorded series from a snapshot.
+func sampleFromSnapshot(m MetricsSnapshot) historySample {
+	sample := historySample{Time: m.CollectedAt, Count: 1}
+	sample.Values[histCPU] = m.CPU.Usage
+	sample.Values[histMemory] = m.Memory.UsedPercent
+	if m.Memory.SwapTotal > 0 {
+		sample.Values[histSwap] = float64(m.Memory.SwapUsed) / float64(m.Memory.SwapTotal) * 100
+	}
+	sample.Values[histDiskRead] = m.DiskIO.ReadRate
+	sample.Values[histDiskWrite] = m.DiskIO.WriteRate
+	for _, n := range m.Network {
+		sample.Values[histNetRx] += n.RxRateMBs
+		sample.Values[histNetTx] += n.TxRateMBs
+	}
+	if len(m.GPU) > 0 && m.GPU[0].Usage >= 0 {
+		sample.Values[histGPU] = m.GPU[0].Usage
+	}
+	if len(m.Batteries) > 0 {
+		sample.Values[histBattery] = m.Batteries[0].Percent
+	}
+	if len(m.TopProcesses) > 0 {
+		sample.TopProc = m.TopProcesses[0].Name
+		sample.TopProcCPU = m.TopProcesses[0].CPU
+	}
+	return sample
+}
+
+// Record folds a raw sample into every tier, persisting buckets as they close.
+func (s *historyStore) Record(sample historySample) error {
+	if s == nil || sample.Time.IsZero() {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sample.Time.After(s.latest) {
+		s.latest = sample.Time
+	}
+	var firstErr error
+	for _, tier := range s.tiers {
+		bucket := sample.Time.Truncate(tier.step)
+		if tier.pending.Count > 0 && !bucket.Equal(tier.pending.Time) {
+			if err := s.flushLocked(tier, sample.Time); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		if tier.pending.Count == 0 {
+			tier.pending = historySample{Time: bucket}
+		}
+		tier.pending.Count++
+		for i, v := range sample.Values {
+			tier.pending.Values[i] += v
+		}
+		if sample.TopProc != "" && sample.TopProcCPU >= tier.pending.TopProcCPU {
+			tier.pending.TopProc = sample.TopProc
+			tier.pending.TopProcCPU = sample.TopProcCPU
+		}
+	}
+	return firstErr
+}
+
+// Close flushes open buckets so a restart can resume them.
+func (s *historyStore) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var firstErr error
+	for _, tier := range s.tiers {
+		if tier.pending.Count == 0 {
+			continue
+		}
+		if err := s.flushLocked(tier, s.latest); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (s *historyStore) flushLocked(tier *historyTier, now time.Time) error {
+	point := tier.pending.average()
+	tier.pending = historySample{}
+	tier.samples = append(tier.samples, point)
+	tier.trim(now)
+
+	// Rewrite once the file holds twice the retained points, otherwise append.
+	if tier.appended >= len(tier.samples) {
+		return s.rewriteTier(tier)
+	}
+	f, err := os.OpenFile(s.tierPath(tier), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	_ = w.Write(point.record())
+	w.Flush()
+	tier.appended++
+	if err := w.Error(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rewriteTier replaces the tier file with the retained points.
+func (s *historyStore) rewriteTier(tier *historyTier) error {
+	path := s.tierPath(tier)
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	fmt.Fprintln(bw, historyFileHeader)
+	w := csv.NewWriter(bw)
+	for _, sample := range tier.samples {
+		_ = w.Write(sample.record())
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	tier.appended = 0
+	return os.Rename(tmp, path)
+}
+
+func (t *historyTier) trim(now time.Time) {
+	cutoff := now.Add(-t.retention)
+	i := sort.Search(len(t.samples), func(i int) bool { return !t.samples[i].Time.Before(cutoff) })
+	if i > 0 {
+		t.samples = append(t.samples[:0], t.samples[i:]...)
+	}
+}
+
+// Window returns points covering the last d from the finest tier that retains that long,
+// including the bucket still being filled.
+func (s *historyStore) Window(d time.Duration, now time.Time) []historySample {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tier := s.tiers[len(s.tiers)-1]
+	for _, t := range s.tiers {
+		if t.retention >= d {
+			tier = t
+			break
+		}
+	}
+	cutoff := now.Add(-d)
+	var out []historySample
+	for _, sample := range tier.samples {
+		if !sample.Time.Before(cutoff) {
+			out = append(out, sample)
+		}
+	}
+	if tier.pending.Count > 0 && !tier.pending.Time.Before(cutoff) {
+		out = append(out, tier.pending.average())
+	}
+	return out
+}
+
+func (h historySample) average() historySample {
+	out := h
+	if h.Count > 0 {
+		for i := range out.Values {
+			out.Values[i] = h.Values[i] / float64(h.Count)
+		}
+	}
+	return out
+}
+
+// Series extracts one metric from samples in time order.
+func historySeries(samples []historySample, metric historyMetric) []float64 {
+	out := make([]float64, len(samples))
+	for i, sample := range samples {
+		out[i] = sample.Values[metric]
+	}
+	return out
+}
+
+// record encodes a point as: unix time, count, values..., top process, top process CPU.
+func (h historySample) record() []string {
+	rec := make([]string, 0, 4+len(h.Values))
+	rec = append(rec, strconv.FormatInt(h.Time.Unix(), 10), strconv.Itoa(h.Count))
+	for _, v := range h.Values {
+		rec = append(rec, formatHistoryValue(v))
+	}
+	return append(rec, h.TopProc, formatHistoryValue(h.TopProcCPU))
+}
+
+func formatHistoryValue(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+func parseHistoryRecord(rec []string) (historySample, error) {
+	var h historySample
+	if len(rec) != 4+int(histMetricCount) {
+		return h, fmt.Errorf("expected %d fields, got %d", 4+int(histMetricCount), len(rec))
+	}
+	unix, err := strconv.ParseInt(rec[0], 10, 64)
+	if err != nil {
+		return h, err
+	}
+	h.Time = time.Unix(unix, 0)
+	if h.Count, err = strconv.Atoi(rec[1]); err != nil {
+		return h, err
+	}
+	for i := range h.Values {
+		if h.Values[i], err = strconv.ParseFloat(rec[2+i], 64); err != nil {
+			return h, err
+		}
+	}
+	h.TopProc = rec[2+len(h.Values)]
+	if h.TopProcCPU, err = strconv.ParseFloat(rec[3+len(h.Values)], 64); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+// readHistoryFile parses a tier file. Later lines for the same time replace
+// earlier ones, since a resumed bucket is appended again when it closes.
+func readHistoryFile(path string) ([]historySample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+	return parseHistory(f)
+}
+
+func parseHistory(r io.Reader) ([]historySample, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+
+	var samples []historySample
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return samples, err
+		}
+		sample, err := parseHistoryRecord(rec)
+		if err != nil {
+			continue // Skip a torn final line from an interrupted write.
+		}
+		if n := len(samples); n > 0 && !sample.Time.After(samples[n-1].Time) {
+			if sample.Time.Equal(samples[n-1].Time) {
+				samples[n-1] = sample
+			}
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func cpuSample(at time.Time, cpu float64, proc string) historySample {
+	s := historySample{Time: at, Count: 1, TopProc: proc, TopProcCPU: cpu}
+	s.Values[histCPU] = cpu
+	return s
+}
+
+func TestHistoryStoreDownsamplesIntoTiers(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+	store, err := openHistoryStore(dir, start)
+	if err != nil {
+		t.Fatalf("openHistoryStore: %v", err)
+	}
+
+	// Two minutes of 1s samples: 10% in the first minute, 30% in the second.
+	for i := range 120 {
+		cpu := 10.0
+		if i >= 60 {
+			cpu = 30
+		}
+		if err := store.Record(cpuSample(start.Add(time.Duration(i)*time.Second), cpu, "backupd")); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	now := start.Add(2 * time.Minute)
+
+	minutes := store.Window(24*time.Hour, now)
+	if len(minutes) != 2 {
+		t.Fatalf("expected 2 minute points, got %d", len(minutes))
+	}
+	if minutes[0].Values[histCPU] != 10 || minutes[1].Values[histCPU] != 30 {
+		t.Errorf("minute averages = %v, %v", minutes[0].Values[histCPU], minutes[1].Values[histCPU])
+	}
+	if minutes[0].Count != 60 {
+		t.Errorf("minute point Count = %d, want 60", minutes[0].Count)
+	}
+
+	seconds := store.Window(time.Minute, now)
+	if len(seconds) != 60 {
+		t.Errorf("expected 60 second points in the last minute, got %d", len(seconds))
+	}
+
+	hours := store.Window(30*24*time.Hour, now)
+	if len(hours) != 1 || hours[0].Values[histCPU] != 20 {
+		t.Errorf("hour tier = %+v", hours)
+	}
+}
+
+func TestHistoryStorePersistsAndResumes(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+	store, err := openHistoryStore(dir, start)
+	if err != nil {
+		t.Fatalf("openHistoryStore: %v", err)
+	}
+	for i := range 30 {
+		_ = store.Record(cpuSample(start.Add(time.Duration(i)*time.Second), 40, "spotlight"))
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Reopen within the same minute: the open bucket continues instead of duplicating.
+	reopened, err := openHistoryStore(dir, start.Add(40*time.Second))
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	for i := 40; i < 70; i++ {
+		_ = reopened.Record(cpuSample(start.Add(time.Duration(i)*time.Second), 20, "spotlight"))
+	}
+
+	minutes := reopened.Window(24*time.Hour, start.Add(70*time.Second))
+	if len(minutes) != 2 {
+		t.Fatalf("expected 2 minute points, got %d: %+v", len(minutes), minutes)
+	}
+	// 30 samples at 40% and 20 at 20% in the first minute.
+	if minutes[0].Count != 50 || minutes[0].Values[histCPU] != 32 {
+		t.Errorf("resumed bucket = count %d, cpu %v", minutes[0].Count, minutes[0].Values[histCPU])
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "history_1m.csv"))
+	if err != nil {
+		t.Fatalf("read tier file: %v", err)
+	}
+	if !strings.HasPrefix(string(data), historyFileHeader) {
+		t.Errorf("tier file missing header:\n%s", data)
+	}
+}
+
+func TestHistoryStoreDropsExpiredPoints(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store, err := openHistoryStore(dir, old)
+	if err != nil {
+		t.Fatalf("openHistoryStore: %v", err)
+	}
+	_ = store.Record(cpuSample(old, 50, "a"))
+	_ = store.Record(cpuSample(old.Add(time.Hour), 50, "a"))
+	_ = store.Close()
+
+	later := old.Add(10 * 24 * time.Hour)
+	reopened, err := openHistoryStore(dir, later)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if got := reopened.Window(7*24*time.Hour, later); len(got) != 0 {
+		t.Errorf("minute tier should have expired, got %d points", len(got))
+	}
+	if got := reopened.Window(90*24*time.Hour, later); len(got) != 2 {
+		t.Errorf("hour tier should keep 90 days, got %d points", len(got))
+	}
+}
+
+func TestParseHistorySkipsTornLinesAndDuplicates(t *testing.T) {
+	input := historyFileHeader + "\n" +
+		"100,1,1,2,3,4,5,6,7,8,9,proc,1\n" +
+		"100,2,5,2,3,4,5,6,7,8,9,proc,1\n" +
+		"160,1,1,2,3\n"
+	samples, err := parseHistory(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseHistory: %v", err)
+	}
+	if len(samples) != 1 {
+		t.Fatalf("expected 1 sample, got %d", len(samples))
+	}
+	if samples[0].Count != 2 || samples[0].Values[histCPU] != 5 {
+		t.Errorf("later duplicate should win: %+v", samples[0])
+	}
+}
+
+func TestTopProcessByHour(t *testing.T) {
+	base := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+	samples := []historySample{
+		cpuSample(base, 20, "mds"),
+		cpuSample(base.Add(10*time.Minute), 90, "backupd"),
+		cpuSample(base.Add(time.Hour), 15, "Safari"),
+	}
+	got := topProcessByHour(samples, 5)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 hours, got %d", len(got))
+	}
+	if got[0].TopProc != "Safari" || got[1].TopProc != "backupd" {
+		t.Errorf("unexpected hogs: %+v", got)
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	got := downsample([]float64{1, 3, 5, 7}, 2)
+	if len(got) != 2 || got[0] != 2 || got[1] != 6 {
+		t.Errorf("downsample = %v, want [2 6]", got)
+	}
+	if got := downsample([]float64{1, 2}, 5); len(got) != 2 {
+		t.Errorf("short series should pass through, got %v", got)
+	}
+}
+
+func TestRenderHistoryView(t *testing.T) {
+	if got := renderHistoryView(nil, false, 100); !strings.Contains(got, "MO_STATUS_HISTORY=1") {
+		t.Errorf("disabled history should explain how to enable it:\n%s", got)
+	}
+
+	base := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+	samples := []historySample{cpuSample(base, 20, "mds"), cpuSample(base.Add(time.Minute), 80, "backupd")}
+	got := renderHistoryView(samples, true, 100)
+	for _, want := range []string{"CPU", "Memory", "max  80.0%", "Top CPU by hour", "backupd"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("history view missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Battery") {
+		t.Errorf("battery row should be hidden when there is no battery data")
+	}
+}
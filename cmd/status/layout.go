@@ -0,0 +1,133 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/tw93/mole/internal/prefs"
+)
+
+// The dashboard layout is the status.layout key of the shared preferences
+// (see internal/prefs):
+//
+//	"layout": {"cards": [
+//	  {"id": "network", "column": "left", "variant": "expanded"},
+//	  {"id": "cpu", "column": "right"},
+//	  {"id": "processes", "hidden": true},
+//	  {"id": "gpu"}
+//	]}
+//
+// Listed cards come first, in the order given; the rest keep the default
+// order. column is left or right, and an empty one fills the shorter column.
+// variant is compact (the first lines only) or expanded (extra detail).
+// Cards added with RegisterCard use their lowercased title as id.
+// Hiding a card or changing its variant on the dashboard saves the layout.
+
+const compactCardLines = 2
+
+// cardIDs is the default card order.
+var cardIDs = []string{"cpu", "memory", "disk", "battery", "processes", "network", "docker", "gpu", "bluetooth"}
+
+// hiddenByDefault lists cards that repeat the header unless asked for.
+var hiddenByDefault = []string{"gpu", "bluetooth"}
+
+type cardLayout = prefs.Card
+
+type dashboardLayout prefs.Layout
+
+// entry returns a card's placement, falling back to the defaults.
+func (l dashboardLayout) entry(id string) cardLayout {
+	for _, c := range l.Cards {
+		if c.ID == id {
+			return c
+		}
+	}
+	return cardLayout{ID: id, Hidden: slices.Contains(hiddenByDefault, id)}
+}
+
+// update changes a card's placement. The first change lists every default
+// card, so appending an entry never moves it ahead of the others.
+func (l *dashboardLayout) update(id string, change func(*cardLayout)) {
+	l.listDefaults()
+	for i := range l.Cards {
+		if l.Cards[i].ID == id {
+			change(&l.Cards[i])
+			return
+		}
+	}
+	c := l.entry(id)
+	change(&c)
+	l.Cards = append(l.Cards, c)
+}
+
+// listDefaults appends the default cards missing from the layout, in their
+// current order.
+func (l *dashboardLayout) listDefaults() {
+	l.Cards = slices.Clone(l.Cards) // Earlier copies of the model share the slice
+	for _, id := range cardIDs {
+		if !slices.ContainsFunc(l.Cards, func(c cardLayout) bool { return c.ID == id }) {
+			l.Cards = append(l.Cards, l.entry(id))
+		}
+	}
+}
+
+// unhideAll shows every card, including the ones hidden by default.
+func (l *dashboardLayout) unhideAll() {
+	l.listDefaults()
+	for i := range l.Cards {
+		l.Cards[i].Hidden = false
+	}
+}
+
+// rank orders listed cards first, then the default order, then extensions.
+func (l dashboardLayout) rank(id string) int {
+	for i, c := range l.Cards {
+		if c.ID == id {
+			return i
+		}
+	}
+	if i := slices.Index(cardIDs, id); i >= 0 {
+		return len(l.Cards) + i
+	}
+	return len(l.Cards) + len(cardIDs)
+}
+
+// arrange orders the cards, drops hidden ones and applies their variant.
+func (l dashboardLayout) arrange(cards []cardData) []cardData {
+	out := make([]cardData, 0, len(cards))
+	for _, c := range cards {
+		place := l.entry(c.id)
+		if place.Hidden {
+			continue
+		}
+		c.column = place.Column
+		switch place.Variant {
+		case "compact":
+			c.lines = c.lines[:min(len(c.lines), compactCardLines)]
+		case "expanded":
+			c = c.expanded()
+		}
+		out = append(out, c)
+	}
+	slices.SortStableFunc(out, func(a, b cardData) int { return l.rank(a.id) - l.rank(b.id) })
+	return out
+}
+
+// expanded returns the card with its extra detail lines.
+func (c cardData) expanded() cardData {
+	if len(c.more) > 0 {
+		c.lines = append(slices.Clone(c.lines), c.more...)
+		c.more = nil
+	}
+	return c
+}
+
+// nextVariant cycles normal, compact and expanded.
+func nextVariant(v string) string {
+	switch v {
+	case "":
+		return "compact"
+	case "compact":
+		return "expanded"
+	}
+	return ""
+}
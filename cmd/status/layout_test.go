@@ -0,0 +1,166 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/tw93/mole/internal/prefs"
+)
+
+// keyType builds the message for a named non-rune key.
+func keyType(name string) tea.KeyMsg {
+	types := map[string]tea.KeyType{"tab": tea.KeyTab, "shift+tab": tea.KeyShiftTab, "esc": tea.KeyEsc}
+	return tea.KeyMsg{Type: types[name]}
+}
+
+func cardIDsOf(cards []cardData) []string {
+	ids := make([]string, len(cards))
+	for i, c := range cards {
+		ids[i] = c.id
+	}
+	return ids
+}
+
+func testCards() []cardData {
+	var cards []cardData
+	for _, id := range append(slices.Clone(cardIDs), "ci") {
+		cards = append(cards, cardData{id: id, title: id, lines: []string{"one", "two", "three"}, more: []string{"four"}})
+	}
+	return cards
+}
+
+func TestArrangeCards(t *testing.T) {
+	// The default layout keeps today's order and leaves out gpu and bluetooth.
+	got := cardIDsOf(dashboardLayout{}.arrange(testCards()))
+	want := []string{"cpu", "memory", "disk", "battery", "processes", "network", "docker", "ci"}
+	if !slices.Equal(got, want) {
+		t.Errorf("default arrange = %v, want %v", got, want)
+	}
+
+	l := dashboardLayout{Cards: []cardLayout{
+		{ID: "network", Column: "left", Variant: "expanded"},
+		{ID: "gpu", Variant: "compact"},
+		{ID: "processes", Hidden: true},
+	}}
+	cards := l.arrange(testCards())
+	got = cardIDsOf(cards)
+	want = []string{"network", "gpu", "cpu", "memory", "disk", "battery", "docker", "ci"}
+	if !slices.Equal(got, want) {
+		t.Errorf("arrange = %v, want %v", got, want)
+	}
+	if cards[0].column != "left" || len(cards[0].lines) != 4 || cards[0].more != nil {
+		t.Errorf("expanded card = %+v", cards[0])
+	}
+	if len(cards[1].lines) != compactCardLines {
+		t.Errorf("compact card = %+v", cards[1])
+	}
+}
+
+func TestLayoutUpdateKeepsOrder(t *testing.T) {
+	var l dashboardLayout
+	l.update("network", func(c *cardLayout) { c.Variant = nextVariant(c.Variant) })
+	l.update("memory", func(c *cardLayout) { c.Hidden = true })
+	got := cardIDsOf(l.arrange(testCards()))
+	want := []string{"cpu", "disk", "battery", "processes", "network", "docker", "ci"}
+	if !slices.Equal(got, want) {
+		t.Errorf("after editing, order = %v, want %v", got, want)
+	}
+	if l.entry("network").Variant != "compact" || !l.entry("gpu").Hidden {
+		t.Errorf("entries = %+v", l.Cards)
+	}
+
+	l.unhideAll()
+	if got := l.arrange(testCards()); len(got) != len(testCards()) {
+		t.Errorf("unhide all should show every card, got %v", cardIDsOf(got))
+	}
+}
+
+func TestRenderTwoColumnsPlacement(t *testing.T) {
+	card := func(id, column string) cardData {
+		return cardData{id: id, title: strings.ToUpper(id), lines: []string{id + " body"}, column: column}
+	}
+	out := ansiRe.ReplaceAllString(renderTwoColumns([]cardData{
+		card("aaa", "right"), card("bbb", "right"), card("ccc", ""),
+	}, 100), "")
+	lines := strings.Split(out, "\n")
+	// ccc fills the empty left column beside aaa; bbb stays on the right.
+	if !strings.HasPrefix(lines[0], " CCC") || !strings.Contains(lines[0], "AAA") {
+		t.Errorf("first row = %q", lines[0])
+	}
+	last := lines[len(lines)-2]
+	if !strings.HasPrefix(strings.TrimLeft(last, " "), "BBB") || strings.Index(last, "BBB") < 40 {
+		t.Errorf("a right-only card should stay in the right column: %q", last)
+	}
+}
+
+func TestDashboardCardKeys(t *testing.T) {
+	h := newTUIHarness(t, newScriptedCollector(cpuSpikeScript()), 120, 60)
+	h.collect(1)
+
+	h.send(keyType("tab"))
+	if h.m.focus != "cpu" || !strings.Contains(h.m.View(), "esc done") {
+		t.Fatalf("tab should focus the first card, got %q", h.m.focus)
+	}
+	h.send(keyType("tab"))
+	h.send(keyType("shift+tab"))
+	h.send(keyType("tab"))
+	if h.m.focus != "memory" {
+		t.Errorf("focus after tab, tab, shift+tab, tab = %q", h.m.focus)
+	}
+
+	h.press("z")
+	view := h.m.View()
+	if !h.m.zoomed || strings.Contains(view, "CPU") || !strings.Contains(view, "Memory") {
+		t.Errorf("z should show only the focused card:\n%s", view)
+	}
+	h.send(keyType("esc"))
+	if h.m.zoomed || h.m.focus != "memory" {
+		t.Errorf("esc should leave zoom first, zoomed %v focus %q", h.m.zoomed, h.m.focus)
+	}
+
+	h.press("x")
+	if h.m.focus != "disk" || strings.Contains(h.m.View(), "Memory") {
+		t.Errorf("x should hide the card and focus the next, focus %q", h.m.focus)
+	}
+	h.press("v")
+	if h.m.layout.entry("disk").Variant != "compact" {
+		t.Errorf("v should cycle the variant, got %+v", h.m.layout.entry("disk"))
+	}
+	h.press("u")
+	if !strings.Contains(h.m.View(), "Memory") {
+		t.Errorf("u should show hidden cards again")
+	}
+
+	h.send(keyType("esc"))
+	if h.m.focus != "" {
+		t.Errorf("esc should clear the focus")
+	}
+	if cmd := h.send(keyType("esc")); cmd == nil {
+		t.Errorf("esc without focus should still quit")
+	}
+}
+
+func TestDashboardSavesPrefs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mole", "config.json")
+	h := newTUIHarness(t, newScriptedCollector(cpuSpikeScript()), 120, 60)
+	h.m.prefsPath = path
+	h.collect(1)
+
+	h.send(keyType("tab"))
+	h.press("x", "k")
+
+	p, err := prefs.Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if p.Status.CatHidden || !dashboardLayout(p.Status.Layout).entry("cpu").Hidden {
+		t.Errorf("saved prefs = %+v", p.Status)
+	}
+	if h.m.withPrefs(p, path).layout.arrange(testCards())[0].id != "memory" {
+		t.Errorf("restored layout should start at memory")
+	}
+}
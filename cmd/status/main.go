@@ -2,17 +2,23 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
+	"os/signal"
+	"syscall"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/tw93/mole/internal/prefs"
 )
 
-const refreshInterval = time.Second
+// refreshInterval is the refresh_interval preference.
+var refreshInterval = time.Second
 
 var (
 	Version   = "dev"
@@ -27,8 +33,15 @@ type metricsMsg struct {
 	err  error
 }
 
+// metricsSource feeds the dashboard; *Collector is the live one.
+type metricsSource interface {
+	Collect() (MetricsSnapshot, error)
+	SetProcessDetail(on bool)
+	SetConnectionDetail(on bool)
+}
+
 type model struct {
-	collector   *Collector
+	collector   metricsSource // nil while replaying
 	width       int
 	height      int
 	metrics     MetricsSnapshot
@@ -37,78 +50,279 @@ type model struct {
 	lastUpdated time.Time
 	collecting  bool
 	animFrame   int
-	catHidden   bool // true = hidden, false = visible
+	catHidden   bool          // true = hidden, false = visible
+	history     *historyStore // nil unless history recording is on
+	showHistory bool
+	graphWindow int          // Index into graphWindows
+	hint        string       // One-off note under the cards, cleared by the next key
+	alerts      *alertEngine // nil without a rules file
+	showProcs   bool
+	procView    processView
+	showDisks   bool
+	diskView    diskView
+	showNetwork bool
+	netView     networkView
+	showBattery bool
+	layout      dashboardLayout
+	prefsPath   string            // Where runtime changes are saved; empty in tests
+	focus       string            // id of the focused card
+	zoomed      bool              // Show only the focused card, expanded
+	recorder    *snapshotRecorder // nil unless --record
+	replay      replayState       // Playback of --replay; replay.log is nil when live
 }
 
-// getConfigPath returns the path to the status preferences file.
-func getConfigPath() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return ""
-	}
-	return filepath.Join(home, ".config", "mole", "status_prefs")
+// graphWindow is a time span the card graphs can show.
+type graphWindow struct {
+	label    string        // Empty for the live window, whose span follows refresh_interval
+	duration time.Duration // 0 = live collector buffers
+}
+
+// Longer windows read from the history store, so they need --history.
+var graphWindows = []graphWindow{
+	{},
+	{label: "1h", duration: time.Hour},
+	{label: "24h", duration: 24 * time.Hour},
 }
 
-// loadCatHidden loads the cat hidden preference from config file.
-func loadCatHidden() bool {
-	path := getConfigPath()
-	if path == "" {
-		return false
+// Label names the span shown, e.g. "2m" for the live window at the default
+// 1s refresh interval.
+func (w graphWindow) Label() string {
+	if w.label != "" {
+		return w.label
 	}
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return false
+	span := refreshInterval * MetricsHistorySize
+	switch {
+	case span < time.Minute:
+		return fmt.Sprintf("%ds", int(span.Round(time.Second).Seconds()))
+	case span < time.Hour:
+		return fmt.Sprintf("%dm", int(span.Round(time.Minute).Minutes()))
+	default:
+		return fmt.Sprintf("%dh", int(span.Round(time.Hour).Hours()))
 	}
-	return strings.TrimSpace(string(data)) == "cat_hidden=true"
 }
 
-// saveCatHidden saves the cat hidden preference to config file.
-func saveCatHidden(hidden bool) {
-	path := getConfigPath()
-	if path == "" {
-		return
+// graphHistory returns the series for the selected graph window.
+func (m model) graphHistory() MetricsHistory {
+	window := graphWindows[m.graphWindow]
+	if window.duration == 0 || m.history == nil {
+		return m.metrics.History
 	}
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return
+	samples := m.history.Window(window.duration, time.Now())
+	var hist MetricsHistory
+	for i := range hist.Series {
+		hist.Series[i] = historySeries(samples, historyMetric(i))
 	}
-	value := "cat_hidden=false"
-	if hidden {
-		value = "cat_hidden=true"
+	return hist
+}
+
+// loadPrefs reads the shared preferences with their MO_* overrides and
+// applies the ones that are package settings. A file that cannot be parsed
+// falls back to the defaults and a bad override is skipped; the error says
+// which.
+func loadPrefs(path string) (prefs.Prefs, error) {
+	p, err := prefs.Load(path) // The defaults when err is set
+	if err != nil {
+		err = fmt.Errorf("%w; using defaults", err)
 	}
-	_ = os.WriteFile(path, []byte(value+"\n"), 0644)
+	p, envErr := prefs.ApplyEnv(p, os.Getenv)
+	if envErr != nil {
+		err = errors.Join(err, fmt.Errorf("%w; ignored", envErr))
+	}
+	refreshInterval = time.Duration(p.RefreshInterval)
+	byteUnit = uint64(p.ByteUnit())
+	percentWarn, percentDanger = p.Thresholds.WarnPercent, p.Thresholds.DangerPercent
+	tempWarn, tempDanger = p.Thresholds.WarnTemp, p.Thresholds.DangerTemp
+	healthSettings = p.Thresholds.Health
+	applyTheme(p.Theme)
+	return p, err
+}
+
+// withPrefs restores the dashboard state saved in the preferences.
+func (m model) withPrefs(p prefs.Prefs, path string) model {
+	m.catHidden = p.Status.CatHidden
+	m.layout = dashboardLayout(p.Status.Layout)
+	m.prefsPath = path
+	return m
+}
+
+// savePrefs records a dashboard change in the preferences file. Saving is
+// best effort; the dashboard keeps the change either way.
+func (m model) savePrefs(change func(*prefs.Prefs)) {
+	_ = prefs.Update(m.prefsPath, change)
+}
+
+// saveLayout stores the current card layout.
+func (m model) saveLayout() {
+	layout := prefs.Layout(m.layout)
+	m.savePrefs(func(p *prefs.Prefs) { p.Status.Layout = layout })
 }
 
-func newModel() model {
+func newModel(collector metricsSource, history *historyStore, alerts *alertEngine) model {
 	return model{
-		collector: NewCollector(),
-		catHidden: loadCatHidden(),
+		collector: collector,
+		history:   history,
+		alerts:    alerts,
 	}
 }
 
 func (m model) Init() tea.Cmd {
+	if m.replay.log != nil {
+		return tea.Batch(m.replay.tick(), animTick())
+	}
 	return tea.Batch(tickAfter(0), animTick())
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.replay.log != nil && !m.showProcs && !m.showDisks && !m.showNetwork && !m.showBattery {
+		if next, cmd, handled := m.updateReplay(msg); handled {
+			return next, cmd
+		}
+	}
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.showProcs {
+			key := msg.String()
+			quit := key == "ctrl+c" || (key == "q" && !m.procView.filtering && m.procView.confirm == nil)
+			if quit {
+				return m, tea.Quit
+			}
+			open, cmd := m.procView.handleKey(msg, m.metrics.Processes, processPageSize(m.height))
+			if !open {
+				m.showProcs = false
+				m.setProcessDetail(false)
+			}
+			return m, cmd
+		}
+		if m.showDisks {
+			if key := msg.String(); key == "q" || key == "ctrl+c" {
+				return m, tea.Quit
+			}
+			open, cmd := m.diskView.handleKey(msg, m.metrics.allMounts())
+			m.showDisks = open
+			return m, cmd
+		}
+		if m.showNetwork {
+			if key := msg.String(); key == "q" || key == "ctrl+c" {
+				return m, tea.Quit
+			}
+			if !m.netView.handleKey(msg, networkPageSize(m.height)) {
+				m.showNetwork = false
+				m.setConnectionDetail(false)
+			}
+			return m, nil
+		}
+		if m.showBattery {
+			switch msg.String() {
+			case "q", "ctrl+c":
+				return m, tea.Quit
+			case "esc", "b":
+				m.showBattery = false
+			}
+			return m, nil
+		}
+		m.hint = ""
 		switch msg.String() {
-		case "q", "esc", "ctrl+c":
+		case "q", "ctrl+c":
 			return m, tea.Quit
+		case "esc":
+			switch {
+			case m.zoomed:
+				m.zoomed = false
+			case m.focus != "":
+				m.focus = ""
+			default:
+				return m, tea.Quit
+			}
+			return m, nil
+		case "tab":
+			m.focus = m.nextFocus(1)
+			return m, nil
+		case "shift+tab":
+			m.focus = m.nextFocus(-1)
+			return m, nil
+		case "z", "enter":
+			if m.focus == "" {
+				m.focus = m.nextFocus(1)
+			}
+			m.zoomed = !m.zoomed && m.focus != ""
+			return m, nil
+		case "x":
+			if m.focus != "" {
+				hidden := m.focus
+				m.focus = m.nextFocus(1)
+				if m.focus == hidden {
+					m.focus = ""
+				}
+				m.zoomed = false
+				m.layout.update(hidden, func(c *cardLayout) { c.Hidden = true })
+				m.saveLayout()
+			}
+			return m, nil
+		case "v":
+			if m.focus != "" {
+				m.layout.update(m.focus, func(c *cardLayout) { c.Variant = nextVariant(c.Variant) })
+				m.saveLayout()
+			}
+			return m, nil
+		case "u":
+			m.layout.unhideAll()
+			m.saveLayout()
+			return m, nil
 		case "k":
 			// Toggle cat visibility and persist preference
 			m.catHidden = !m.catHidden
-			saveCatHidden(m.catHidden)
+			hidden := m.catHidden
+			m.savePrefs(func(p *prefs.Prefs) { p.Status.CatHidden = hidden })
+			return m, nil
+		case "h":
+			m.showHistory = !m.showHistory
+			return m, nil
+		case "p":
+			m.showProcs = true
+			m.showHistory = false
+			m.setProcessDetail(true)
+			return m, nil
+		case "d":
+			m.showDisks = true
+			m.showHistory = false
+			return m, nil
+		case "n":
+			m.showNetwork = true
+			m.showHistory = false
+			m.setConnectionDetail(true)
+			return m, nil
+		case "b":
+			m.showBattery = true
+			m.showHistory = false
+			return m, nil
+		case "w":
+			if m.history == nil {
+				m.hint = "Longer graph windows need --history"
+				return m, nil
+			}
+			m.graphWindow = (m.graphWindow + 1) % len(graphWindows)
 			return m, nil
 		}
+	case processSignalMsg:
+		if msg.err != nil {
+			m.procView.status = fmt.Sprintf("%s %d failed: %v", msg.signal.verb(), msg.signal.pid, msg.err)
+		} else {
+			m.procView.status = fmt.Sprintf("Sent %s to %d (%s)", msg.signal.verb(), msg.signal.pid, msg.signal.name)
+		}
+		return m, nil
+	case analyzeDoneMsg:
+		if msg.err != nil {
+			m.diskView.status = fmt.Sprintf("analyze %s: %v", msg.mount, msg.err)
+		} else {
+			m.diskView.status = ""
+		}
+		return m, nil
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		return m, nil
 	case tickMsg:
-		if m.collecting {
+		if m.collecting || m.collector == nil {
 			return m, nil
 		}
 		m.collecting = true
@@ -134,17 +348,98 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// dashboardCards builds the visible cards in layout order.
+func (m model) dashboardCards() []cardData {
+	cards := m.layout.arrange(buildCards(m.metrics, m.graphHistory(), graphWindows[m.graphWindow], m.cardWidth()))
+	for i := range cards {
+		cards[i].focused = cards[i].id == m.focus
+	}
+	return cards
+}
+
+// focusHint lists the card keys while a card is focused.
+func (m model) focusHint() string {
+	switch {
+	case m.focus != "":
+		return "\n\n" + subtleStyle.Render("tab next · z zoom · v variant · x hide · u unhide all · esc done")
+	case m.hint != "":
+		return "\n\n" + subtleStyle.Render(m.hint)
+	}
+	return ""
+}
+
+// cardWidth is the width of a card in two columns, 0 in one column.
+func (m model) cardWidth() int {
+	if m.width > 80 {
+		return maxInt(24, m.width/2-4)
+	}
+	return 0
+}
+
+// nextFocus returns the card step places after the focused one, wrapping.
+func (m model) nextFocus(step int) string {
+	cards := m.dashboardCards()
+	if len(cards) == 0 {
+		return ""
+	}
+	current := -1
+	for i, c := range cards {
+		if c.id == m.focus {
+			current = i
+		}
+	}
+	if current < 0 {
+		if step < 0 {
+			return cards[len(cards)-1].id
+		}
+		return cards[0].id
+	}
+	return cards[(current+step+len(cards))%len(cards)].id
+}
+
 func (m model) View() string {
 	if !m.ready {
 		return "Loading..."
 	}
 
+	if m.showProcs {
+		return renderProcessView(m.metrics.Processes, m.procView, m.width, m.height)
+	}
+
+	if m.showNetwork {
+		return renderNetworkView(m.metrics, m.netView, m.width, m.height)
+	}
+
+	if m.showBattery {
+		return renderBatteryView(m.metrics.Batteries, m.width)
+	}
+
+	if m.showDisks {
+		return renderDiskView(m.metrics.allMounts(), m.diskView, m.width)
+	}
+
+	if m.showHistory {
+		samples := m.history.Window(historyViewWindow, time.Now())
+		return renderHistoryView(samples, m.history != nil, m.width)
+	}
+
 	header := renderHeader(m.metrics, m.errMessage, m.animFrame, m.width, m.catHidden)
-	cardWidth := 0
-	if m.width > 80 {
-		cardWidth = maxInt(24, m.width/2-4)
+	if active, sinkErr := m.alerts.Active(); len(active) > 0 || sinkErr != nil {
+		header += "\n" + renderAlertBanner(active, sinkErr, m.width)
+	}
+	if m.replay.log != nil {
+		header += "\n" + renderReplayBar(m.replay)
+	}
+	cards := m.dashboardCards()
+	if m.zoomed {
+		for _, c := range cards {
+			if c.id == m.focus {
+				c.focused = false
+				return header + "\n\n" + renderCard(c.expanded(), max(m.width-2, colWidth), 0) +
+					"\n\n" + subtleStyle.Render("z/esc back · v variant · x hide")
+			}
+		}
 	}
-	cards := buildCards(m.metrics, cardWidth)
 
 	if m.width <= 80 {
 		var rendered []string
@@ -152,17 +447,17 @@ func (m model) View() string {
 			if i > 0 {
 				rendered = append(rendered, "")
 			}
-			rendered = append(rendered, renderCard(c, cardWidth, 0))
+			rendered = append(rendered, renderCard(c, m.cardWidth(), 0))
 		}
 		result := header + "\n" + lipgloss.JoinVertical(lipgloss.Left, rendered...)
 		// Add extra newline if cat is hidden for better spacing
 		if m.catHidden {
 			result = header + "\n\n" + lipgloss.JoinVertical(lipgloss.Left, rendered...)
 		}
-		return result
+		return result + m.focusHint()
 	}
 
-	twoCol := renderTwoColumns(cards, m.width)
+	twoCol := renderTwoColumns(cards, m.width) + m.focusHint()
 	// Add extra newline if cat is hidden for better spacing
 	if m.catHidden {
 		return header + "\n\n" + twoCol
@@ -173,10 +468,29 @@ func (m model) View() string {
 func (m model) collectCmd() tea.Cmd {
 	return func() tea.Msg {
 		data, err := m.collector.Collect()
+		_ = m.history.Record(sampleFromSnapshot(data)) // History is best effort
+		if recErr := m.recorder.Record(data, err); recErr != nil && err == nil {
+			err = fmt.Errorf("record: %w", recErr)
+		}
+		m.alerts.Observe(data)
 		return metricsMsg{data: data, err: err}
 	}
 }
 
+// setProcessDetail asks the collector for full process detail; replays have none.
+func (m model) setProcessDetail(on bool) {
+	if m.collector != nil {
+		m.collector.SetProcessDetail(on)
+	}
+}
+
+// setConnectionDetail asks the collector for every socket; replays have none.
+func (m model) setConnectionDetail(on bool) {
+	if m.collector != nil {
+		m.collector.SetConnectionDetail(on)
+	}
+}
+
 func tickAfter(delay time.Duration) tea.Cmd {
 	return tea.Tick(delay, func(time.Time) tea.Msg { return tickMsg{} })
 }
@@ -191,10 +505,130 @@ func animTickWithSpeed(cpuUsage float64) tea.Cmd {
 	return tea.Tick(time.Duration(interval)*time.Millisecond, func(time.Time) tea.Msg { return animTickMsg{} })
 }
 
+func flagPassed(name string) bool {
+	passed := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
+	return passed
+}
+
 func main() {
-	p := tea.NewProgram(newModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(prefs.RunCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	// Preferences come first: the refresh interval is a flag default, and
+	// the health thresholds apply to the exporter too.
+	settings, prefsErr := loadPrefs(prefs.Path())
+	if prefsErr != nil {
+		fmt.Fprintf(os.Stderr, "config: %v (see mole config check)\n", prefsErr)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		os.Exit(runServe(os.Args[2:]))
+	}
+
+	jsonOut := flag.Bool("json", false, "print one metrics snapshot as JSON and exit")
+	stream := flag.Bool("stream", false, "print a JSON metrics snapshot per line until interrupted")
+	interval := flag.Duration("interval", refreshInterval, "sampling interval for --stream")
+	recordHistory := flag.Bool("history", historyEnabled(), "record metrics history to the mole cache dir")
+	alertsPath := flag.String("alerts", alertRulesPath(), "alert rules file")
+	recordPath := flag.String("record", "", "append every dashboard snapshot to this file")
+	replayPath := flag.String("replay", "", "play back a file written by --record or --stream")
+	replaySpeed := flag.String("speed", "1x", "playback speed for --replay, e.g. 4x")
+	flag.StringVar(&probeTargetsFile, "probes", probeTargetsFile, "latency probe targets file")
+	flag.StringVar(&dockerHost, "docker-host", "", "Docker or Podman API endpoint (default $DOCKER_HOST or the standard sockets)")
+	flag.Parse()
+
+	if *replayPath != "" {
+		os.Exit(runReplay(*replayPath, *replaySpeed, settings))
+	}
+
+	// The default rules file is optional; one named with --alerts is not.
+	alerts, err := openAlertEngine(*alertsPath, flagPassed("alerts"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "alert rules: %v\n", err)
+		os.Exit(2)
+	}
+
+	if *jsonOut || *stream {
+		collector := NewCollector()
+		if *stream {
+			if *interval <= 0 {
+				fmt.Fprintln(os.Stderr, "--interval must be positive")
+				os.Exit(2)
+			}
+			if alerts != nil {
+				alerts.errLog = os.Stderr
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			err = streamSnapshotsJSON(ctx, os.Stdout, alerts.watch(collector.Collect), *interval)
+			stop()
+			alerts.Close()
+		} else {
+			err = writeSnapshotJSON(os.Stdout, collector.Collect, refreshInterval)
+		}
+		_ = collector.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "system status error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var history *historyStore
+	if *recordHistory {
+		if dir, err := historyDir(); err == nil {
+			history, err = openHistoryStore(dir, time.Now())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "history disabled: %v\n", err)
+			}
+		}
+	}
+
+	var recorder *snapshotRecorder
+	if *recordPath != "" {
+		var err error
+		if recorder, err = openSnapshotRecorder(*recordPath); err != nil {
+			fmt.Fprintf(os.Stderr, "record: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	collector := NewCollector()
+	m := newModel(collector, history, alerts).withPrefs(settings, prefs.Path())
+	m.recorder = recorder
+	if prefsErr != nil {
+		m.hint = "Config ignored, using defaults: run mole config check"
+	}
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	_, err = p.Run()
+	_ = collector.Close()
+	_ = history.Close()
+	_ = recorder.Close()
+	alerts.Close()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "system status error: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+func runReplay(path, speedFlag string, settings prefs.Prefs) int {
+	speed, err := parseReplaySpeed(speedFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		return 2
+	}
+	log, err := loadReplay(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		return 2
+	}
+	if _, err := tea.NewProgram(newReplayModel(log, speed).withPrefs(settings, prefs.Path()), tea.WithAltScreen()).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "system status error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -2,13 +2,11 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"os/exec"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/shirou/gopsutil/v4/disk"
-	"github.com/shirou/gopsutil/v4/host"
 	"github.com/shirou/gopsutil/v4/net"
 )
 
@@ -57,281 +55,364 @@ func (rb *RingBuffer) Slice() []float64 {
 }
 
 type MetricsSnapshot struct {
-	CollectedAt    time.Time
-	Host           string
-	Platform       string
-	Uptime         string
-	Procs          uint64
-	Hardware       HardwareInfo
-	HealthScore    int    // 0-100 system health score
-	HealthScoreMsg string // Brief explanation
-
-	CPU            CPUStatus
-	GPU            []GPUStatus
-	Memory         MemoryStatus
-	Disks          []DiskStatus
-	DiskIO         DiskIOStatus
-	Network        []NetworkStatus
-	NetworkHistory NetworkHistory
-	Proxy          ProxyStatus
-	Batteries      []BatteryStatus
-	Thermal        ThermalStatus
-	Sensors        []SensorReading
-	Bluetooth      []BluetoothDevice
-	TopProcesses   []ProcessInfo
+	CollectedAt    time.Time    `json:"collected_at"`
+	Host           string       `json:"host"`
+	Platform       string       `json:"platform"`
+	Uptime         string       `json:"uptime"`
+	Procs          uint64       `json:"procs"`
+	Hardware       HardwareInfo `json:"hardware"`
+	HealthScore    int          `json:"health_score"`     // 0-100 system health score
+	HealthScoreMsg string       `json:"health_score_msg"` // Brief explanation
+
+	HealthBreakdown []HealthComponent `json:"health_breakdown"`
+
+	CPU            CPUStatus         `json:"cpu"`
+	GPU            []GPUStatus       `json:"gpu"`
+	Memory         MemoryStatus      `json:"memory"`
+	Disks          []DiskStatus      `json:"disks"` // Data volumes; see dataVolumes
+	DiskIO         DiskIOStatus      `json:"disk_io"`
+	Network        []NetworkStatus   `json:"network"`
+	NetworkHistory NetworkHistory    `json:"network_history"`
+	Proxy          ProxyStatus       `json:"proxy"`
+	NetworkConfig  NetworkConfig     `json:"network_config"`
+	Probes         []ProbeResult     `json:"probes"` // TCP and DNS latency probes
+	Batteries      []BatteryStatus   `json:"batteries"`
+	Thermal        ThermalStatus     `json:"thermal"`
+	Sensors        []SensorReading   `json:"sensors"`
+	Bluetooth      []BluetoothDevice `json:"bluetooth"`
+	TopProcesses   []ProcessInfo     `json:"top_processes"`
+	TopIO          []ProcessIO       `json:"top_io"`    // Busiest disk readers and writers
+	TopNet         []ProcessNet      `json:"top_net"`   // Busiest network users; TCP only on Linux
+	Container      *ContainerStatus  `json:"container"` // nil outside containers and WSL
+	Docker         *DockerStatus     `json:"docker"`    // nil without a reachable Docker or Podman engine
+
+	// Mounts is every mount for the disk screen, tiny and pseudo ones included.
+	Mounts []DiskStatus `json:"-"`
+
+	// Processes is every process, filled only while the process screen is open.
+	Processes []ProcessDetail `json:"-"`
+
+	// Connections is every listening and established socket, filled only
+	// while the network screen is open.
+	Connections []ConnectionInfo `json:"-"`
+
+	// History is for the dashboard graphs only; use --stream to export time series.
+	History MetricsHistory `json:"-"`
+
+	// Extensions holds values from registered providers, keyed by provider name.
+	Extensions map[string]any `json:"extensions,omitempty"`
 }
 
 type HardwareInfo struct {
-	Model       string // MacBook Pro 14-inch, 2021
-	CPUModel    string // Apple M1 Pro / Intel Core i7
-	TotalRAM    string // 16GB
-	DiskSize    string // 512GB
-	OSVersion   string // macOS Sonoma 14.5
-	RefreshRate string // 120Hz / 60Hz
+	Model       string `json:"model"`        // MacBook Pro 14-inch, 2021
+	CPUModel    string `json:"cpu_model"`    // Apple M1 Pro / Intel Core i7
+	TotalRAM    string `json:"total_ram"`    // 16GB
+	DiskSize    string `json:"disk_size"`    // 512GB
+	OSVersion   string `json:"os_version"`   // macOS Sonoma 14.5
+	RefreshRate string `json:"refresh_rate"` // 120Hz / 60Hz
 }
 
 type DiskIOStatus struct {
-	ReadRate  float64 // MB/s
-	WriteRate float64 // MB/s
+	ReadRate  float64 `json:"read_rate_mbs"`  // MB/s
+	WriteRate float64 `json:"write_rate_mbs"` // MB/s
 }
 
 type ProcessInfo struct {
-	Name   string
-	CPU    float64
-	Memory float64
+	Name   string  `json:"name"`
+	CPU    float64 `json:"cpu"`
+	Memory float64 `json:"memory"`
 }
 
 type CPUStatus struct {
-	Usage            float64
-	PerCore          []float64
-	PerCoreEstimated bool
-	Load1            float64
-	Load5            float64
-	Load15           float64
-	CoreCount        int
-	LogicalCPU       int
-	PCoreCount       int // Performance cores (Apple Silicon)
-	ECoreCount       int // Efficiency cores (Apple Silicon)
+	Usage            float64   `json:"usage"`
+	PerCore          []float64 `json:"per_core"`
+	PerCoreEstimated bool      `json:"per_core_estimated"`
+	Load1            float64   `json:"load1"`
+	Load5            float64   `json:"load5"`
+	Load15           float64   `json:"load15"`
+	CoreCount        int       `json:"core_count"`
+	LogicalCPU       int       `json:"logical_cpu"`
+	PCoreCount       int       `json:"p_core_count"` // Performance cores (Apple Silicon)
+	ECoreCount       int       `json:"e_core_count"` // Efficiency cores (Apple Silicon)
 }
 
 type GPUStatus struct {
-	Name        string
-	Usage       float64
-	MemoryUsed  float64
-	MemoryTotal float64
-	CoreCount   int
-	Note        string
+	Name        string  `json:"name"`
+	Usage       float64 `json:"usage"`
+	MemoryUsed  float64 `json:"memory_used"`
+	MemoryTotal float64 `json:"memory_total"`
+	CoreCount   int     `json:"core_count"`
+	Note        string  `json:"note"`
 }
 
 type MemoryStatus struct {
-	Used        uint64
-	Total       uint64
-	UsedPercent float64
-	SwapUsed    uint64
-	SwapTotal   uint64
-	Cached      uint64 // File cache that can be freed if needed
-	Pressure    string // macOS memory pressure: normal/warn/critical
+	Used        uint64  `json:"used"`
+	Total       uint64  `json:"total"`
+	UsedPercent float64 `json:"used_percent"`
+	SwapUsed    uint64  `json:"swap_used"`
+	SwapTotal   uint64  `json:"swap_total"`
+	Cached      uint64  `json:"cached"`   // File cache that can be freed if needed
+	Pressure    string  `json:"pressure"` // macOS memory pressure: normal/warn/critical
 }
 
 type DiskStatus struct {
-	Mount       string
-	Device      string
-	Used        uint64
-	Total       uint64
-	UsedPercent float64
-	Fstype      string
-	External    bool
+	Mount       string  `json:"mount"`
+	Device      string  `json:"device"`
+	Used        uint64  `json:"used"`
+	Total       uint64  `json:"total"`
+	UsedPercent float64 `json:"used_percent"`
+	Fstype      string  `json:"fstype"`
+	External    bool    `json:"external"`
+
+	InodesUsed    uint64  `json:"inodes_used"`
+	InodesTotal   uint64  `json:"inodes_total"` // 0 on filesystems without fixed inodes (APFS, btrfs)
+	InodesPercent float64 `json:"inodes_used_percent"`
+	ReadRate      float64 `json:"read_rate_mbs"`          // MB/s on this mount's device
+	WriteRate     float64 `json:"write_rate_mbs"`         // MB/s on this mount's device
+	GrowthRate    float64 `json:"growth_bytes_per_day"`   // Used-bytes trend over the last hour
+	FullInDays    float64 `json:"full_in_days,omitempty"` // Projected from GrowthRate; 0 when not filling
 }
 
 type NetworkStatus struct {
-	Name      string
-	RxRateMBs float64
-	TxRateMBs float64
-	IP        string
+	Name      string  `json:"name"`
+	RxRateMBs float64 `json:"rx_rate_mbs"`
+	TxRateMBs float64 `json:"tx_rate_mbs"`
+	IP        string  `json:"ip"`
+	IPv6      string  `json:"ipv6"`       // First global address; link-local ones are skipped
+	SpeedMbps int     `json:"speed_mbps"` // 0 when unknown
+	ErrorsIn  uint64  `json:"errors_in"`  // Counters since boot
+	ErrorsOut uint64  `json:"errors_out"`
+	DropsIn   uint64  `json:"drops_in"`
+	DropsOut  uint64  `json:"drops_out"`
 }
 
 // NetworkHistory holds the global network usage history.
 type NetworkHistory struct {
-	RxHistory []float64
-	TxHistory []float64
+	RxHistory []float64 `json:"rx_history"`
+	TxHistory []float64 `json:"tx_history"`
+
+	// Interfaces holds the same history per interface. Network screen only;
+	// the latest per-interface rates are already in Network.
+	Interfaces map[string]NetworkHistory `json:"-"`
 }
 
 const NetworkHistorySize = 120 // Increased history size for wider graph
 
+// MetricsHistory holds recent values of every graphed metric, oldest first.
+type MetricsHistory struct {
+	Series  [histMetricCount][]float64
+	PerCore [][]float64 // Live window only
+}
+
+const MetricsHistorySize = NetworkHistorySize
+
+// NetworkConfig is the default route and resolver configuration.
+type NetworkConfig struct {
+	Gateway          string   `json:"gateway"`
+	GatewayInterface string   `json:"gateway_interface"`
+	Gateway6         string   `json:"gateway6"` // Linux only
+	DNS              []string `json:"dns"`
+	SearchDomains    []string `json:"search_domains"`
+}
+
 type ProxyStatus struct {
-	Enabled bool
-	Type    string // HTTP, SOCKS, System
-	Host    string
+	Enabled bool   `json:"enabled"`
+	Type    string `json:"type"` // HTTP, SOCKS, System
+	Host    string `json:"host"`
 }
 
 type BatteryStatus struct {
-	Percent    float64
-	Status     string
-	TimeLeft   string
-	Health     string
-	CycleCount int
-	Capacity   int // Maximum capacity percentage (e.g., 85 means 85% of original)
+	Name       string  `json:"name,omitempty"` // Power supply name on Linux, e.g. BAT0
+	Percent    float64 `json:"percent"`
+	Status     string  `json:"status"`
+	TimeLeft   string  `json:"time_left"`
+	Health     string  `json:"health"`
+	CycleCount int     `json:"cycle_count"`
+	Capacity   int     `json:"capacity"` // Maximum capacity percentage (e.g., 85 means 85% of original)
+
+	EnergyNow     float64 `json:"energy_now_wh,omitempty"`
+	EnergyFull    float64 `json:"energy_full_wh,omitempty"`
+	EnergyDesign  float64 `json:"energy_full_design_wh,omitempty"`
+	Power         float64 `json:"power_w,omitempty"`          // Instantaneous charge or discharge power
+	DischargeRate float64 `json:"discharge_rate_w,omitempty"` // Smoothed while discharging; drives TimeLeft
+
+	Wear        *BatteryWear    `json:"wear,omitempty"`         // Capacity trend, from the battery log
+	Session     *ChargeSession  `json:"session,omitempty"`      // The ongoing charge or discharge
+	LastSession *ChargeSession  `json:"last_session,omitempty"` // The latest finished one
+	Sessions    []ChargeSession `json:"-"`                      // Battery screen only: oldest first, the last may be ongoing
+}
+
+// BatteryWear is how full-charge capacity has changed over time.
+type BatteryWear struct {
+	Latest   BatteryCapacitySample   `json:"latest"`
+	Days     int                     `json:"days_logged"`
+	PerMonth float64                 `json:"capacity_change_per_month"` // Percentage points, 0 until there are two weeks of samples
+	Samples  []BatteryCapacitySample `json:"-"`                         // Screens only: one per day, oldest first
+}
+
+type BatteryCapacitySample struct {
+	Date     string  `json:"date"`     // Local day, YYYY-MM-DD
+	Capacity float64 `json:"capacity"` // Percent of design capacity
+	Cycles   int     `json:"cycles"`
+}
+
+// ChargeSession is a stretch of charging or discharging.
+type ChargeSession struct {
+	Kind    string    `json:"kind"` // charge or discharge
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	From    float64   `json:"from_percent"`
+	To      float64   `json:"to_percent"`
+	Ongoing bool      `json:"ongoing,omitempty"`
 }
 
 type ThermalStatus struct {
-	CPUTemp      float64
-	GPUTemp      float64
-	FanSpeed     int
-	FanCount     int
-	SystemPower  float64 // System power consumption in Watts
-	AdapterPower float64 // AC adapter max power in Watts
-	BatteryPower float64 // Battery charge/discharge power in Watts (positive = discharging)
+	CPUTemp      float64 `json:"cpu_temp"`
+	GPUTemp      float64 `json:"gpu_temp"`
+	FanSpeed     int     `json:"fan_speed"`
+	FanCount     int     `json:"fan_count"`
+	SystemPower  float64 `json:"system_power"`  // System power consumption in Watts
+	AdapterPower float64 `json:"adapter_power"` // AC adapter max power in Watts
+	BatteryPower float64 `json:"battery_power"` // Battery charge/discharge power in Watts (positive = discharging)
 }
 
 type SensorReading struct {
-	Label string
-	Value float64
-	Unit  string
-	Note  string
+	Label string  `json:"label"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+	Note  string  `json:"note"`
 }
 
 type BluetoothDevice struct {
-	Name      string
-	Connected bool
-	Battery   string
+	Name      string `json:"name"`
+	Connected bool   `json:"connected"`
+	Battery   string `json:"battery"`
 }
 
 type Collector struct {
-	// Static cache.
-	cachedHW  HardwareInfo
-	lastHWAt  time.Time
-	hasStatic bool
-
-	// Slow cache (30s-1m).
-	lastBTAt time.Time
-	lastBT   []BluetoothDevice
+	scheduler *scheduler
 
-	// Fast metrics (1s).
+	// Rate counters.
 	prevNet      map[string]net.IOCountersStat
 	lastNetAt    time.Time
 	rxHistoryBuf *RingBuffer
 	txHistoryBuf *RingBuffer
-	lastGPUAt    time.Time
-	cachedGPU    []GPUStatus
-	prevDiskIO   disk.IOCountersStat
+	ifaceHistory *interfaceHistory
+	prevDiskIO   map[string]disk.IOCountersStat
 	lastDiskAt   time.Time
+	diskTrend    *diskTrend
+	batteries    *batteryTracker
+
+	history *historyBuffers // Live graph history, 1 sample per collect
+
+	health healthModel
+
+	procs         *processSampler
+	procNet       *processNetSampler
+	processDetail atomic.Bool
+
+	connections      *connectionSampler
+	connectionDetail atomic.Bool
 }
 
 func NewCollector() *Collector {
-	return &Collector{
+	c := &Collector{
 		prevNet:      make(map[string]net.IOCountersStat),
 		rxHistoryBuf: NewRingBuffer(NetworkHistorySize),
 		txHistoryBuf: NewRingBuffer(NetworkHistorySize),
+		ifaceHistory: newInterfaceHistory(),
+		diskTrend:    newDiskTrend(),
+		batteries:    newBatteryTracker(batteryLogPath()),
+	}
+	c.history = newHistoryBuffers()
+	c.health = healthModel{healthSettings}
+	c.procs = newProcessSampler()
+	c.procNet = newProcessNetSampler()
+	c.connections = newConnectionSampler()
+
+	registryMu.Lock()
+	providers := append(c.builtinProviders(), registeredProviders...)
+	registryMu.Unlock()
+	c.scheduler = newScheduler(providers)
+	return c
+}
+
+// SetProcessDetail makes later snapshots carry every process with full detail.
+func (c *Collector) SetProcessDetail(on bool) {
+	c.processDetail.Store(on)
+}
+
+// SetConnectionDetail makes later snapshots carry every socket.
+func (c *Collector) SetConnectionDetail(on bool) {
+	c.connectionDetail.Store(on)
+}
+
+// Close logs what must outlive the run, such as an ongoing battery session.
+func (c *Collector) Close() error {
+	return c.batteries.Close()
+}
+
+// historyBuffers keeps the live graph history, one sample per snapshot.
+type historyBuffers struct {
+	series [histMetricCount]*RingBuffer
+	cores  []*RingBuffer
+}
+
+func newHistoryBuffers() *historyBuffers {
+	h := &historyBuffers{}
+	for i := range h.series {
+		h.series[i] = NewRingBuffer(MetricsHistorySize)
+	}
+	return h
+}
+
+// Add appends the snapshot to the buffers and returns their contents.
+func (h *historyBuffers) Add(m MetricsSnapshot) MetricsHistory {
+	sample := sampleFromSnapshot(m)
+	var hist MetricsHistory
+	for i, buf := range h.series {
+		buf.Add(sample.Values[i])
+		hist.Series[i] = buf.Slice()
+	}
+
+	if !m.CPU.PerCoreEstimated {
+		for len(h.cores) < len(m.CPU.PerCore) {
+			h.cores = append(h.cores, NewRingBuffer(MetricsHistorySize))
+		}
+		hist.PerCore = make([][]float64, len(m.CPU.PerCore))
+		for i, v := range m.CPU.PerCore {
+			h.cores[i].Add(v)
+			hist.PerCore[i] = h.cores[i].Slice()
+		}
 	}
+	return hist
 }
 
+// Collect runs the providers that are due and returns a snapshot of the
+// latest value from every provider.
 func (c *Collector) Collect() (MetricsSnapshot, error) {
 	now := time.Now()
+	updates, mergeErr := c.scheduler.Run(now)
 
-	// Host info is cached by gopsutil; fetch once.
-	hostInfo, _ := host.Info()
-
-	var (
-		wg       sync.WaitGroup
-		errMu    sync.Mutex
-		mergeErr error
-
-		cpuStats     CPUStatus
-		memStats     MemoryStatus
-		diskStats    []DiskStatus
-		diskIO       DiskIOStatus
-		netStats     []NetworkStatus
-		proxyStats   ProxyStatus
-		batteryStats []BatteryStatus
-		thermalStats ThermalStatus
-		sensorStats  []SensorReading
-		gpuStats     []GPUStatus
-		btStats      []BluetoothDevice
-		topProcs     []ProcessInfo
-	)
-
-	// Helper to launch concurrent collection.
-	collect := func(fn func() error) {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if err := fn(); err != nil {
-				errMu.Lock()
-				if mergeErr == nil {
-					mergeErr = err
-				} else {
-					mergeErr = fmt.Errorf("%v; %w", mergeErr, err)
-				}
-				errMu.Unlock()
-			}
-		}()
+	snapshot := MetricsSnapshot{CollectedAt: now}
+	for _, update := range updates {
+		update(&snapshot)
 	}
 
-	// Launch independent collection tasks.
-	collect(func() (err error) { cpuStats, err = collectCPU(); return })
-	collect(func() (err error) { memStats, err = collectMemory(); return })
-	collect(func() (err error) { diskStats, err = collectDisks(); return })
-	collect(func() (err error) { diskIO = c.collectDiskIO(now); return nil })
-	collect(func() (err error) { netStats, err = c.collectNetwork(now); return })
-	collect(func() (err error) { proxyStats = collectProxy(); return nil })
-	collect(func() (err error) { batteryStats, _ = collectBatteries(); return nil })
-	collect(func() (err error) { thermalStats = collectThermal(); return nil })
-	// Sensors disabled - CPU temp already shown in CPU card
-	// collect(func() (err error) { sensorStats, _ = collectSensors(); return nil })
-	collect(func() (err error) { gpuStats, err = c.collectGPU(now); return })
-	collect(func() (err error) {
-		// Bluetooth is slow; cache for 30s.
-		if now.Sub(c.lastBTAt) > 30*time.Second || len(c.lastBT) == 0 {
-			btStats = c.collectBluetooth(now)
-			c.lastBT = btStats
-			c.lastBTAt = now
-		} else {
-			btStats = c.lastBT
-		}
-		return nil
-	})
-	collect(func() (err error) { topProcs = collectTopProcesses(); return nil })
-
-	// Wait for all to complete.
-	wg.Wait()
-
-	// Dependent tasks (post-collect).
-	// Cache hardware info as it's expensive and rarely changes.
-	if !c.hasStatic || now.Sub(c.lastHWAt) > 10*time.Minute {
-		c.cachedHW = collectHardware(memStats.Total, diskStats)
-		c.lastHWAt = now
-		c.hasStatic = true
+	snapshot.TopProcesses = topProcesses(snapshot.Processes, snapshot.Memory.Total, topProcessCount)
+	snapshot.TopIO = topIOProcesses(snapshot.Processes, topIOCount)
+	if !c.processDetail.Load() {
+		snapshot.Processes = nil
+	}
+	if !c.connectionDetail.Load() {
+		snapshot.Connections = nil
 	}
-	hwInfo := c.cachedHW
-
-	score, scoreMsg := calculateHealthScore(cpuStats, memStats, diskStats, diskIO, thermalStats)
-
-	return MetricsSnapshot{
-		CollectedAt:    now,
-		Host:           hostInfo.Hostname,
-		Platform:       fmt.Sprintf("%s %s", hostInfo.Platform, hostInfo.PlatformVersion),
-		Uptime:         formatUptime(hostInfo.Uptime),
-		Procs:          hostInfo.Procs,
-		Hardware:       hwInfo,
-		HealthScore:    score,
-		HealthScoreMsg: scoreMsg,
-		CPU:            cpuStats,
-		GPU:            gpuStats,
-		Memory:         memStats,
-		Disks:          diskStats,
-		DiskIO:         diskIO,
-		Network:        netStats,
-		NetworkHistory: NetworkHistory{
-			RxHistory: c.rxHistoryBuf.Slice(),
-			TxHistory: c.txHistoryBuf.Slice(),
-		},
-		Proxy:        proxyStats,
-		Batteries:    batteryStats,
-		Thermal:      thermalStats,
-		Sensors:      sensorStats,
-		Bluetooth:    btStats,
-		TopProcesses: topProcs,
-	}, mergeErr
+
+	snapshot.HealthScore, snapshot.HealthScoreMsg, snapshot.HealthBreakdown =
+		c.health.score(snapshot.CPU, snapshot.Memory, snapshot.Disks, snapshot.DiskIO, snapshot.Thermal)
+
+	snapshot.History = c.history.Add(snapshot)
+	return snapshot, mergeErr
 }
 
 func runCmd(ctx context.Context, name string, args ...string) (string, error) {
@@ -4,9 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"math"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -19,7 +20,7 @@ var (
 	powerCacheTTL = 30 * time.Second
 )
 
-func collectBatteries() (batts []BatteryStatus, err error) {
+func collectBatteries(ctx context.Context) (batts []BatteryStatus, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			// Swallow panics to keep UI alive.
@@ -29,9 +30,9 @@ func collectBatteries() (batts []BatteryStatus, err error) {
 
 	// macOS: pmset for real-time percentage/status.
 	if runtime.GOOS == "darwin" && commandExists("pmset") {
-		if out, err := runCmd(context.Background(), "pmset", "-g", "batt"); err == nil {
+		if out, err := runCmd(ctx, "pmset", "-g", "batt"); err == nil {
 			// Health/cycles/capacity from cached system_profiler.
-			health, cycles, capacity := getCachedPowerData()
+			health, cycles, capacity := getCachedPowerData(ctx)
 			if batts := parsePMSet(out, health, cycles, capacity); len(batts) > 0 {
 				return batts, nil
 			}
@@ -39,25 +40,7 @@ func collectBatteries() (batts []BatteryStatus, err error) {
 	}
 
 	// Linux: /sys/class/power_supply.
-	matches, _ := filepath.Glob("/sys/class/power_supply/BAT*/capacity")
-	for _, capFile := range matches {
-		statusFile := filepath.Join(filepath.Dir(capFile), "status")
-		capData, err := os.ReadFile(capFile)
-		if err != nil {
-			continue
-		}
-		statusData, _ := os.ReadFile(statusFile)
-		percentStr := strings.TrimSpace(string(capData))
-		percent, _ := strconv.ParseFloat(percentStr, 64)
-		status := strings.TrimSpace(string(statusData))
-		if status == "" {
-			status = "Unknown"
-		}
-		batts = append(batts, BatteryStatus{
-			Percent: percent,
-			Status:  status,
-		})
-	}
+	batts = readLinuxBatteries(hostRoot)
 	if len(batts) > 0 {
 		return batts, nil
 	}
@@ -65,6 +48,62 @@ func collectBatteries() (batts []BatteryStatus, err error) {
 	return nil, errors.New("no battery data found")
 }
 
+// readLinuxBatteries reads every BAT* power supply. Energy comes from the
+// energy_* attributes in µWh, or from charge_* in µAh times the design voltage.
+func readLinuxBatteries(root string) []BatteryStatus {
+	dirs, _ := filepath.Glob(filepath.Join(root, "sys/class/power_supply/BAT*"))
+	slices.Sort(dirs)
+	var batts []BatteryStatus
+	for _, dir := range dirs {
+		attr := func(name string) (float64, bool) {
+			v, ok := readSysInt(filepath.Join(dir, name))
+			return float64(v), ok && v >= 0
+		}
+		percent, ok := attr("capacity")
+		if !ok {
+			continue
+		}
+		b := BatteryStatus{
+			Name:    filepath.Base(dir),
+			Percent: percent,
+			Status:  readTrimmed(filepath.Join(dir, "status")),
+			Health:  readTrimmed(filepath.Join(dir, "health")),
+		}
+		if b.Status == "" {
+			b.Status = "Unknown"
+		}
+		if cycles, ok := attr("cycle_count"); ok {
+			b.CycleCount = int(cycles)
+		}
+
+		volts, ok := attr("voltage_min_design")
+		if !ok {
+			volts, _ = attr("voltage_now")
+		}
+		energy := func(name string) float64 {
+			if uwh, ok := attr("energy_" + name); ok {
+				return uwh / 1e6
+			}
+			if uah, ok := attr("charge_" + name); ok {
+				return uah * volts / 1e12
+			}
+			return 0
+		}
+		b.EnergyNow, b.EnergyFull, b.EnergyDesign = energy("now"), energy("full"), energy("full_design")
+		if uw, ok := attr("power_now"); ok {
+			b.Power = uw / 1e6
+		} else if ua, ok := attr("current_now"); ok {
+			now, _ := attr("voltage_now")
+			b.Power = ua * now / 1e12
+		}
+		if b.EnergyFull > 0 && b.EnergyDesign > 0 {
+			b.Capacity = int(math.Round(b.EnergyFull / b.EnergyDesign * 100))
+		}
+		batts = append(batts, b)
+	}
+	return batts
+}
+
 func parsePMSet(raw string, health string, cycles int, capacity int) []BatteryStatus {
 	var out []BatteryStatus
 	var timeLeft string
@@ -119,8 +158,8 @@ func parsePMSet(raw string, health string, cycles int, capacity int) []BatterySt
 }
 
 // getCachedPowerData returns condition, cycles, and capacity from cached system_profiler.
-func getCachedPowerData() (health string, cycles int, capacity int) {
-	out := getSystemPowerOutput()
+func getCachedPowerData(ctx context.Context) (health string, cycles int, capacity int) {
+	out := getSystemPowerOutput(ctx)
 	if out == "" {
 		return "", 0, 0
 	}
@@ -148,7 +187,7 @@ func getCachedPowerData() (health string, cycles int, capacity int) {
 	return health, cycles, capacity
 }
 
-func getSystemPowerOutput() string {
+func getSystemPowerOutput(ctx context.Context) string {
 	if runtime.GOOS != "darwin" {
 		return ""
 	}
@@ -158,7 +197,7 @@ func getSystemPowerOutput() string {
 		return cachedPower
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	out, err := runCmd(ctx, "system_profiler", "SPPowerDataType")
@@ -169,7 +208,10 @@ func getSystemPowerOutput() string {
 	return cachedPower
 }
 
-func collectThermal() ThermalStatus {
+func collectThermal(ctx context.Context) ThermalStatus {
+	if runtime.GOOS == "linux" {
+		return readLinuxThermal(hostRoot)
+	}
 	if runtime.GOOS != "darwin" {
 		return ThermalStatus{}
 	}
@@ -177,7 +219,7 @@ func collectThermal() ThermalStatus {
 	var thermal ThermalStatus
 
 	// Fan info from cached system_profiler.
-	out := getSystemPowerOutput()
+	out := getSystemPowerOutput(ctx)
 	if out != "" {
 		for line := range strings.Lines(out) {
 			lower := strings.ToLower(line)
@@ -192,7 +234,7 @@ func collectThermal() ThermalStatus {
 	}
 
 	// Power metrics from ioreg (fast, real-time).
-	ctxPower, cancelPower := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	ctxPower, cancelPower := context.WithTimeout(ctx, 500*time.Millisecond)
 	defer cancelPower()
 	if out, err := runCmd(ctxPower, "ioreg", "-rn", "AppleSmartBattery"); err == nil {
 		for line := range strings.Lines(out) {
@@ -268,7 +310,7 @@ func collectThermal() ThermalStatus {
 
 	// Fallback: thermal level proxy.
 	if thermal.CPUTemp == 0 {
-		ctx2, cancel2 := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		ctx2, cancel2 := context.WithTimeout(ctx, 500*time.Millisecond)
 		defer cancel2()
 		out2, err := runCmd(ctx2, "sysctl", "-n", "machdep.xcpm.cpu_thermal_level")
 		if err == nil {
@@ -13,28 +13,14 @@ const (
 	bluetoothctlTimeout = 1500 * time.Millisecond
 )
 
-func (c *Collector) collectBluetooth(now time.Time) []BluetoothDevice {
-	if len(c.lastBT) > 0 && !c.lastBTAt.IsZero() && now.Sub(c.lastBTAt) < bluetoothCacheTTL {
-		return c.lastBT
-	}
-
+func collectBluetooth() []BluetoothDevice {
 	if devs, err := readSystemProfilerBluetooth(); err == nil && len(devs) > 0 {
-		c.lastBTAt = now
-		c.lastBT = devs
 		return devs
 	}
-
 	if devs, err := readBluetoothCTLDevices(); err == nil && len(devs) > 0 {
-		c.lastBTAt = now
-		c.lastBT = devs
 		return devs
 	}
-
-	c.lastBTAt = now
-	if len(c.lastBT) == 0 {
-		c.lastBT = []BluetoothDevice{{Name: "No Bluetooth info", Connected: false}}
-	}
-	return c.lastBT
+	return []BluetoothDevice{{Name: "No Bluetooth info", Connected: false}}
 }
 
 func readSystemProfilerBluetooth() ([]BluetoothDevice, error) {
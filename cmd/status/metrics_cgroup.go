@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bufio"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContainerStatus is usage of the cgroup status runs in, against its limits.
+type ContainerStatus struct {
+	Env           string  `json:"env"`            // docker, podman, kubernetes, devcontainer, lxc or wsl
+	CgroupVersion int     `json:"cgroup_version"` // 0 when no cgroup was read (WSL)
+	CPULimit      float64 `json:"cpu_limit"`      // CPUs; 0 = unlimited
+	CPUUsage      float64 `json:"cpu_usage"`      // Percent of the limit, or of all CPUs when unlimited
+	MemoryUsed    uint64  `json:"memory_used"`    // Excludes reclaimable page cache
+	MemoryLimit   uint64  `json:"memory_limit"`   // 0 = unlimited
+	IOReadRate    float64 `json:"io_read_rate_mbs"`
+	IOWriteRate   float64 `json:"io_write_rate_mbs"`
+}
+
+// MemoryPercent is memory used against the limit, or 0 without one.
+func (c ContainerStatus) MemoryPercent() float64 {
+	if c.MemoryLimit == 0 {
+		return 0
+	}
+	return float64(c.MemoryUsed) / float64(c.MemoryLimit) * 100
+}
+
+// envLabels are the names shown in the header.
+var envLabels = map[string]string{
+	"docker":       "Docker",
+	"podman":       "Podman",
+	"kubernetes":   "Kubernetes",
+	"devcontainer": "Dev Container",
+	"lxc":          "LXC",
+	"wsl":          "WSL",
+}
+
+// detectEnvironment reports the container or VM status runs in, or "".
+func detectEnvironment(root string, getenv func(string) string) string {
+	switch {
+	case getenv("REMOTE_CONTAINERS") == "true" || getenv("CODESPACES") == "true" || getenv("DEVCONTAINER") != "":
+		return "devcontainer"
+	case getenv("KUBERNETES_SERVICE_HOST") != "":
+		return "kubernetes"
+	case fileExists(filepath.Join(root, "run/.containerenv")):
+		return "podman"
+	case fileExists(filepath.Join(root, ".dockerenv")):
+		return "docker"
+	}
+	if env := readTrimmed(filepath.Join(root, "run/systemd/container")); env != "" {
+		if env == "container-other" {
+			return "docker"
+		}
+		return env
+	}
+	cgroup := readTrimmed(filepath.Join(root, "proc/1/cgroup"))
+	switch {
+	case strings.Contains(cgroup, "/docker/") || strings.Contains(cgroup, "docker-"):
+		return "docker"
+	case strings.Contains(cgroup, "/kubepods"):
+		return "kubernetes"
+	case strings.Contains(cgroup, "/lxc/") || strings.Contains(cgroup, "lxc.payload"):
+		return "lxc"
+	}
+	release := strings.ToLower(readTrimmed(filepath.Join(root, "proc/sys/kernel/osrelease")))
+	if strings.Contains(release, "microsoft") || strings.Contains(release, "wsl") {
+		return "wsl"
+	}
+	return ""
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// cgroupCounters is one reading of a cgroup's limits and cumulative counters.
+type cgroupCounters struct {
+	version     int
+	cpuLimit    float64 // CPUs; 0 = unlimited
+	cpuUsage    time.Duration
+	memoryUsed  uint64
+	memoryLimit uint64
+	ioRead      uint64
+	ioWrite     uint64
+}
+
+// Kernels report "no limit" in v1 as a page-rounded MaxInt64.
+const cgroupV1Unlimited = 1 << 62
+
+// readCgroup reads the cgroup of the current process under root.
+func readCgroup(root string) (cgroupCounters, bool) {
+	base := filepath.Join(root, "sys/fs/cgroup")
+	paths := parseProcCgroup(readTrimmed(filepath.Join(root, "proc/self/cgroup")))
+	if fileExists(filepath.Join(base, "cgroup.controllers")) {
+		return readCgroupV2(cgroupDir(base, paths[""])), true
+	}
+	if !fileExists(filepath.Join(base, "memory")) && !fileExists(filepath.Join(base, "cpuacct")) && !fileExists(filepath.Join(base, "cpu,cpuacct")) {
+		return cgroupCounters{}, false
+	}
+	return readCgroupV1(base, paths), true
+}
+
+// parseProcCgroup maps each v1 controller to its path; "" holds the v2 path.
+func parseProcCgroup(data string) map[string]string {
+	paths := make(map[string]string)
+	for line := range strings.Lines(data) {
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[1] == "" {
+			paths[""] = parts[2]
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			paths[controller] = parts[2]
+		}
+	}
+	return paths
+}
+
+// cgroupDir joins a cgroup path to its mount. Inside a cgroup namespace the
+// mount is already the process's cgroup, so a missing directory falls back to it.
+func cgroupDir(mount, path string) string {
+	dir := filepath.Join(mount, path)
+	if fileExists(dir) {
+		return dir
+	}
+	return mount
+}
+
+func readCgroupV2(dir string) cgroupCounters {
+	c := cgroupCounters{version: 2}
+	if fields := strings.Fields(readTrimmed(filepath.Join(dir, "cpu.max"))); len(fields) == 2 && fields[0] != "max" {
+		quota, err1 := strconv.ParseFloat(fields[0], 64)
+		period, err2 := strconv.ParseFloat(fields[1], 64)
+		if err1 == nil && err2 == nil && period > 0 {
+			c.cpuLimit = quota / period
+		}
+	}
+	stat := readKeyValues(filepath.Join(dir, "cpu.stat"))
+	c.cpuUsage = time.Duration(stat["usage_usec"]) * time.Microsecond
+
+	if limit := readTrimmed(filepath.Join(dir, "memory.max")); limit != "max" {
+		c.memoryLimit, _ = strconv.ParseUint(limit, 10, 64)
+	}
+	current, _ := readSysInt(filepath.Join(dir, "memory.current"))
+	c.memoryUsed = subtractFloor(uint64(max(current, 0)), readKeyValues(filepath.Join(dir, "memory.stat"))["inactive_file"])
+
+	for line := range strings.Lines(readTrimmed(filepath.Join(dir, "io.stat"))) {
+		fields := strings.Fields(line) // "8:0 rbytes=1 wbytes=2 rios=3 ..."
+		if len(fields) < 2 {
+			continue
+		}
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, "=")
+			n, _ := strconv.ParseUint(value, 10, 64)
+			switch key {
+			case "rbytes":
+				c.ioRead += n
+			case "wbytes":
+				c.ioWrite += n
+			}
+		}
+	}
+	return c
+}
+
+func readCgroupV1(base string, paths map[string]string) cgroupCounters {
+	c := cgroupCounters{version: 1}
+	cpuDir := cgroupDir(firstExisting(base, "cpu,cpuacct", "cpu"), paths["cpu"])
+	quota, okQuota := readSysInt(filepath.Join(cpuDir, "cpu.cfs_quota_us"))
+	period, okPeriod := readSysInt(filepath.Join(cpuDir, "cpu.cfs_period_us"))
+	if okQuota && okPeriod && quota > 0 && period > 0 {
+		c.cpuLimit = float64(quota) / float64(period)
+	}
+	acctDir := cgroupDir(firstExisting(base, "cpu,cpuacct", "cpuacct"), paths["cpuacct"])
+	if usage, ok := readSysInt(filepath.Join(acctDir, "cpuacct.usage")); ok {
+		c.cpuUsage = time.Duration(usage) // Nanoseconds
+	}
+
+	memDir := cgroupDir(filepath.Join(base, "memory"), paths["memory"])
+	if limit, ok := readSysInt(filepath.Join(memDir, "memory.limit_in_bytes")); ok && limit > 0 && limit < cgroupV1Unlimited {
+		c.memoryLimit = uint64(limit)
+	}
+	usage, _ := readSysInt(filepath.Join(memDir, "memory.usage_in_bytes"))
+	c.memoryUsed = subtractFloor(uint64(max(usage, 0)), readKeyValues(filepath.Join(memDir, "memory.stat"))["total_inactive_file"])
+
+	blkioDir := cgroupDir(filepath.Join(base, "blkio"), paths["blkio"])
+	for line := range strings.Lines(readTrimmed(filepath.Join(blkioDir, "blkio.throttle.io_service_bytes"))) {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue // The "Total" summary line has two fields.
+		}
+		n, _ := strconv.ParseUint(fields[2], 10, 64)
+		switch fields[1] {
+		case "Read":
+			c.ioRead += n
+		case "Write":
+			c.ioWrite += n
+		}
+	}
+	return c
+}
+
+// firstExisting returns the first controller directory that exists under base.
+func firstExisting(base string, names ...string) string {
+	for _, name := range names {
+		if dir := filepath.Join(base, name); fileExists(dir) {
+			return dir
+		}
+	}
+	return filepath.Join(base, names[len(names)-1])
+}
+
+// readKeyValues reads "key value" lines such as cpu.stat and memory.stat.
+func readKeyValues(path string) map[string]uint64 {
+	values := make(map[string]uint64)
+	file, err := os.Open(path)
+	if err != nil {
+		return values
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64); err == nil {
+			values[key] = n
+		}
+	}
+	return values
+}
+
+func subtractFloor(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
+
+// cgroupSampler turns cgroup counters into rates between samples.
+type cgroupSampler struct {
+	root   string
+	env    string
+	cpus   int
+	prev   cgroupCounters
+	prevAt time.Time
+}
+
+func newCgroupSampler(root string) *cgroupSampler {
+	return &cgroupSampler{root: root, env: detectEnvironment(root, os.Getenv), cpus: runtime.NumCPU()}
+}
+
+// Sample returns nil outside a container or VM.
+func (s *cgroupSampler) Sample(now time.Time) *ContainerStatus {
+	if s.env == "" {
+		return nil
+	}
+	status := &ContainerStatus{Env: s.env}
+	if s.env == "wsl" {
+		return status // The WSL cgroup is the whole VM; the host-wide numbers already are.
+	}
+	counters, ok := readCgroup(s.root)
+	if !ok {
+		return status
+	}
+	status.CgroupVersion = counters.version
+	status.CPULimit = counters.cpuLimit
+	status.MemoryUsed = counters.memoryUsed
+	status.MemoryLimit = counters.memoryLimit
+
+	if !s.prevAt.IsZero() {
+		if elapsed := now.Sub(s.prevAt).Seconds(); elapsed > 0 {
+			cpus := counters.cpuLimit
+			if cpus == 0 {
+				cpus = float64(s.cpus)
+			}
+			if counters.cpuUsage >= s.prev.cpuUsage {
+				used := (counters.cpuUsage - s.prev.cpuUsage).Seconds()
+				status.CPUUsage = math.Min(used/elapsed/cpus*100, 100)
+			}
+			const mb = 1024 * 1024
+			if counters.ioRead >= s.prev.ioRead {
+				status.IOReadRate = float64(counters.ioRead-s.prev.ioRead) / mb / elapsed
+			}
+			if counters.ioWrite >= s.prev.ioWrite {
+				status.IOWriteRate = float64(counters.ioWrite-s.prev.ioWrite) / mb / elapsed
+			}
+		}
+	}
+	s.prev, s.prevAt = counters, now
+	return status
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectEnvironment(t *testing.T) {
+	tests := []struct {
+		root string
+		env  map[string]string
+		want string
+	}{
+		{"testdata/cgroup/docker-v2", nil, "docker"},
+		{"testdata/cgroup/kubernetes-v1", nil, "kubernetes"},
+		{"testdata/cgroup/wsl", nil, "wsl"},
+		{"testdata/linux/desktop", nil, ""},
+		{"testdata/cgroup/docker-v2", map[string]string{"REMOTE_CONTAINERS": "true"}, "devcontainer"},
+	}
+	for _, tt := range tests {
+		getenv := func(key string) string { return tt.env[key] }
+		if got := detectEnvironment(tt.root, getenv); got != tt.want {
+			t.Errorf("detectEnvironment(%s, %v) = %q, want %q", tt.root, tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestReadCgroup(t *testing.T) {
+	tests := []struct {
+		root string
+		want cgroupCounters
+	}{
+		{"testdata/cgroup/docker-v2", cgroupCounters{
+			version: 2, cpuLimit: 1.5, cpuUsage: 5 * time.Second,
+			memoryUsed: 768 << 20, memoryLimit: 2 << 30, ioRead: 2 << 20, ioWrite: 2 << 20,
+		}},
+		// The pod's cgroup path is not visible inside its namespace; the mount root is used.
+		{"testdata/cgroup/kubernetes-v1", cgroupCounters{
+			version: 1, cpuLimit: 0.5, cpuUsage: 3 * time.Second,
+			memoryUsed: 256 << 20, memoryLimit: 512 << 20, ioRead: 4096, ioWrite: 8192,
+		}},
+	}
+	for _, tt := range tests {
+		got, ok := readCgroup(tt.root)
+		if !ok || got != tt.want {
+			t.Errorf("readCgroup(%s) = %+v, %v\nwant %+v", tt.root, got, ok, tt.want)
+		}
+	}
+	if _, ok := readCgroup("testdata/cgroup/wsl"); ok {
+		t.Errorf("a tree without cgroup mounts should not be read")
+	}
+}
+
+func TestCgroupSamplerRates(t *testing.T) {
+	root := t.TempDir()
+	if err := os.CopyFS(root, os.DirFS("testdata/cgroup/docker-v2")); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, data string) {
+		if err := os.WriteFile(filepath.Join(root, "sys/fs/cgroup", name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &cgroupSampler{root: root, env: "docker", cpus: 8}
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	first := s.Sample(at)
+	if first.CPUUsage != 0 || first.CPULimit != 1.5 || first.MemoryPercent() != 37.5 {
+		t.Fatalf("first sample = %+v", first)
+	}
+
+	// 1.5 CPU-seconds over 2s against a 1.5 CPU limit is 50%.
+	write("cpu.stat", "usage_usec 6500000\n")
+	write("io.stat", "8:0 rbytes=5242880 wbytes=2097152\n")
+	second := s.Sample(at.Add(2 * time.Second))
+	if second.CPUUsage != 50 || second.IOReadRate != 1.5 || second.IOWriteRate != 0 {
+		t.Errorf("second sample = %+v", second)
+	}
+
+	// Without a quota usage is measured against every CPU.
+	write("cpu.max", "max 100000\n")
+	write("memory.max", "max\n")
+	write("cpu.stat", "usage_usec 10500000\n")
+	third := s.Sample(at.Add(3 * time.Second))
+	if third.CPULimit != 0 || third.CPUUsage != 50 || third.MemoryLimit != 0 {
+		t.Errorf("unlimited sample = %+v", third)
+	}
+
+	if got := (&cgroupSampler{root: root}).Sample(at); got != nil {
+		t.Errorf("no environment should report nothing, got %+v", got)
+	}
+	if got := (&cgroupSampler{root: root, env: "wsl"}).Sample(at); got == nil || got.CgroupVersion != 0 {
+		t.Errorf("WSL should only be labelled, got %+v", got)
+	}
+}
+
+func TestContainerCardsAndHeader(t *testing.T) {
+	m := MetricsSnapshot{
+		CPU:       CPUStatus{Usage: 10, LogicalCPU: 8},
+		Memory:    MemoryStatus{UsedPercent: 20, Total: 16 << 30},
+		Container: &ContainerStatus{Env: "docker", CgroupVersion: 2, CPULimit: 1.5, CPUUsage: 50, MemoryUsed: 768 << 20, MemoryLimit: 2 << 30},
+	}
+	cards := buildCards(m, MetricsHistory{}, graphWindows[0], 0)
+	if cpu := strings.Join(cards[0].lines, "\n"); !strings.Contains(cpu, "50.0% of 1.5 CPUs") {
+		t.Errorf("cpu card missing cgroup usage:\n%s", cpu)
+	}
+	if mem := strings.Join(cards[1].lines, "\n"); !strings.Contains(mem, "37.5% 768.0M/2.0G") {
+		t.Errorf("memory card missing cgroup usage:\n%s", mem)
+	}
+	if header := renderHeader(m, "", 0, 100, true); !strings.Contains(header, "[Docker]") {
+		t.Errorf("header missing environment label:\n%s", header)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/net"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+const connectionsTimeout = 3 * time.Second // lsof on macOS can be slow
+
+// ConnectionInfo is one listening or established socket.
+type ConnectionInfo struct {
+	PID        int32  `json:"pid"` // 0 when the owner is not visible without root
+	Process    string `json:"process"`
+	Proto      string `json:"proto"` // tcp, tcp6, udp or udp6
+	LocalAddr  string `json:"local_addr"`
+	LocalPort  uint32 `json:"local_port"`
+	RemoteAddr string `json:"remote_addr"`
+	RemotePort uint32 `json:"remote_port"`
+	Status     string `json:"status"` // LISTEN or ESTABLISHED; empty for UDP
+}
+
+// Listening reports TCP listeners and bound UDP sockets without a peer.
+func (c ConnectionInfo) Listening() bool {
+	return c.Status == "LISTEN" || (c.Status == "" && c.RemotePort == 0)
+}
+
+// connectionSampler lists sockets and names their processes. Names are cached
+// by PID for as long as the PID keeps showing up.
+type connectionSampler struct {
+	names map[int32]string
+}
+
+func newConnectionSampler() *connectionSampler {
+	return &connectionSampler{names: make(map[int32]string)}
+}
+
+func (s *connectionSampler) Sample(ctx context.Context) ([]ConnectionInfo, error) {
+	stats, err := net.ConnectionsWithContext(ctx, "inet")
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int32]string)
+	var conns []ConnectionInfo
+	for _, st := range stats {
+		conn := ConnectionInfo{
+			PID:        st.Pid,
+			Proto:      connectionProto(st.Type, st.Family),
+			LocalAddr:  st.Laddr.IP,
+			LocalPort:  st.Laddr.Port,
+			RemoteAddr: st.Raddr.IP,
+			RemotePort: st.Raddr.Port,
+		}
+		if st.Type == syscall.SOCK_STREAM {
+			conn.Status = st.Status
+		}
+		if conn.Status != "ESTABLISHED" && !conn.Listening() {
+			continue // TIME_WAIT and friends belong to no one in particular
+		}
+		if conn.PID > 0 {
+			name, ok := names[conn.PID]
+			if !ok {
+				if name, ok = s.names[conn.PID]; !ok {
+					if p, err := process.NewProcessWithContext(ctx, conn.PID); err == nil {
+						name, _ = p.NameWithContext(ctx)
+					}
+				}
+				names[conn.PID] = name
+			}
+			conn.Process = name
+		}
+		conns = append(conns, conn)
+	}
+	s.names = names
+	return conns, nil
+}
+
+func connectionProto(sockType, family uint32) string {
+	proto := "tcp"
+	if sockType == syscall.SOCK_DGRAM {
+		proto = "udp"
+	}
+	if family == syscall.AF_INET6 {
+		proto += "6"
+	}
+	return proto
+}
+
+// listeningSockets returns listeners ordered by port, one row per process and address.
+func listeningSockets(conns []ConnectionInfo) []ConnectionInfo {
+	var out []ConnectionInfo
+	for _, c := range conns {
+		if c.Listening() && !slices.Contains(out, c) {
+			out = append(out, c)
+		}
+	}
+	slices.SortFunc(out, func(a, b ConnectionInfo) int {
+		return cmp.Or(cmp.Compare(a.LocalPort, b.LocalPort), cmp.Compare(a.Proto, b.Proto), cmp.Compare(a.PID, b.PID), cmp.Compare(a.LocalAddr, b.LocalAddr))
+	})
+	return out
+}
+
+// connectionGroup is the established connections of one process.
+type connectionGroup struct {
+	PID     int32
+	Process string
+	Count   int
+	Remotes []string // host:port, most connections first
+}
+
+// establishedByProcess groups established connections by process, busiest first.
+func establishedByProcess(conns []ConnectionInfo) []connectionGroup {
+	index := make(map[int32]int)
+	var groups []connectionGroup
+	var perRemote []map[string]int
+	for _, c := range conns {
+		if c.Status != "ESTABLISHED" {
+			continue
+		}
+		i, ok := index[c.PID]
+		if !ok {
+			i = len(groups)
+			index[c.PID] = i
+			groups = append(groups, connectionGroup{PID: c.PID, Process: c.Process})
+			perRemote = append(perRemote, make(map[string]int))
+		}
+		remote := formatHostPort(c.RemoteAddr, c.RemotePort)
+		if perRemote[i][remote] == 0 {
+			groups[i].Remotes = append(groups[i].Remotes, remote)
+		}
+		perRemote[i][remote]++
+		groups[i].Count++
+	}
+	for i := range groups {
+		seen := perRemote[i]
+		slices.SortStableFunc(groups[i].Remotes, func(a, b string) int { return cmp.Compare(seen[b], seen[a]) })
+	}
+	slices.SortStableFunc(groups, func(a, b connectionGroup) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Process, b.Process))
+	})
+	return groups
+}
+
+// formatHostPort brackets IPv6 addresses the way URLs do.
+func formatHostPort(host string, port uint32) string {
+	if host == "" {
+		host = "*"
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return host + ":" + strconv.FormatUint(uint64(port), 10)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"runtime"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -22,6 +24,37 @@ var skipDiskMounts = map[string]bool{
 	"/dev":                     true,
 }
 
+// pseudoFilesystems hold no user data. They are listed on the disk screen
+// but left out of health, alerts on the fullest disk, the exporter and --json.
+var pseudoFilesystems = map[string]bool{
+	"tmpfs": true, "devtmpfs": true, "ramfs": true, "squashfs": true,
+	"efivarfs": true, "autofs": true, "devfs": true, "nullfs": true,
+}
+
+const minDataVolume = 1 << 30 // Smaller mounts are boot, EFI or scratch areas
+
+// dataVolumes picks the mounts that count as storage: real filesystems of
+// at least minDataVolume, largest first.
+func dataVolumes(mounts []DiskStatus) []DiskStatus {
+	var out []DiskStatus
+	for _, d := range mounts {
+		if d.Total >= minDataVolume && !pseudoFilesystems[d.Fstype] {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// allMounts is what the disk screen lists. Recordings carry no Mounts, so
+// they fall back to the data volumes.
+func (m MetricsSnapshot) allMounts() []DiskStatus {
+	if len(m.Mounts) > 0 {
+		return m.Mounts
+	}
+	return m.Disks
+}
+
+// collectDisks returns every mount worth showing on the disk screen, largest first.
 func collectDisks() ([]DiskStatus, error) {
 	partitions, err := disk.Partitions(false)
 	if err != nil {
@@ -58,10 +91,6 @@ func collectDisks() ([]DiskStatus, error) {
 		if err != nil || usage.Total == 0 {
 			continue
 		}
-		// Skip <1GB volumes.
-		if usage.Total < 1<<30 {
-			continue
-		}
 		// Use size-based dedupe key for shared pools.
 		volKey := fmt.Sprintf("%s:%d", part.Fstype, usage.Total)
 		if seenVolume[volKey] {
@@ -74,6 +103,10 @@ func collectDisks() ([]DiskStatus, error) {
 			Total:       usage.Total,
 			UsedPercent: usage.UsedPercent,
 			Fstype:      part.Fstype,
+
+			InodesUsed:    usage.InodesUsed,
+			InodesTotal:   usage.InodesTotal,
+			InodesPercent: usage.InodesUsedPercent,
 		})
 		seenDevice[baseDevice] = true
 		seenVolume[volKey] = true
@@ -85,11 +118,71 @@ func collectDisks() ([]DiskStatus, error) {
 		return disks[i].Total > disks[j].Total
 	})
 
-	if len(disks) > 3 {
-		disks = disks[:3]
+	return disks, nil
+}
+
+const (
+	diskTrendWindow  = time.Hour
+	diskTrendMinSpan = 2 * time.Minute // Less history makes a meaningless projection
+	diskFullHorizon  = 365             // Days; longer projections are not shown
+)
+
+// diskTrend keeps recent used-bytes samples per mount to estimate growth.
+type diskTrend struct {
+	samples map[string][]diskUsageSample
+}
+
+type diskUsageSample struct {
+	at   time.Time
+	used uint64
+}
+
+func newDiskTrend() *diskTrend {
+	return &diskTrend{samples: make(map[string][]diskUsageSample)}
+}
+
+// Annotate records each disk's usage and sets its growth rate and days until full.
+// Mounts that are gone are forgotten.
+func (t *diskTrend) Annotate(disks []DiskStatus, now time.Time) {
+	next := make(map[string][]diskUsageSample, len(disks))
+	for i := range disks {
+		d := &disks[i]
+		samples := append(t.samples[d.Mount], diskUsageSample{at: now, used: d.Used})
+		for len(samples) > 1 && now.Sub(samples[0].at) > diskTrendWindow {
+			samples = samples[1:]
+		}
+		next[d.Mount] = samples
+		if now.Sub(samples[0].at) < diskTrendMinSpan {
+			continue
+		}
+		d.GrowthRate = usageSlope(samples) * 86400
+		if d.GrowthRate > 0 && d.Total > d.Used {
+			if days := float64(d.Total-d.Used) / d.GrowthRate; days <= diskFullHorizon {
+				d.FullInDays = days
+			}
+		}
 	}
+	t.samples = next
+}
 
-	return disks, nil
+// usageSlope is the least-squares growth in bytes per second.
+func usageSlope(samples []diskUsageSample) float64 {
+	first := samples[0]
+	var n, sx, sy, sxx, sxy float64
+	for _, s := range samples {
+		x := s.at.Sub(first.at).Seconds()
+		y := float64(s.used) - float64(first.used) // Offset keeps the sums small
+		n++
+		sx += x
+		sy += y
+		sxx += x * x
+		sxy += x * y
+	}
+	den := n*sxx - sx*sx
+	if den == 0 {
+		return 0
+	}
+	return (n*sxy - sx*sy) / den
 }
 
 var (
@@ -174,41 +267,66 @@ func isExternalDisk(device string) (bool, error) {
 	return external, nil
 }
 
-func (c *Collector) collectDiskIO(now time.Time) DiskIOStatus {
+// collectDiskIO returns the rate across all devices and the rate of each device.
+func (c *Collector) collectDiskIO(now time.Time) (DiskIOStatus, map[string]DiskIOStatus) {
 	counters, err := disk.IOCounters()
 	if err != nil || len(counters) == 0 {
-		return DiskIOStatus{}
-	}
-
-	var total disk.IOCountersStat
-	for _, v := range counters {
-		total.ReadBytes += v.ReadBytes
-		total.WriteBytes += v.WriteBytes
+		return DiskIOStatus{}, nil
 	}
 
-	if c.lastDiskAt.IsZero() {
-		c.prevDiskIO = total
-		c.lastDiskAt = now
-		return DiskIOStatus{}
+	prev, lastAt := c.prevDiskIO, c.lastDiskAt
+	c.prevDiskIO, c.lastDiskAt = counters, now
+	if lastAt.IsZero() {
+		return DiskIOStatus{}, nil
 	}
 
-	elapsed := now.Sub(c.lastDiskAt).Seconds()
+	elapsed := now.Sub(lastAt).Seconds()
 	if elapsed <= 0 {
 		elapsed = 1
 	}
 
-	readRate := float64(total.ReadBytes-c.prevDiskIO.ReadBytes) / 1024 / 1024 / elapsed
-	writeRate := float64(total.WriteBytes-c.prevDiskIO.WriteBytes) / 1024 / 1024 / elapsed
-
-	c.prevDiskIO = total
-	c.lastDiskAt = now
+	var total DiskIOStatus
+	devices := make(map[string]DiskIOStatus, len(counters))
+	for name, cur := range counters {
+		p, ok := prev[name]
+		if !ok {
+			continue
+		}
+		rate := DiskIOStatus{
+			ReadRate:  byteRate(p.ReadBytes, cur.ReadBytes, elapsed),
+			WriteRate: byteRate(p.WriteBytes, cur.WriteBytes, elapsed),
+		}
+		devices[name] = rate
+		total.ReadRate += rate.ReadRate
+		total.WriteRate += rate.WriteRate
+	}
+	return total, devices
+}
 
-	if readRate < 0 {
-		readRate = 0
+// withDeviceRates copies disks with the IO rate of each mount's device.
+func withDeviceRates(disks []DiskStatus, devices map[string]DiskIOStatus) []DiskStatus {
+	if len(disks) == 0 || devices == nil {
+		return disks
 	}
-	if writeRate < 0 {
-		writeRate = 0
+	disks = slices.Clone(disks)
+	for i := range disks {
+		if rate, ok := lookupDeviceRate(disks[i].Device, devices); ok {
+			disks[i].ReadRate, disks[i].WriteRate = rate.ReadRate, rate.WriteRate
+		}
 	}
+	return disks
+}
 
-	return DiskIOStatus{ReadRate: readRate, WriteRate: writeRate}
+// lookupDeviceRate matches a mount's device with its IO counters. Linux counts
+// partitions by kernel name (dm-0 for /dev/mapper), macOS whole disks only.
+func lookupDeviceRate(device string, devices map[string]DiskIOStatus) (DiskIOStatus, bool) {
+	if resolved, err := filepath.EvalSymlinks(device); err == nil {
+		device = resolved
+	}
+	name := strings.TrimPrefix(device, "/dev/")
+	if rate, ok := devices[name]; ok {
+		return rate, true
+	}
+	rate, ok := devices[baseDeviceName(name)]
+	return rate, ok
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDiskTrendProjectsFullDate(t *testing.T) {
+	trend := newDiskTrend()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	const gb = 1 << 30
+	disk := func(used uint64) []DiskStatus {
+		return []DiskStatus{
+			{Mount: "/", Used: used, Total: 500 * gb},
+			{Mount: "/boot", Used: gb, Total: 2 * gb},
+		}
+	}
+
+	// One minute is too little history to project from.
+	var disks []DiskStatus
+	for i := range 13 {
+		disks = disk(uint64(400*gb + i*gb/12))
+		trend.Annotate(disks, at.Add(time.Duration(i)*5*time.Second))
+	}
+	if disks[0].GrowthRate != 0 || disks[0].FullInDays != 0 {
+		t.Fatalf("projection after 1m = %+v", disks[0])
+	}
+
+	// 1 GB a minute leaves 100 GB for 100 minutes.
+	for i := 13; i <= 36; i++ {
+		disks = disk(uint64(400*gb + i*gb/12))
+		trend.Annotate(disks, at.Add(time.Duration(i)*5*time.Second))
+	}
+	if got := disks[0].GrowthRate / gb; math.Abs(got-1440) > 0.01 {
+		t.Errorf("growth = %.2f GB/day, want 1440", got)
+	}
+	wantDays := float64(500*gb-disks[0].Used) / (1440 * gb)
+	if math.Abs(disks[0].FullInDays-wantDays) > 1e-6 {
+		t.Errorf("full in %v days, want %v", disks[0].FullInDays, wantDays)
+	}
+	if disks[1].GrowthRate != 0 || disks[1].FullInDays != 0 {
+		t.Errorf("a steady mount should not be filling: %+v", disks[1])
+	}
+
+	// Samples older than the window are dropped, and so are mounts that went away.
+	trend.Annotate(disk(450 * gb)[:1], at.Add(2*diskTrendWindow))
+	if n := len(trend.samples["/"]); n != 1 {
+		t.Errorf("kept %d samples past the window", n)
+	}
+	if _, ok := trend.samples["/boot"]; ok {
+		t.Errorf("unmounted /boot should be forgotten")
+	}
+}
+
+func TestWithDeviceRates(t *testing.T) {
+	devices := map[string]DiskIOStatus{
+		"nvme0n1p2": {ReadRate: 1, WriteRate: 2},
+		"disk3":     {ReadRate: 3, WriteRate: 4},
+	}
+	disks := []DiskStatus{
+		{Mount: "/", Device: "/dev/nvme0n1p2"},
+		{Mount: "/Volumes/Data", Device: "/dev/disk3s1"},
+		{Mount: "/mnt/nfs", Device: "server:/export"},
+	}
+	got := withDeviceRates(disks, devices)
+	if got[0].ReadRate != 1 || got[0].WriteRate != 2 || got[1].ReadRate != 3 || got[2].ReadRate != 0 {
+		t.Errorf("withDeviceRates = %+v", got)
+	}
+	if disks[0].ReadRate != 0 {
+		t.Errorf("the provider's cached slice should not be modified")
+	}
+}
+
+func TestDataVolumesSkipTinyAndPseudoMounts(t *testing.T) {
+	mounts := []DiskStatus{
+		{Mount: "/", Fstype: "ext4", Total: 500e9, UsedPercent: 40},
+		{Mount: "/mnt/sandboxing", Fstype: "ext4", Total: 470e6, UsedPercent: 87},
+		{Mount: "/dev/shm", Fstype: "tmpfs", Total: 8e9, UsedPercent: 95},
+		{Mount: "/srv", Fstype: "xfs", Total: 2e12, UsedPercent: 50},
+	}
+	disks := dataVolumes(mounts)
+	if len(disks) != 2 || disks[0].Mount != "/" || disks[1].Mount != "/srv" {
+		t.Fatalf("dataVolumes = %+v", disks)
+	}
+
+	score, _, _ := defaultHealthModel().score(CPUStatus{}, MemoryStatus{}, disks, DiskIOStatus{}, ThermalStatus{})
+	if score != 100 {
+		t.Errorf("tiny and pseudo mounts should not cost health, score %d", score)
+	}
+	snapshot := MetricsSnapshot{Mounts: mounts, Disks: disks}
+	if len(snapshot.allMounts()) != 4 {
+		t.Errorf("the disk screen should still list every mount")
+	}
+	if v, ok := alertMetrics["disk.used_percent"].read(snapshot, ""); !ok || v != 50 {
+		t.Errorf("fullest data volume = %v, want 50", v)
+	}
+	if v, ok := alertMetrics["disk.used_percent"].read(snapshot, "/dev/shm"); !ok || v != 95 {
+		t.Errorf("a named mount should be found among all mounts, got %v", v)
+	}
+}
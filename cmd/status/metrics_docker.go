@@ -0,0 +1,479 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dockerInterval   = 2 * time.Second
+	dockerTimeout    = 3 * time.Second
+	dockerDFInterval = time.Minute // /system/df walks every layer; keep it rare
+	dockerDFTimeout  = 5 * time.Second
+	dockerStatsLimit = 8 // Concurrent stats requests
+)
+
+// dockerHost is the Engine API endpoint from --docker-host. Empty means
+// $DOCKER_HOST, then $CONTAINER_HOST, then the usual Docker and Podman sockets.
+var dockerHost string
+
+// DockerStatus is what the Docker or Podman engine reports.
+type DockerStatus struct {
+	Endpoint   string            `json:"endpoint"`
+	Engine     string            `json:"engine"` // docker or podman
+	Version    string            `json:"version"`
+	Containers []DockerContainer `json:"containers"` // Running only, busiest first
+	Images     DockerUsage       `json:"images"`
+	Volumes    DockerUsage       `json:"volumes"`
+	BuildCache DockerUsage       `json:"build_cache"`
+}
+
+type DockerContainer struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	Image          string  `json:"image"`
+	CPU            float64 `json:"cpu"`          // Percent of one CPU, like docker stats
+	MemoryUsed     uint64  `json:"memory_used"`  // Excludes reclaimable page cache
+	MemoryLimit    uint64  `json:"memory_limit"` // Host memory when unlimited
+	NetRxRate      float64 `json:"net_rx_rate_mbs"`
+	NetTxRate      float64 `json:"net_tx_rate_mbs"`
+	BlockReadRate  float64 `json:"block_read_rate_mbs"`
+	BlockWriteRate float64 `json:"block_write_rate_mbs"`
+}
+
+// DockerUsage is the disk used by one kind of engine object.
+type DockerUsage struct {
+	Count       int    `json:"count"`
+	Size        uint64 `json:"size"`
+	Reclaimable uint64 `json:"reclaimable"` // Not used by any container
+}
+
+// dockerSocketCandidates are the endpoints probed in order when none is
+// configured. Docker Desktop and Podman on Windows listen on named pipes.
+func dockerSocketCandidates(goos string, getenv func(string) string, home string) []string {
+	if goos == "windows" {
+		return []string{"npipe:////./pipe/docker_engine", "npipe:////./pipe/podman-machine-default"}
+	}
+	candidates := []string{"/var/run/docker.sock"}
+	if xdg := getenv("XDG_RUNTIME_DIR"); xdg != "" {
+		candidates = append(candidates, filepath.Join(xdg, "podman", "podman.sock"), filepath.Join(xdg, "docker.sock"))
+	}
+	candidates = append(candidates, "/run/podman/podman.sock")
+	if home != "" {
+		candidates = append(candidates,
+			filepath.Join(home, ".docker", "run", "docker.sock"), // Docker Desktop
+			filepath.Join(home, ".orbstack", "run", "docker.sock"),
+			filepath.Join(home, ".colima", "default", "docker.sock"),
+		)
+	}
+	for i, path := range candidates {
+		candidates[i] = "unix://" + path
+	}
+	return candidates
+}
+
+// dockerPipePath turns npipe:////./pipe/name into \\.\pipe\name.
+func dockerPipePath(endpoint string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(endpoint, "npipe://"), "/", `\`)
+}
+
+// resolveDockerEndpoint picks the endpoint to use. explicit is true when the
+// user configured one, so failures to reach it are worth reporting.
+func resolveDockerEndpoint(flagValue string, getenv func(string) string, home string) (endpoint string, explicit bool) {
+	for _, configured := range []string{flagValue, getenv("DOCKER_HOST"), getenv("CONTAINER_HOST")} {
+		if configured != "" {
+			return configured, true
+		}
+	}
+	for _, endpoint := range dockerSocketCandidates(runtime.GOOS, getenv, home) {
+		path := strings.TrimPrefix(endpoint, "unix://")
+		if strings.HasPrefix(endpoint, "npipe://") {
+			path = dockerPipePath(endpoint)
+		}
+		if fileExists(path) {
+			return endpoint, false
+		}
+	}
+	return "", false
+}
+
+// dockerClient speaks the Engine API, which Podman also serves.
+type dockerClient struct {
+	endpoint string
+	base     string // URL prefix for requests
+	http     *http.Client
+}
+
+// newDockerClient accepts unix://, npipe://, tcp://, http:// and plain socket paths.
+func newDockerClient(endpoint string) (*dockerClient, error) {
+	c := &dockerClient{endpoint: endpoint}
+	transport := &http.Transport{MaxIdleConnsPerHost: dockerStatsLimit}
+	switch {
+	case strings.HasPrefix(endpoint, "/") || strings.HasPrefix(endpoint, "unix://"):
+		socket := strings.TrimPrefix(endpoint, "unix://")
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		}
+		c.base = "http://docker"
+	case strings.HasPrefix(endpoint, "npipe://"):
+		pipe := dockerPipePath(endpoint)
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialDockerPipe(ctx, pipe)
+		}
+		c.base = "http://docker"
+	case strings.HasPrefix(endpoint, "tcp://"):
+		c.base = "http://" + strings.TrimPrefix(endpoint, "tcp://")
+	case strings.HasPrefix(endpoint, "http://"):
+		c.base = strings.TrimSuffix(endpoint, "/")
+	default:
+		return nil, fmt.Errorf("unsupported endpoint %q (want unix://, npipe://, tcp:// or http://)", endpoint)
+	}
+	c.http = &http.Client{Transport: transport}
+	return c, nil
+}
+
+func (c *dockerClient) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		return fmt.Errorf("%s: %s", path, apiErr.Message)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+type dockerVersionJSON struct {
+	Version    string `json:"Version"`
+	Components []struct {
+		Name string `json:"Name"`
+	} `json:"Components"`
+}
+
+type dockerContainerJSON struct {
+	ID    string   `json:"Id"`
+	Names []string `json:"Names"`
+	Image string   `json:"Image"`
+}
+
+type dockerCPUStatsJSON struct {
+	CPUUsage struct {
+		TotalUsage uint64 `json:"total_usage"`
+	} `json:"cpu_usage"`
+	SystemUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs  int    `json:"online_cpus"`
+}
+
+type dockerStatsJSON struct {
+	CPUStats    dockerCPUStatsJSON `json:"cpu_stats"`
+	PreCPUStats dockerCPUStatsJSON `json:"precpu_stats"`
+	MemoryStats struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"`
+	} `json:"memory_stats"`
+	Networks map[string]struct {
+		RxBytes uint64 `json:"rx_bytes"`
+		TxBytes uint64 `json:"tx_bytes"`
+	} `json:"networks"`
+	BlkioStats struct {
+		IOServiceBytesRecursive []struct {
+			Op    string `json:"op"`
+			Value uint64 `json:"value"`
+		} `json:"io_service_bytes_recursive"`
+	} `json:"blkio_stats"`
+}
+
+type dockerDFJSON struct {
+	LayersSize int64 `json:"LayersSize"`
+	Images     []struct {
+		Size       int64 `json:"Size"`
+		SharedSize int64 `json:"SharedSize"`
+		Containers int64 `json:"Containers"`
+	} `json:"Images"`
+	Volumes []struct {
+		UsageData struct {
+			Size     int64 `json:"Size"`
+			RefCount int64 `json:"RefCount"`
+		} `json:"UsageData"`
+	} `json:"Volumes"`
+	BuildCache []struct {
+		Size   int64 `json:"Size"`
+		InUse  bool  `json:"InUse"`
+		Shared bool  `json:"Shared"`
+	} `json:"BuildCache"`
+}
+
+// dockerCounters are the cumulative values rates are computed from.
+type dockerCounters struct {
+	cpuTotal, cpuSystem   uint64
+	netRx, netTx          uint64
+	blockRead, blockWrite uint64
+}
+
+// dockerSampler polls the engine and keeps counters between polls.
+type dockerSampler struct {
+	flagValue string
+	getenv    func(string) string
+	home      string
+
+	mu     sync.Mutex
+	client *dockerClient
+	prev   map[string]dockerCounters
+	prevAt time.Time
+}
+
+func newDockerSampler(flagValue string) *dockerSampler {
+	home, _ := os.UserHomeDir()
+	return &dockerSampler{flagValue: flagValue, getenv: os.Getenv, home: home}
+}
+
+// connect returns a client for the current endpoint. Docker Desktop may be
+// started after status, so the endpoint is resolved on every poll.
+func (s *dockerSampler) connect() (*dockerClient, bool, error) {
+	endpoint, explicit := resolveDockerEndpoint(s.flagValue, s.getenv, s.home)
+	if endpoint == "" {
+		return nil, false, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client == nil || s.client.endpoint != endpoint {
+		client, err := newDockerClient(endpoint)
+		if err != nil {
+			return nil, explicit, err
+		}
+		s.client, s.prev = client, nil
+	}
+	return s.client, explicit, nil
+}
+
+// Sample returns nil when no engine is running. Errors are only returned for
+// a configured endpoint; an auto-detected socket with its engine stopped is normal.
+func (s *dockerSampler) Sample(ctx context.Context, now time.Time) (*DockerStatus, error) {
+	client, explicit, err := s.connect()
+	if client == nil {
+		return nil, err
+	}
+	status, err := s.sample(ctx, client, now)
+	if err != nil && !explicit {
+		return nil, nil
+	}
+	return status, err
+}
+
+func (s *dockerSampler) sample(ctx context.Context, client *dockerClient, now time.Time) (*DockerStatus, error) {
+	var version dockerVersionJSON
+	if err := client.get(ctx, "/version", &version); err != nil {
+		return nil, err
+	}
+	status := &DockerStatus{Endpoint: client.endpoint, Engine: "docker", Version: version.Version}
+	for _, component := range version.Components {
+		if strings.Contains(strings.ToLower(component.Name), "podman") {
+			status.Engine = "podman"
+		}
+	}
+
+	var list []dockerContainerJSON
+	if err := client.get(ctx, "/containers/json", &list); err != nil {
+		return nil, err
+	}
+	stats := make([]*dockerStatsJSON, len(list))
+	sem := make(chan struct{}, dockerStatsLimit)
+	var wg sync.WaitGroup
+	for i, c := range list {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			// one-shot skips the engine's own one second CPU sample; rates come from the previous poll.
+			var st dockerStatsJSON
+			if client.get(ctx, "/containers/"+url.PathEscape(c.ID)+"/stats?stream=false&one-shot=true", &st) == nil {
+				stats[i] = &st
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elapsed := now.Sub(s.prevAt).Seconds()
+	next := make(map[string]dockerCounters, len(list))
+	for i, c := range list {
+		container := DockerContainer{ID: shortContainerID(c.ID), Image: c.Image}
+		if len(c.Names) > 0 {
+			container.Name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		if st := stats[i]; st != nil {
+			counters := st.counters()
+			container.MemoryUsed, container.MemoryLimit = st.memory()
+			prev, seen := s.prev[c.ID]
+			container.CPU = st.cpuPercent(prev, seen)
+			if seen && elapsed > 0 {
+				container.NetRxRate = byteRate(prev.netRx, counters.netRx, elapsed)
+				container.NetTxRate = byteRate(prev.netTx, counters.netTx, elapsed)
+				container.BlockReadRate = byteRate(prev.blockRead, counters.blockRead, elapsed)
+				container.BlockWriteRate = byteRate(prev.blockWrite, counters.blockWrite, elapsed)
+			}
+			next[c.ID] = counters
+		}
+		status.Containers = append(status.Containers, container)
+	}
+	s.prev, s.prevAt = next, now
+
+	slices.SortStableFunc(status.Containers, func(a, b DockerContainer) int {
+		switch {
+		case a.CPU != b.CPU:
+			if a.CPU > b.CPU {
+				return -1
+			}
+			return 1
+		case a.MemoryUsed != b.MemoryUsed:
+			if a.MemoryUsed > b.MemoryUsed {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return status, nil
+}
+
+// DiskUsage reads /system/df. It returns nil, nil when no engine is running.
+func (s *dockerSampler) DiskUsage(ctx context.Context) (*dockerDiskUsage, error) {
+	client, explicit, err := s.connect()
+	if client == nil {
+		return nil, err
+	}
+	var df dockerDFJSON
+	if err := client.get(ctx, "/system/df", &df); err != nil {
+		if !explicit {
+			return nil, nil
+		}
+		return nil, err
+	}
+	usage := &dockerDiskUsage{}
+	var imageSum uint64
+	for _, img := range df.Images {
+		usage.images.Count++
+		imageSum += nonNegative(img.Size)
+		if img.Containers == 0 {
+			usage.images.Reclaimable += nonNegative(img.Size - max(img.SharedSize, 0))
+		}
+	}
+	// LayersSize counts shared layers once; the per-image sizes double count them.
+	usage.images.Size = nonNegative(df.LayersSize)
+	if usage.images.Size == 0 {
+		usage.images.Size = imageSum
+	}
+	usage.images.Reclaimable = min(usage.images.Reclaimable, usage.images.Size)
+	for _, vol := range df.Volumes {
+		usage.volumes.Count++
+		size := nonNegative(vol.UsageData.Size) // -1 when the engine did not measure it
+		usage.volumes.Size += size
+		if vol.UsageData.RefCount == 0 {
+			usage.volumes.Reclaimable += size
+		}
+	}
+	for _, cache := range df.BuildCache {
+		usage.buildCache.Count++
+		usage.buildCache.Size += nonNegative(cache.Size)
+		if !cache.InUse && !cache.Shared {
+			usage.buildCache.Reclaimable += nonNegative(cache.Size)
+		}
+	}
+	return usage, nil
+}
+
+type dockerDiskUsage struct {
+	images, volumes, buildCache DockerUsage
+}
+
+func (st *dockerStatsJSON) counters() dockerCounters {
+	c := dockerCounters{cpuTotal: st.CPUStats.CPUUsage.TotalUsage, cpuSystem: st.CPUStats.SystemUsage}
+	for _, n := range st.Networks {
+		c.netRx += n.RxBytes
+		c.netTx += n.TxBytes
+	}
+	for _, entry := range st.BlkioStats.IOServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			c.blockRead += entry.Value
+		case "write":
+			c.blockWrite += entry.Value
+		}
+	}
+	return c
+}
+
+// memory matches docker stats: usage less inactive page cache.
+func (st *dockerStatsJSON) memory() (used, limit uint64) {
+	stats := st.MemoryStats.Stats
+	cache, ok := stats["inactive_file"] // cgroup v2
+	if !ok {
+		cache = stats["total_inactive_file"]
+	}
+	return subtractFloor(st.MemoryStats.Usage, cache), st.MemoryStats.Limit
+}
+
+// cpuPercent uses the engine's precpu sample when it sent one, else the previous poll.
+func (st *dockerStatsJSON) cpuPercent(prev dockerCounters, seen bool) float64 {
+	pre := dockerCounters{cpuTotal: st.PreCPUStats.CPUUsage.TotalUsage, cpuSystem: st.PreCPUStats.SystemUsage}
+	if pre.cpuSystem == 0 {
+		if !seen {
+			return 0
+		}
+		pre = prev
+	}
+	cur := st.CPUStats
+	if cur.CPUUsage.TotalUsage < pre.cpuTotal || cur.SystemUsage <= pre.cpuSystem {
+		return 0
+	}
+	cpus := max(cur.OnlineCPUs, 1)
+	return float64(cur.CPUUsage.TotalUsage-pre.cpuTotal) / float64(cur.SystemUsage-pre.cpuSystem) * float64(cpus) * 100
+}
+
+func byteRate(prev, cur uint64, seconds float64) float64 {
+	if cur < prev {
+		return 0 // Counter reset, e.g. a restarted container
+	}
+	return float64(cur-prev) / (1024 * 1024) / seconds
+}
+
+func nonNegative(v int64) uint64 {
+	return uint64(max(v, 0))
+}
+
+func shortContainerID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
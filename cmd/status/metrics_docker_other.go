@@ -0,0 +1,14 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// dialDockerPipe fails outside Windows, which has no named pipes.
+func dialDockerPipe(context.Context, string) (net.Conn, error) {
+	return nil, errors.New("npipe endpoints need Windows")
+}
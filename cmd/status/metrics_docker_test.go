@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEngine serves the Engine API endpoints status uses. Each stats request
+// advances a container's counters by one step.
+type fakeEngine struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (f *fakeEngine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reply := func(v any) { _ = json.NewEncoder(w).Encode(v) }
+	switch {
+	case r.URL.Path == "/version":
+		reply(map[string]any{"Version": "5.2.0", "Components": []map[string]string{{"Name": "Podman Engine"}}})
+	case r.URL.Path == "/containers/json":
+		reply([]map[string]any{
+			{"Id": "db0000000000aaaa", "Names": []string{"/postgres"}, "Image": "postgres:16"},
+			{"Id": "web000000000bbbb", "Names": []string{"/web"}, "Image": "node:22"},
+		})
+	case strings.HasSuffix(r.URL.Path, "/stats"):
+		id := strings.Split(r.URL.Path, "/")[2]
+		f.mu.Lock()
+		n := uint64(f.calls[id])
+		f.calls[id]++
+		f.mu.Unlock()
+		const mib = 1 << 20
+		if strings.HasPrefix(id, "web") {
+			// 0.5 CPU-seconds per 2s of 4 CPUs: a full core, 100%.
+			reply(map[string]any{
+				"cpu_stats":    map[string]any{"cpu_usage": map[string]any{"total_usage": 1e9 + n*5e8}, "system_cpu_usage": 100e9 + n*2e9, "online_cpus": 4},
+				"memory_stats": map[string]any{"usage": 300 * mib, "limit": 1 << 30, "stats": map[string]uint64{"inactive_file": 44 * mib}},
+				"networks":     map[string]any{"eth0": map[string]uint64{"rx_bytes": n * 2 * mib, "tx_bytes": n * mib}},
+				"blkio_stats":  map[string]any{"io_service_bytes_recursive": []map[string]any{{"op": "read", "value": n * 4 * mib}, {"op": "write", "value": 0}}},
+			})
+			return
+		}
+		reply(map[string]any{
+			"cpu_stats":    map[string]any{"cpu_usage": map[string]any{"total_usage": 5e9 + n*1e8}, "system_cpu_usage": 100e9 + n*2e9, "online_cpus": 4},
+			"memory_stats": map[string]any{"usage": 600 * mib, "limit": 8 << 30, "stats": map[string]uint64{"total_inactive_file": 88 * mib}},
+			"blkio_stats":  map[string]any{"io_service_bytes_recursive": nil},
+		})
+	case r.URL.Path == "/system/df":
+		reply(map[string]any{
+			"LayersSize": 3 << 30,
+			"Images": []map[string]any{
+				{"Size": 2 << 30, "SharedSize": 512 * (1 << 20), "Containers": 1},
+				{"Size": 1536 << 20, "SharedSize": 512 * (1 << 20), "Containers": 0},
+			},
+			"Volumes": []map[string]any{
+				{"UsageData": map[string]any{"Size": 1 << 30, "RefCount": 1}},
+				{"UsageData": map[string]any{"Size": 256 << 20, "RefCount": 0}},
+				{"UsageData": map[string]any{"Size": -1, "RefCount": 0}},
+			},
+			"BuildCache": []map[string]any{
+				{"Size": 700 << 20, "InUse": false, "Shared": false},
+				{"Size": 300 << 20, "InUse": true, "Shared": false},
+			},
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		reply(map[string]string{"message": "page not found"})
+	}
+}
+
+// startFakeEngine listens on a unix socket and returns its path.
+func startFakeEngine(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	// Socket paths are limited to about 100 bytes, too short for some TempDirs.
+	dir, err := os.MkdirTemp("", "mole-docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Listener = listener
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return socket
+}
+
+func noEnv(string) string { return "" }
+
+func TestResolveDockerEndpoint(t *testing.T) {
+	home := t.TempDir()
+	socket := filepath.Join(home, ".colima", "default", "docker.sock")
+	if err := os.MkdirAll(filepath.Dir(socket), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	env := func(key string) string {
+		if key == "DOCKER_HOST" {
+			return "tcp://10.0.0.2:2375"
+		}
+		return ""
+	}
+	tests := []struct {
+		flag         string
+		getenv       func(string) string
+		createSocket bool
+		want         string
+		wantExplicit bool
+	}{
+		{"unix:///tmp/podman.sock", env, false, "unix:///tmp/podman.sock", true},
+		{"", env, false, "tcp://10.0.0.2:2375", true},
+		{"", noEnv, false, "", false},
+		{"", noEnv, true, "unix://" + socket, false},
+	}
+	for _, tt := range tests {
+		if tt.createSocket {
+			if err := os.WriteFile(socket, nil, 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got, explicit := resolveDockerEndpoint(tt.flag, tt.getenv, home)
+		// /var/run/docker.sock may exist on the machine running the tests.
+		if got != tt.want && !(tt.want == "" && got == "unix:///var/run/docker.sock") {
+			t.Errorf("resolveDockerEndpoint(%q) = %q, want %q", tt.flag, got, tt.want)
+		}
+		if got == tt.want && explicit != tt.wantExplicit {
+			t.Errorf("resolveDockerEndpoint(%q) explicit = %v", tt.flag, explicit)
+		}
+	}
+}
+
+func TestDockerPipeEndpoints(t *testing.T) {
+	candidates := dockerSocketCandidates("windows", noEnv, `C:\Users\me`)
+	if len(candidates) == 0 || candidates[0] != "npipe:////./pipe/docker_engine" {
+		t.Fatalf("windows candidates = %v", candidates)
+	}
+	if got := dockerPipePath(candidates[0]); got != `\\.\pipe\docker_engine` {
+		t.Errorf("dockerPipePath = %q", got)
+	}
+	if _, err := newDockerClient("npipe:////./pipe/docker_engine"); err != nil {
+		t.Errorf("npipe endpoints should be accepted: %v", err)
+	}
+}
+
+func TestDockerSamplerOverUnixSocket(t *testing.T) {
+	socket := startFakeEngine(t, &fakeEngine{calls: make(map[string]int)})
+	s := &dockerSampler{flagValue: "unix://" + socket, getenv: noEnv}
+	ctx := context.Background()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	first, err := s.Sample(ctx, at)
+	if err != nil {
+		t.Fatalf("Sample: %v", err)
+	}
+	if first.Engine != "podman" || first.Version != "5.2.0" || len(first.Containers) != 2 {
+		t.Fatalf("first sample = %+v", first)
+	}
+	if first.Containers[0].CPU != 0 || first.Containers[0].NetRxRate != 0 {
+		t.Errorf("the first one-shot sample has nothing to diff against: %+v", first.Containers[0])
+	}
+
+	second, err := s.Sample(ctx, at.Add(2*time.Second))
+	if err != nil {
+		t.Fatalf("Sample: %v", err)
+	}
+	web, db := second.Containers[0], second.Containers[1]
+	if web.Name != "web" || web.ID != "web000000000" || db.Name != "postgres" {
+		t.Fatalf("containers should be sorted by CPU: %+v", second.Containers)
+	}
+	if web.CPU != 100 || web.MemoryUsed != 256<<20 || web.MemoryLimit != 1<<30 {
+		t.Errorf("web cpu/memory = %+v", web)
+	}
+	if web.NetRxRate != 1 || web.NetTxRate != 0.5 || web.BlockReadRate != 2 || web.BlockWriteRate != 0 {
+		t.Errorf("web rates = %+v", web)
+	}
+	if db.CPU != 20 || db.MemoryUsed != 512<<20 {
+		t.Errorf("postgres (cgroup v1 stats) = %+v", db)
+	}
+
+	usage, err := s.DiskUsage(ctx)
+	if err != nil {
+		t.Fatalf("DiskUsage: %v", err)
+	}
+	wantImages := DockerUsage{Count: 2, Size: 3 << 30, Reclaimable: 1 << 30}
+	wantVolumes := DockerUsage{Count: 3, Size: 1280 << 20, Reclaimable: 256 << 20}
+	wantCache := DockerUsage{Count: 2, Size: 1000 << 20, Reclaimable: 700 << 20}
+	if usage.images != wantImages || usage.volumes != wantVolumes || usage.buildCache != wantCache {
+		t.Errorf("disk usage = %+v", *usage)
+	}
+}
+
+func TestDockerSamplerErrors(t *testing.T) {
+	broken := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"message":"daemon is shutting down"}`))
+	})
+	socket := startFakeEngine(t, broken)
+	ctx := context.Background()
+
+	configured := &dockerSampler{flagValue: socket, getenv: noEnv}
+	if _, err := configured.Sample(ctx, time.Now()); err == nil || !strings.Contains(err.Error(), "daemon is shutting down") {
+		t.Errorf("a configured endpoint should report its error, got %v", err)
+	}
+
+	// An auto-detected engine that is failing or stopped just hides the card.
+	detected := &dockerSampler{getenv: func(key string) string {
+		if key == "XDG_RUNTIME_DIR" {
+			return filepath.Dir(socket)
+		}
+		return ""
+	}}
+	if !fileExists("/var/run/docker.sock") { // A real engine socket takes precedence.
+		if status, err := detected.Sample(ctx, time.Now()); status != nil || err != nil {
+			t.Errorf("failing auto-detected engine = %+v, %v", status, err)
+		}
+	}
+
+	if _, err := newDockerClient("ssh://build-host"); err == nil {
+		t.Errorf("ssh endpoints should be rejected")
+	}
+}
+
+func TestDockerCard(t *testing.T) {
+	card := renderDockerCard(DockerStatus{
+		Engine: "docker",
+		Containers: []DockerContainer{
+			{Name: "web", CPU: 100, MemoryUsed: 256 << 20, NetRxRate: 1, BlockReadRate: 2},
+			{Name: "postgres", CPU: 20, MemoryUsed: 512 << 20, NetTxRate: 0.5},
+		},
+		Images:  DockerUsage{Count: 2, Size: 3 << 30, Reclaimable: 1 << 30},
+		Volumes: DockerUsage{Count: 1, Size: 1 << 30},
+	})
+	got := ansiRe.ReplaceAllString(strings.Join(card.lines, "\n"), "")
+	for _, want := range []string{
+		"Running 2 · CPU 120.0% · Mem 768.0M",
+		"web           ▮▮▮▮▮  100.0%  256.0M",
+		"Net    ↓ 1.0  ↑ 0.5 MB/s",
+		"Block  R 2.0  W 0.0 MB/s",
+		"Images 3.0G  2 · 1.0G reclaimable",
+		"Volume 1.0G  1 · 0 reclaimable",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("card missing %q:\n%s", want, got)
+		}
+	}
+	if card.title != "Docker" || strings.Contains(got, "Cache") {
+		t.Errorf("unexpected card %q:\n%s", card.title, got)
+	}
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"context"
+	"net"
+
+	"github.com/Microsoft/go-winio"
+)
+
+// dialDockerPipe connects to the Engine API on a Windows named pipe.
+func dialDockerPipe(ctx context.Context, pipe string) (net.Conn, error) {
+	return winio.DialPipeContext(ctx, pipe)
+}
@@ -13,7 +13,7 @@ import (
 
 const (
 	systemProfilerTimeout = 4 * time.Second
-	macGPUInfoTTL         = 10 * time.Minute
+	macGPUInfoTTL         = 10 * time.Minute // gpu_info provider interval
 	powermetricsTimeout   = 2 * time.Second
 )
 
@@ -23,26 +23,13 @@ var (
 	gpuIdleResidencyRe   = regexp.MustCompile(`GPU idle residency:\s+([\d.]+)%`)
 )
 
-func (c *Collector) collectGPU(now time.Time) ([]GPUStatus, error) {
-	if runtime.GOOS == "darwin" {
-		// Static GPU info (cached 10 min).
-		if len(c.cachedGPU) == 0 || c.lastGPUAt.IsZero() || now.Sub(c.lastGPUAt) >= macGPUInfoTTL {
-			if gpus, err := readMacGPUInfo(); err == nil && len(gpus) > 0 {
-				c.cachedGPU = gpus
-				c.lastGPUAt = now
-			}
-		}
-
-		// Real-time GPU usage.
-		if len(c.cachedGPU) > 0 {
-			usage := getMacGPUUsage()
-			result := make([]GPUStatus, len(c.cachedGPU))
-			copy(result, c.cachedGPU)
-			// Apply usage to first GPU (Apple Silicon).
-			if len(result) > 0 {
-				result[0].Usage = usage
-			}
-			return result, nil
+// collectGPU reads GPUs outside macOS, where gpu_info and powermetrics are used instead.
+// It returns nil when no source is available, so the GPU card stays hidden.
+func collectGPU() ([]GPUStatus, error) {
+	// amdgpu and some Intel drivers report busy percent in sysfs.
+	if runtime.GOOS == "linux" {
+		if gpus := readDRMGPUs(hostRoot); len(gpus) > 0 {
+			return gpus, nil
 		}
 	}
 
@@ -50,10 +37,7 @@ func (c *Collector) collectGPU(now time.Time) ([]GPUStatus, error) {
 	defer cancel()
 
 	if !commandExists("nvidia-smi") {
-		return []GPUStatus{{
-			Name: "No GPU metrics available",
-			Note: "Install nvidia-smi or use platform-specific metrics",
-		}}, nil
+		return nil, nil
 	}
 
 	out, err := runCmd(ctx, "nvidia-smi", "--query-gpu=utilization.gpu,memory.used,memory.total,name", "--format=csv,noheader,nounits")
@@ -82,8 +66,9 @@ func (c *Collector) collectGPU(now time.Time) ([]GPUStatus, error) {
 
 	if len(gpus) == 0 {
 		return []GPUStatus{{
-			Name: "GPU read failed",
-			Note: "Verify nvidia-smi availability",
+			Name:  "GPU read failed",
+			Usage: -1,
+			Note:  "Verify nvidia-smi availability",
 		}}, nil
 	}
 
@@ -8,25 +8,28 @@ import (
 	"time"
 )
 
-func collectHardware(totalRAM uint64, disks []DiskStatus) HardwareInfo {
+// collectHardware reads the static machine description. TotalRAM and
+// DiskSize are filled in by the hardware provider from the live snapshot.
+func collectHardware(ctx context.Context) HardwareInfo {
+	if runtime.GOOS == "linux" {
+		return linuxHardware(hostRoot)
+	}
 	if runtime.GOOS != "darwin" {
 		return HardwareInfo{
 			Model:       "Unknown",
 			CPUModel:    runtime.GOARCH,
-			TotalRAM:    humanBytes(totalRAM),
-			DiskSize:    "Unknown",
 			OSVersion:   runtime.GOOS,
 			RefreshRate: "",
 		}
 	}
 
 	// Model and CPU from system_profiler.
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx1, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	var model, cpuModel, osVersion, refreshRate string
 
-	out, err := runCmd(ctx, "system_profiler", "SPHardwareDataType")
+	out, err := runCmd(ctx1, "system_profiler", "SPHardwareDataType")
 	if err == nil {
 		for line := range strings.Lines(out) {
 			lower := strings.ToLower(strings.TrimSpace(line))
@@ -52,7 +55,7 @@ func collectHardware(totalRAM uint64, disks []DiskStatus) HardwareInfo {
 		}
 	}
 
-	ctx2, cancel2 := context.WithTimeout(context.Background(), 1*time.Second)
+	ctx2, cancel2 := context.WithTimeout(ctx, 1*time.Second)
 	defer cancel2()
 	out2, err := runCmd(ctx2, "sw_vers", "-productVersion")
 	if err == nil {
@@ -60,28 +63,41 @@ func collectHardware(totalRAM uint64, disks []DiskStatus) HardwareInfo {
 	}
 
 	// Get refresh rate from display info (use mini detail to keep it fast).
-	ctx3, cancel3 := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx3, cancel3 := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel3()
 	out3, err := runCmd(ctx3, "system_profiler", "-detailLevel", "mini", "SPDisplaysDataType")
 	if err == nil {
 		refreshRate = parseRefreshRate(out3)
 	}
 
-	diskSize := "Unknown"
-	if len(disks) > 0 {
-		diskSize = humanBytes(disks[0].Total)
-	}
-
 	return HardwareInfo{
 		Model:       model,
 		CPUModel:    cpuModel,
-		TotalRAM:    humanBytes(totalRAM),
-		DiskSize:    diskSize,
 		OSVersion:   osVersion,
 		RefreshRate: refreshRate,
 	}
 }
 
+// linuxHardware reads the machine model from DMI, the CPU from /proc/cpuinfo
+// and the distribution from os-release.
+func linuxHardware(root string) HardwareInfo {
+	info := HardwareInfo{
+		Model:     readDMIProductName(root),
+		CPUModel:  readCPUModel(root),
+		OSVersion: readOSRelease(root),
+	}
+	if info.Model == "" {
+		info.Model = "Unknown"
+	}
+	if info.CPUModel == "" {
+		info.CPUModel = runtime.GOARCH
+	}
+	if info.OSVersion == "" {
+		info.OSVersion = "Linux"
+	}
+	return info
+}
+
 // parseRefreshRate extracts the highest refresh rate from system_profiler display output.
 func parseRefreshRate(output string) string {
 	maxHz := 0
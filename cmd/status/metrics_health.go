@@ -3,133 +3,131 @@ package main
 import (
 	"fmt"
 	"strings"
-)
 
-// Health score weights and thresholds.
-const (
-	// Weights.
-	healthCPUWeight     = 30.0
-	healthMemWeight     = 25.0
-	healthDiskWeight    = 20.0
-	healthThermalWeight = 15.0
-	healthIOWeight      = 10.0
-
-	// CPU.
-	cpuNormalThreshold = 30.0
-	cpuHighThreshold   = 70.0
-
-	// Memory.
-	memNormalThreshold     = 50.0
-	memHighThreshold       = 80.0
-	memPressureWarnPenalty = 5.0
-	memPressureCritPenalty = 15.0
-
-	// Disk.
-	diskWarnThreshold = 70.0
-	diskCritThreshold = 90.0
-
-	// Thermal.
-	thermalNormalThreshold = 60.0
-	thermalHighThreshold   = 85.0
-
-	// Disk IO (MB/s).
-	ioNormalThreshold = 50.0
-	ioHighThreshold   = 150.0
+	"github.com/tw93/mole/internal/prefs"
 )
 
-func calculateHealthScore(cpu CPUStatus, mem MemoryStatus, disks []DiskStatus, diskIO DiskIOStatus, thermal ThermalStatus) (int, string) {
-	score := 100.0
-	issues := []string{}
-
-	// CPU penalty.
-	cpuPenalty := 0.0
-	if cpu.Usage > cpuNormalThreshold {
-		if cpu.Usage > cpuHighThreshold {
-			cpuPenalty = healthCPUWeight * (cpu.Usage - cpuNormalThreshold) / cpuHighThreshold
-		} else {
-			cpuPenalty = (healthCPUWeight / 2) * (cpu.Usage - cpuNormalThreshold) / (cpuHighThreshold - cpuNormalThreshold)
-		}
+// healthSettings are the health score weights and thresholds from the
+// preferences (thresholds.health).
+var healthSettings = prefs.Defaults().Thresholds.Health
+
+// healthModel holds the weights and thresholds behind the health score.
+type healthModel struct {
+	prefs.Health
+}
+
+func defaultHealthModel() healthModel {
+	return healthModel{prefs.Defaults().Thresholds.Health}
+}
+
+// HealthComponent is what one subsystem cost the health score.
+type HealthComponent struct {
+	Name    string  `json:"name"` // cpu, memory, memory_pressure, disk, thermal or io
+	Value   float64 `json:"value"`
+	Penalty float64 `json:"penalty"` // Points deducted
+	Weight  float64 `json:"weight"`  // Most points it could deduct
+	Detail  string  `json:"detail,omitempty"`
+	Issue   string  `json:"issue,omitempty"`
+}
+
+// percentPenalty is the curve for values that top out at 100.
+func percentPenalty(l prefs.HealthLimits, v float64) float64 {
+	switch {
+	case v <= l.Normal:
+		return 0
+	case v <= l.High:
+		return (l.Weight / 2) * (v - l.Normal) / (l.High - l.Normal)
+	case l.Normal >= 100:
+		return l.Weight
+	default:
+		return min(l.Weight, l.Weight*(v-l.Normal)/(100-l.Normal))
 	}
-	score -= cpuPenalty
-	if cpu.Usage > cpuHighThreshold {
-		issues = append(issues, "High CPU")
+}
+
+// rampPenalty is the curve for unbounded values such as temperatures and rates.
+func rampPenalty(l prefs.HealthLimits, v float64) float64 {
+	switch {
+	case v <= l.Normal:
+		return 0
+	case v > l.High:
+		return l.Weight
+	default:
+		return l.Weight * (v - l.Normal) / (l.High - l.Normal)
 	}
+}
 
-	// Memory penalty.
-	memPenalty := 0.0
-	if mem.UsedPercent > memNormalThreshold {
-		if mem.UsedPercent > memHighThreshold {
-			memPenalty = healthMemWeight * (mem.UsedPercent - memNormalThreshold) / memNormalThreshold
-		} else {
-			memPenalty = (healthMemWeight / 2) * (mem.UsedPercent - memNormalThreshold) / (memHighThreshold - memNormalThreshold)
+// diskLimits returns the limits for a mount, or false when it is not scored.
+func (hm healthModel) diskLimits(d DiskStatus) (prefs.HealthLimits, bool) {
+	for _, r := range hm.DiskRules {
+		if r.Mount == d.Mount {
+			return prefs.HealthLimits{Weight: hm.Disk.Weight, Normal: r.Normal, High: r.High}, !r.Ignore
 		}
 	}
-	score -= memPenalty
-	if mem.UsedPercent > memHighThreshold {
-		issues = append(issues, "High Memory")
+	return hm.Disk, hm.ExternalDisks || !d.External
+}
+
+// score computes the health score, its message and the per-component breakdown.
+func (hm healthModel) score(cpu CPUStatus, mem MemoryStatus, disks []DiskStatus, diskIO DiskIOStatus, thermal ThermalStatus) (int, string, []HealthComponent) {
+	var components []HealthComponent
+	add := func(c HealthComponent, flagged bool, issue string) {
+		if flagged {
+			c.Issue = issue
+		}
+		components = append(components, c)
 	}
 
-	// Memory pressure penalty.
+	add(HealthComponent{Name: "cpu", Value: cpu.Usage, Penalty: percentPenalty(hm.CPU, cpu.Usage), Weight: hm.CPU.Weight},
+		cpu.Usage > hm.CPU.High, "High CPU")
+	add(HealthComponent{Name: "memory", Value: mem.UsedPercent, Penalty: percentPenalty(hm.Memory, mem.UsedPercent), Weight: hm.Memory.Weight},
+		mem.UsedPercent > hm.Memory.High, "High Memory")
+
+	pressure := HealthComponent{Name: "memory_pressure", Weight: hm.MemoryPressure.Critical, Detail: mem.Pressure}
 	switch mem.Pressure {
 	case "warn":
-		score -= memPressureWarnPenalty
-		issues = append(issues, "Memory Pressure")
+		pressure.Penalty = hm.MemoryPressure.Warn
+		pressure.Issue = "Memory Pressure"
 	case "critical":
-		score -= memPressureCritPenalty
-		issues = append(issues, "Critical Memory")
+		pressure.Penalty = hm.MemoryPressure.Critical
+		pressure.Issue = "Critical Memory"
 	}
-
-	// Disk penalty.
-	diskPenalty := 0.0
-	if len(disks) > 0 {
-		diskUsage := disks[0].UsedPercent
-		if diskUsage > diskWarnThreshold {
-			if diskUsage > diskCritThreshold {
-				diskPenalty = healthDiskWeight * (diskUsage - diskWarnThreshold) / (100 - diskWarnThreshold)
-			} else {
-				diskPenalty = (healthDiskWeight / 2) * (diskUsage - diskWarnThreshold) / (diskCritThreshold - diskWarnThreshold)
-			}
+	components = append(components, pressure)
+
+	// The worst scored mount stands for the disk component.
+	var worstDisk *HealthComponent
+	for _, d := range disks {
+		limits, ok := hm.diskLimits(d)
+		if !ok {
+			continue
 		}
-		score -= diskPenalty
-		if diskUsage > diskCritThreshold {
-			issues = append(issues, "Disk Almost Full")
+		c := HealthComponent{Name: "disk", Value: d.UsedPercent, Penalty: percentPenalty(limits, d.UsedPercent), Weight: limits.Weight, Detail: d.Mount}
+		if d.UsedPercent > limits.High {
+			c.Issue = "Disk Almost Full"
 		}
+		if worstDisk == nil || c.Penalty > worstDisk.Penalty || (c.Penalty == worstDisk.Penalty && c.Issue != "" && worstDisk.Issue == "") {
+			worstDisk = &c
+		}
+	}
+	if worstDisk != nil {
+		components = append(components, *worstDisk)
 	}
 
-	// Thermal penalty.
-	thermalPenalty := 0.0
 	if thermal.CPUTemp > 0 {
-		if thermal.CPUTemp > thermalNormalThreshold {
-			if thermal.CPUTemp > thermalHighThreshold {
-				thermalPenalty = healthThermalWeight
-				issues = append(issues, "Overheating")
-			} else {
-				thermalPenalty = healthThermalWeight * (thermal.CPUTemp - thermalNormalThreshold) / (thermalHighThreshold - thermalNormalThreshold)
-			}
-		}
-		score -= thermalPenalty
+		add(HealthComponent{Name: "thermal", Value: thermal.CPUTemp, Penalty: rampPenalty(hm.Thermal, thermal.CPUTemp), Weight: hm.Thermal.Weight},
+			thermal.CPUTemp > hm.Thermal.High, "Overheating")
 	}
-
-	// Disk IO penalty.
-	ioPenalty := 0.0
 	totalIO := diskIO.ReadRate + diskIO.WriteRate
-	if totalIO > ioNormalThreshold {
-		if totalIO > ioHighThreshold {
-			ioPenalty = healthIOWeight
-			issues = append(issues, "Heavy Disk IO")
-		} else {
-			ioPenalty = healthIOWeight * (totalIO - ioNormalThreshold) / (ioHighThreshold - ioNormalThreshold)
-		}
-	}
-	score -= ioPenalty
+	add(HealthComponent{Name: "io", Value: totalIO, Penalty: rampPenalty(hm.IO, totalIO), Weight: hm.IO.Weight},
+		totalIO > hm.IO.High, "Heavy Disk IO")
 
-	// Clamp score.
-	if score < 0 {
-		score = 0
-	}
-	if score > 100 {
-		score = 100
+	score := 100.0
+	var issues []string
+	for _, c := range components {
+		score -= c.Penalty
+		if c.Issue != "" {
+			issues = append(issues, c.Issue)
+		}
 	}
+	score = min(max(score, 0), 100)
 
 	// Build message.
 	var msg string
@@ -150,7 +148,13 @@ func calculateHealthScore(cpu CPUStatus, mem MemoryStatus, disks []DiskStatus, d
 		msg = msg + ": " + strings.Join(issues, ", ")
 	}
 
-	return int(score), msg
+	return int(score), msg, components
+}
+
+// calculateHealthScore scores with the default model.
+func calculateHealthScore(cpu CPUStatus, mem MemoryStatus, disks []DiskStatus, diskIO DiskIOStatus, thermal ThermalStatus) (int, string) {
+	score, msg, _ := defaultHealthModel().score(cpu, mem, disks, diskIO, thermal)
+	return score, msg
 }
 
 func formatUptime(secs uint64) string {
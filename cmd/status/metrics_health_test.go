@@ -3,6 +3,8 @@ package main
 import (
 	"strings"
 	"testing"
+
+	"github.com/tw93/mole/internal/prefs"
 )
 
 func TestCalculateHealthScorePerfect(t *testing.T) {
@@ -194,3 +196,64 @@ func TestFormatUptimeEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestHealthModelBreakdown(t *testing.T) {
+	model := defaultHealthModel()
+	model.DiskRules = []prefs.DiskRule{
+		{Mount: "/Volumes/Backup", Ignore: true},
+		{Mount: "/data", Normal: 95, High: 98},
+	}
+	disks := []DiskStatus{
+		{Mount: "/", UsedPercent: 80},
+		{Mount: "/data", UsedPercent: 96},
+		{Mount: "/Volumes/Backup", UsedPercent: 99},
+		{Mount: "/Volumes/USB", UsedPercent: 99, External: true},
+	}
+	score, msg, breakdown := model.score(
+		CPUStatus{Usage: 10},
+		MemoryStatus{UsedPercent: 20, Pressure: "warn"},
+		disks,
+		DiskIOStatus{},
+		ThermalStatus{},
+	)
+
+	costs := map[string]HealthComponent{}
+	total := 0.0
+	for _, c := range breakdown {
+		costs[c.Name] = c
+		total += c.Penalty
+	}
+	if int(100-total) != score {
+		t.Errorf("breakdown costs %.1f points but score is %d", total, score)
+	}
+	// "/" at 80% costs 5 of 20 points; "/data" at 96% under its rule costs less.
+	if d := costs["disk"]; d.Detail != "/" || d.Penalty != 5 {
+		t.Errorf("disk component = %+v, want / costing 5", d)
+	}
+	if p := costs["memory_pressure"]; p.Penalty != model.MemoryPressure.Warn || p.Issue != "Memory Pressure" {
+		t.Errorf("memory_pressure component = %+v", p)
+	}
+	if _, ok := costs["thermal"]; ok {
+		t.Errorf("thermal should be skipped without a reading")
+	}
+	if strings.Contains(msg, "Disk Almost Full") {
+		t.Errorf("ignored and external disks should not be flagged: %q", msg)
+	}
+
+	model.ExternalDisks = true
+	if _, msg, _ := model.score(CPUStatus{}, MemoryStatus{}, disks, DiskIOStatus{}, ThermalStatus{}); !strings.Contains(msg, "Disk Almost Full") {
+		t.Errorf("external disks should count when enabled: %q", msg)
+	}
+}
+
+func TestHealthCostText(t *testing.T) {
+	got := healthCostText([]HealthComponent{
+		{Name: "cpu", Penalty: 3.4},
+		{Name: "memory_pressure", Penalty: 5},
+		{Name: "disk", Detail: "/data", Penalty: 12},
+		{Name: "io", Penalty: 0.2},
+	})
+	if want := "disk /data −12, memory pressure −5, cpu −3"; got != want {
+		t.Errorf("healthCostText = %q, want %q", got, want)
+	}
+}
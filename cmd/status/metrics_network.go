@@ -2,9 +2,13 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
+	"net/netip"
 	"os"
+	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -42,19 +46,22 @@ func (c *Collector) collectNetwork(now time.Time) ([]NetworkStatus, error) {
 		if !ok {
 			continue
 		}
-		rx := float64(cur.BytesRecv-prev.BytesRecv) / 1024.0 / 1024.0 / elapsed
-		tx := float64(cur.BytesSent-prev.BytesSent) / 1024.0 / 1024.0 / elapsed
-		if rx < 0 {
-			rx = 0
-		}
-		if tx < 0 {
-			tx = 0
+		addrs := ifAddrs[cur.Name]
+		speed := 0
+		if runtime.GOOS == "linux" {
+			speed = readLinkSpeed(hostRoot, cur.Name)
 		}
 		result = append(result, NetworkStatus{
 			Name:      cur.Name,
-			RxRateMBs: rx,
-			TxRateMBs: tx,
-			IP:        ifAddrs[cur.Name],
+			RxRateMBs: byteRate(prev.BytesRecv, cur.BytesRecv, elapsed),
+			TxRateMBs: byteRate(prev.BytesSent, cur.BytesSent, elapsed),
+			IP:        addrs.v4,
+			IPv6:      addrs.v6,
+			SpeedMbps: speed,
+			ErrorsIn:  cur.Errin,
+			ErrorsOut: cur.Errout,
+			DropsIn:   cur.Dropin,
+			DropsOut:  cur.Dropout,
 		})
 	}
 
@@ -64,11 +71,11 @@ func (c *Collector) collectNetwork(now time.Time) ([]NetworkStatus, error) {
 	}
 
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].RxRateMBs+result[i].TxRateMBs > result[j].RxRateMBs+result[j].TxRateMBs
+		if a, b := result[i].RxRateMBs+result[i].TxRateMBs, result[j].RxRateMBs+result[j].TxRateMBs; a != b {
+			return a > b
+		}
+		return result[i].Name < result[j].Name
 	})
-	if len(result) > 3 {
-		result = result[:3]
-	}
 
 	var totalRx, totalTx float64
 	for _, r := range result {
@@ -79,32 +86,100 @@ func (c *Collector) collectNetwork(now time.Time) ([]NetworkStatus, error) {
 	// Update history using the global/aggregated stats
 	c.rxHistoryBuf.Add(totalRx)
 	c.txHistoryBuf.Add(totalTx)
+	c.ifaceHistory.Add(result)
 
 	return result, nil
 }
 
-func getInterfaceIPs() map[string]string {
-	result := make(map[string]string)
+// interfaceHistory keeps rate history per interface. Interfaces that go away
+// are dropped so hot-plugged adapters do not accumulate.
+type interfaceHistory struct {
+	rx, tx map[string]*RingBuffer
+}
+
+func newInterfaceHistory() *interfaceHistory {
+	return &interfaceHistory{rx: make(map[string]*RingBuffer), tx: make(map[string]*RingBuffer)}
+}
+
+func (h *interfaceHistory) Add(stats []NetworkStatus) {
+	seen := make(map[string]bool, len(stats))
+	for _, n := range stats {
+		seen[n.Name] = true
+		if h.rx[n.Name] == nil {
+			h.rx[n.Name] = NewRingBuffer(NetworkHistorySize)
+			h.tx[n.Name] = NewRingBuffer(NetworkHistorySize)
+		}
+		h.rx[n.Name].Add(n.RxRateMBs)
+		h.tx[n.Name].Add(n.TxRateMBs)
+	}
+	for name := range h.rx {
+		if !seen[name] {
+			delete(h.rx, name)
+			delete(h.tx, name)
+		}
+	}
+}
+
+// Snapshot copies every interface's history.
+func (h *interfaceHistory) Snapshot() map[string]NetworkHistory {
+	if len(h.rx) == 0 {
+		return nil
+	}
+	out := make(map[string]NetworkHistory, len(h.rx))
+	for name, rx := range h.rx {
+		out[name] = NetworkHistory{RxHistory: rx.Slice(), TxHistory: h.tx[name].Slice()}
+	}
+	return out
+}
+
+// interfaceAddrs is the first IPv4 and IPv6 address of an interface.
+type interfaceAddrs struct {
+	v4, v6 string
+}
+
+func getInterfaceIPs() map[string]interfaceAddrs {
+	result := make(map[string]interfaceAddrs)
 	ifaces, err := net.Interfaces()
 	if err != nil {
 		return result
 	}
 	for _, iface := range ifaces {
+		var addrs interfaceAddrs
 		for _, addr := range iface.Addrs {
-			// IPv4 only.
-			if strings.Contains(addr.Addr, ".") && !strings.HasPrefix(addr.Addr, "127.") {
-				ip := strings.Split(addr.Addr, "/")[0]
-				result[iface.Name] = ip
-				break
+			ip, _, _ := strings.Cut(addr.Addr, "/")
+			switch {
+			case strings.Contains(ip, "."):
+				if addrs.v4 == "" && !strings.HasPrefix(ip, "127.") {
+					addrs.v4 = ip
+				}
+			case addrs.v6 == "" && isGlobalIPv6(ip):
+				addrs.v6 = ip
 			}
 		}
+		result[iface.Name] = addrs
 	}
 	return result
 }
 
+// isGlobalIPv6 skips loopback and link-local addresses, which every interface has.
+func isGlobalIPv6(ip string) bool {
+	lower := strings.ToLower(ip)
+	return lower != "::1" && !strings.HasPrefix(lower, "fe80:")
+}
+
+// readLinkSpeed returns the negotiated speed in Mb/s from sysfs, or 0 when
+// unknown (Wi-Fi, virtual interfaces, links that are down).
+func readLinkSpeed(root, name string) int {
+	speed, ok := readSysInt(filepath.Join(root, "sys/class/net", name, "speed"))
+	if !ok || speed <= 0 {
+		return 0
+	}
+	return int(speed)
+}
+
 func isNoiseInterface(name string) bool {
 	lower := strings.ToLower(name)
-	noiseList := []string{"lo", "awdl", "utun", "llw", "bridge", "gif", "stf", "xhc", "anpi", "ap"}
+	noiseList := []string{"lo", "awdl", "utun", "llw", "bridge", "gif", "stf", "xhc", "anpi", "ap", "veth"}
 	for _, prefix := range noiseList {
 		if strings.HasPrefix(lower, prefix) {
 			return true
@@ -150,3 +225,116 @@ func collectProxy() ProxyStatus {
 
 	return ProxyStatus{Enabled: false}
 }
+
+// collectNetworkConfig reads the default gateway and DNS resolvers.
+func collectNetworkConfig(ctx context.Context) NetworkConfig {
+	var cfg NetworkConfig
+	switch runtime.GOOS {
+	case "linux":
+		cfg.Gateway, cfg.GatewayInterface = readLinuxGateway(hostRoot)
+		cfg.Gateway6 = readLinuxGateway6(hostRoot)
+	case "darwin":
+		if out, err := runCmd(ctx, "route", "-n", "get", "default"); err == nil {
+			cfg.Gateway, cfg.GatewayInterface = parseRouteGet(out)
+		}
+	}
+	cfg.DNS, cfg.SearchDomains = readDNS(hostRoot)
+	return cfg
+}
+
+// readLinuxGateway finds the IPv4 default route with the lowest metric in /proc/net/route.
+func readLinuxGateway(root string) (gateway, iface string) {
+	data, err := os.ReadFile(filepath.Join(root, "proc/net/route"))
+	if err != nil {
+		return "", ""
+	}
+	const rtfGateway = 0x2
+	best := int64(-1)
+	for line := range strings.Lines(string(data)) {
+		// Iface Destination Gateway Flags RefCnt Use Metric Mask ...
+		fields := strings.Fields(line)
+		if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+		flags, _ := strconv.ParseUint(fields[3], 16, 32)
+		hop, err := strconv.ParseUint(fields[2], 16, 32)
+		metric, _ := strconv.ParseInt(fields[6], 10, 64)
+		if err != nil || flags&rtfGateway == 0 || (best >= 0 && metric >= best) {
+			continue
+		}
+		// The address is in host byte order, little-endian on every Linux we run on.
+		addr := netip.AddrFrom4([4]byte{byte(hop), byte(hop >> 8), byte(hop >> 16), byte(hop >> 24)})
+		gateway, iface, best = addr.String(), fields[0], metric
+	}
+	return gateway, iface
+}
+
+// readLinuxGateway6 finds the IPv6 default route in /proc/net/ipv6_route.
+func readLinuxGateway6(root string) string {
+	data, err := os.ReadFile(filepath.Join(root, "proc/net/ipv6_route"))
+	if err != nil {
+		return ""
+	}
+	const anyAddr = "00000000000000000000000000000000"
+	for line := range strings.Lines(string(data)) {
+		// Destination prefix source prefix next-hop metric refcnt use flags iface
+		fields := strings.Fields(line)
+		if len(fields) < 10 || fields[0] != anyAddr || fields[1] != "00" || fields[4] == anyAddr || fields[9] == "lo" {
+			continue
+		}
+		raw, err := hex.DecodeString(fields[4])
+		if err != nil || len(raw) != 16 {
+			continue
+		}
+		return netip.AddrFrom16([16]byte(raw)).String()
+	}
+	return ""
+}
+
+// parseRouteGet reads the gateway from macOS "route -n get default".
+func parseRouteGet(out string) (gateway, iface string) {
+	for line := range strings.Lines(out) {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "gateway":
+			gateway = strings.TrimSpace(value)
+		case "interface":
+			iface = strings.TrimSpace(value)
+		}
+	}
+	return gateway, iface
+}
+
+// readDNS lists resolvers from resolv.conf. Behind systemd-resolved the file
+// only names the local stub, so the upstream servers are read instead.
+func readDNS(root string) (servers, search []string) {
+	data, _ := os.ReadFile(filepath.Join(root, "etc/resolv.conf"))
+	servers, search = parseResolvConf(string(data))
+	if len(servers) == 1 && (servers[0] == "127.0.0.53" || servers[0] == "127.0.0.54") {
+		if upstream, err := os.ReadFile(filepath.Join(root, "run/systemd/resolve/resolv.conf")); err == nil {
+			if s, d := parseResolvConf(string(upstream)); len(s) > 0 {
+				return s, d
+			}
+		}
+	}
+	return servers, search
+}
+
+func parseResolvConf(data string) (servers, search []string) {
+	for line := range strings.Lines(data) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			servers = append(servers, fields[1])
+		case "search", "domain":
+			search = append(search, fields[1:]...)
+		}
+	}
+	return servers, search
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	stdnet "net"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestReadLinuxRoutesAndDNS(t *testing.T) {
+	gw, iface := readLinuxGateway("testdata/linux/desktop")
+	if gw != "192.168.1.1" || iface != "eno1" {
+		t.Errorf("gateway = %s (%s), want the lowest metric route 192.168.1.1 (eno1)", gw, iface)
+	}
+	if gw6 := readLinuxGateway6("testdata/linux/desktop"); gw6 != "fe80::1" {
+		t.Errorf("IPv6 gateway = %q", gw6)
+	}
+
+	tests := []struct {
+		root        string
+		wantServers []string
+		wantSearch  []string
+	}{
+		// systemd-resolved's stub is replaced by the upstream servers.
+		{"testdata/linux/desktop", []string{"192.168.1.1", "2001:db8::1"}, []string{"lan"}},
+		{"testdata/linux/laptop", []string{"10.0.0.2", "10.0.0.3"}, []string{"corp.example"}},
+		{"testdata/cgroup/wsl", nil, nil},
+	}
+	for _, tt := range tests {
+		servers, search := readDNS(tt.root)
+		if !slices.Equal(servers, tt.wantServers) || !slices.Equal(search, tt.wantSearch) {
+			t.Errorf("readDNS(%s) = %v, %v", tt.root, servers, search)
+		}
+	}
+
+	if got := readLinkSpeed("testdata/linux/desktop", "eno1"); got != 1000 {
+		t.Errorf("eno1 speed = %d", got)
+	}
+	if got := readLinkSpeed("testdata/linux/desktop", "wlp3s0"); got != 0 {
+		t.Errorf("Wi-Fi reports -1, want 0, got %d", got)
+	}
+}
+
+func TestParseRouteGet(t *testing.T) {
+	out := `   route to: default
+destination: default
+       mask: default
+    gateway: 192.168.1.1
+  interface: en0
+      flags: <UP,GATEWAY,DONE,STATIC,PRCLONING,GLOBAL>
+`
+	if gw, iface := parseRouteGet(out); gw != "192.168.1.1" || iface != "en0" {
+		t.Errorf("parseRouteGet = %q, %q", gw, iface)
+	}
+}
+
+func TestInterfaceHistory(t *testing.T) {
+	h := newInterfaceHistory()
+	h.Add([]NetworkStatus{{Name: "eth0", RxRateMBs: 1, TxRateMBs: 2}, {Name: "wlan0", RxRateMBs: 3}})
+	h.Add([]NetworkStatus{{Name: "eth0", RxRateMBs: 4, TxRateMBs: 5}})
+	got := h.Snapshot()
+	if !slices.Equal(got["eth0"].RxHistory, []float64{1, 4}) || !slices.Equal(got["eth0"].TxHistory, []float64{2, 5}) {
+		t.Errorf("eth0 history = %+v", got["eth0"])
+	}
+	if _, ok := got["wlan0"]; ok {
+		t.Errorf("unplugged wlan0 should be dropped")
+	}
+}
+
+func TestConnectionGrouping(t *testing.T) {
+	conns := []ConnectionInfo{
+		{PID: 10, Process: "sshd", Proto: "tcp", LocalAddr: "0.0.0.0", LocalPort: 22, Status: "LISTEN"},
+		{PID: 10, Process: "sshd", Proto: "tcp6", LocalAddr: "::", LocalPort: 22, Status: "LISTEN"},
+		{PID: 20, Process: "dnsmasq", Proto: "udp", LocalAddr: "127.0.0.1", LocalPort: 53},
+		{PID: 30, Process: "firefox", Proto: "tcp", RemoteAddr: "1.1.1.1", RemotePort: 443, Status: "ESTABLISHED"},
+		{PID: 30, Process: "firefox", Proto: "tcp6", RemoteAddr: "2001:db8::5", RemotePort: 443, Status: "ESTABLISHED"},
+		{PID: 30, Process: "firefox", Proto: "tcp6", RemoteAddr: "2001:db8::5", RemotePort: 443, Status: "ESTABLISHED"},
+		{PID: 10, Process: "sshd", Proto: "tcp", RemoteAddr: "10.0.0.9", RemotePort: 51000, Status: "ESTABLISHED"},
+	}
+	listening := listeningSockets(conns)
+	if len(listening) != 3 || listening[0].LocalPort != 22 || listening[2].Process != "dnsmasq" {
+		t.Errorf("listening = %+v", listening)
+	}
+	groups := establishedByProcess(conns)
+	if len(groups) != 2 || groups[0].Process != "firefox" || groups[0].Count != 3 {
+		t.Fatalf("groups = %+v", groups)
+	}
+	if want := []string{"[2001:db8::5]:443", "1.1.1.1:443"}; !slices.Equal(groups[0].Remotes, want) {
+		t.Errorf("firefox remotes = %v, want %v", groups[0].Remotes, want)
+	}
+}
+
+func TestConnectionSamplerFindsListener(t *testing.T) {
+	ln, err := stdnet.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer ln.Close()
+	port := uint32(ln.Addr().(*stdnet.TCPAddr).Port)
+
+	conns, err := newConnectionSampler().Sample(context.Background())
+	if err != nil {
+		t.Skipf("connections unavailable: %v", err)
+	}
+	for _, c := range conns {
+		if c.LocalPort == port && c.Listening() {
+			if c.PID != int32(os.Getpid()) || c.Proto != "tcp" {
+				t.Errorf("listener = %+v, want this test process over tcp", c)
+			}
+			return
+		}
+	}
+	t.Errorf("listener on port %d not found in %d sockets", port, len(conns))
+}
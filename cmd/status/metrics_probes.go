@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Probe targets live in a plain text file, one probe per line:
+//
+//	# kind  target                label
+//	tcp     1.1.1.1:443           cloudflare
+//	tcp     git.example.com:22
+//	dns     github.com@1.1.1.1    cf-dns
+//
+// DNS probes always name the server to ask: the system resolver answers
+// from its cache, which says nothing about the network. Without a file
+// nothing is probed.
+
+const (
+	probeInterval    = 5 * time.Second
+	probeTimeout     = 2 * time.Second
+	probeHistorySize = 30 // Samples kept for sparklines, jitter and loss
+)
+
+// probeTargetsFile is the targets file from --probes.
+var probeTargetsFile = probeTargetsPath()
+
+// ProbeResult is the latest measurement of one target with its recent history.
+type ProbeResult struct {
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"` // tcp or dns
+	Target    string    `json:"target"`
+	OK        bool      `json:"ok"`
+	LatencyMs float64   `json:"latency_ms"`   // 0 when the last attempt failed
+	JitterMs  float64   `json:"jitter_ms"`    // Mean change between consecutive successes
+	Loss      float64   `json:"loss_percent"` // Failed share of recent attempts
+	Error     string    `json:"error,omitempty"`
+	History   []float64 `json:"history_ms"` // Oldest first; failures are 0
+}
+
+type probeTarget struct {
+	kind   string
+	target string // host:port for tcp, a name for dns
+	server string // dns only: host:port of the server to query
+	label  string
+}
+
+func probeTargetsPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "mole", "status_probes")
+}
+
+// loadProbeTargets reads a targets file. A missing file yields no targets.
+func loadProbeTargets(path string) ([]probeTarget, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+	targets, err := parseProbeTargets(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return targets, nil
+}
+
+func parseProbeTargets(r io.Reader) ([]probeTarget, error) {
+	var targets []probeTarget
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: want \"kind target [label]\"", line)
+		}
+		t := probeTarget{kind: fields[0], target: fields[1], label: fields[1]}
+		if len(fields) == 3 {
+			t.label = fields[2]
+		}
+		switch t.kind {
+		case "tcp":
+			if _, port, err := net.SplitHostPort(t.target); err != nil || port == "" {
+				return nil, fmt.Errorf("line %d: tcp target %q needs host:port", line, t.target)
+			}
+		case "dns":
+			name, server, _ := strings.Cut(t.target, "@")
+			if name == "" || server == "" {
+				return nil, fmt.Errorf("line %d: dns target %q needs name@server", line, t.target)
+			}
+			if _, _, err := net.SplitHostPort(server); err != nil {
+				server = net.JoinHostPort(server, "53")
+			}
+			t.target, t.server = name, server
+			if len(fields) == 2 {
+				t.label = name
+			}
+		default:
+			return nil, fmt.Errorf("line %d: unknown probe kind %q (want tcp or dns)", line, t.kind)
+		}
+		targets = append(targets, t)
+	}
+	return targets, scanner.Err()
+}
+
+// measure runs one attempt and returns its latency.
+func (t probeTarget) measure(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	switch t.kind {
+	case "tcp":
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", t.target)
+		if err != nil {
+			return 0, err
+		}
+		elapsed := time.Since(start)
+		_ = conn.Close()
+		return elapsed, nil
+	default:
+		resolver := &net.Resolver{PreferGo: true, Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, t.server)
+		}}
+		if _, err := resolver.LookupHost(ctx, t.target); err != nil {
+			return 0, err
+		}
+		return time.Since(start), nil
+	}
+}
+
+// prober measures every target concurrently and keeps their recent history.
+type prober struct {
+	targets []probeTarget
+	history [][]probeSample
+}
+
+type probeSample struct {
+	ok      bool
+	latency float64 // Milliseconds
+}
+
+func newProber(targets []probeTarget) *prober {
+	return &prober{targets: targets, history: make([][]probeSample, len(targets))}
+}
+
+func (p *prober) Run(ctx context.Context) []ProbeResult {
+	if len(p.targets) == 0 {
+		return nil
+	}
+	samples := make([]probeSample, len(p.targets))
+	errs := make([]error, len(p.targets))
+	var wg sync.WaitGroup
+	for i, t := range p.targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			latency, err := t.measure(ctx)
+			samples[i] = probeSample{ok: err == nil, latency: float64(latency.Microseconds()) / 1000}
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	results := make([]ProbeResult, len(p.targets))
+	for i, t := range p.targets {
+		hist := append(p.history[i], samples[i])
+		if len(hist) > probeHistorySize {
+			hist = hist[len(hist)-probeHistorySize:]
+		}
+		p.history[i] = hist
+
+		target := t.target
+		if t.kind == "dns" {
+			target += "@" + t.server
+		}
+		r := ProbeResult{Name: t.label, Kind: t.kind, Target: target, OK: samples[i].ok, LatencyMs: samples[i].latency}
+		if errs[i] != nil {
+			r.Error = probeError(errs[i])
+		}
+		r.JitterMs, r.Loss = probeStats(hist)
+		r.History = make([]float64, len(hist))
+		for j, s := range hist {
+			r.History[j] = s.latency
+		}
+		results[i] = r
+	}
+	return results
+}
+
+// probeStats returns the mean absolute change between consecutive successful
+// latencies, and the share of attempts that failed.
+func probeStats(hist []probeSample) (jitter, loss float64) {
+	var failed, pairs int
+	var prev float64
+	havePrev := false
+	for _, s := range hist {
+		if !s.ok {
+			failed++
+			continue
+		}
+		if havePrev {
+			jitter += math.Abs(s.latency - prev)
+			pairs++
+		}
+		prev, havePrev = s.latency, true
+	}
+	if pairs > 0 {
+		jitter /= float64(pairs)
+	}
+	if len(hist) > 0 {
+		loss = float64(failed) / float64(len(hist)) * 100
+	}
+	return jitter, loss
+}
+
+// probeError shortens network errors to their cause.
+func probeError(err error) string {
+	if dnsErr, ok := err.(*net.DNSError); ok {
+		if dnsErr.IsNotFound {
+			return "not found"
+		}
+		if dnsErr.IsTimeout {
+			return "timeout"
+		}
+	}
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return "timeout"
+	}
+	msg := err.Error()
+	if i := strings.LastIndex(msg, ": "); i >= 0 {
+		msg = msg[i+2:]
+	}
+	return msg
+}
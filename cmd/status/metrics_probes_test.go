@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startFakeDNS answers A queries for name with 192.0.2.10 and everything
+// else with NXDOMAIN. It returns the server's host:port.
+func startFakeDNS(t *testing.T, name string) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp unavailable: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if reply := fakeDNSReply(buf[:n], name); reply != nil {
+				_, _ = conn.WriteTo(reply, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func fakeDNSReply(query []byte, name string) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+	// Walk the question name to find its type.
+	i, labels := 12, []string{}
+	for i < len(query) && query[i] != 0 {
+		n := int(query[i])
+		if i+1+n > len(query) {
+			return nil
+		}
+		labels = append(labels, string(query[i+1:i+1+n]))
+		i += 1 + n
+	}
+	end := i + 5 // Root label, type and class
+	if end > len(query) {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(query[i+1:])
+
+	reply := append([]byte{}, query[:end]...)
+	reply[2], reply[3] = 0x81, 0x80 // Response, recursion available, NOERROR
+	binary.BigEndian.PutUint16(reply[6:], 0)
+	binary.BigEndian.PutUint16(reply[8:], 0)
+	binary.BigEndian.PutUint16(reply[10:], 0)
+	if !strings.EqualFold(strings.Join(labels, "."), name) {
+		reply[3] = 0x83 // NXDOMAIN
+		return reply
+	}
+	if qtype == 1 {
+		binary.BigEndian.PutUint16(reply[6:], 1)
+		reply = append(reply, 0xc0, 12, 0, 1, 0, 1, 0, 0, 0, 60, 0, 4, 192, 0, 2, 10)
+	}
+	return reply
+}
+
+func TestParseProbeTargets(t *testing.T) {
+	targets, err := parseProbeTargets(strings.NewReader(`
+# kind target label
+tcp 10.0.0.1:443 office
+tcp [2001:db8::1]:22   # no label
+dns github.com@1.1.1.1
+dns example.com@127.0.0.1:5353 local
+`))
+	if err != nil {
+		t.Fatalf("parseProbeTargets: %v", err)
+	}
+	want := []probeTarget{
+		{kind: "tcp", target: "10.0.0.1:443", label: "office"},
+		{kind: "tcp", target: "[2001:db8::1]:22", label: "[2001:db8::1]:22"},
+		{kind: "dns", target: "github.com", server: "1.1.1.1:53", label: "github.com"},
+		{kind: "dns", target: "example.com", server: "127.0.0.1:5353", label: "local"},
+	}
+	if len(targets) != len(want) {
+		t.Fatalf("got %d targets, want %d: %+v", len(targets), len(want), targets)
+	}
+	for i := range want {
+		if targets[i] != want[i] {
+			t.Errorf("target %d = %+v, want %+v", i, targets[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{"ping 1.1.1.1", "tcp 1.1.1.1", "dns", "dns @1.1.1.1", "dns github.com", "dns github.com@", "tcp a:1 b c"} {
+		if _, err := parseProbeTargets(strings.NewReader(bad)); err == nil {
+			t.Errorf("parseProbeTargets(%q) should fail", bad)
+		}
+	}
+
+	if targets, err := parseProbeTargets(strings.NewReader("# nothing\n")); err != nil || len(targets) != 0 {
+		t.Errorf("an empty file should disable probes, got %+v, %v", targets, err)
+	}
+	if targets, err := loadProbeTargets(t.TempDir() + "/missing"); err != nil || len(targets) != 0 {
+		t.Errorf("a missing file should probe nothing, got %+v, %v", targets, err)
+	}
+}
+
+func TestProberAgainstLocalListeners(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("tcp unavailable: %v", err)
+	}
+	defer listener.Close() //nolint:errcheck
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	// A port that was just released refuses connections.
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := closed.Addr().String()
+	_ = closed.Close()
+
+	dns := startFakeDNS(t, "mole.test")
+	p := newProber([]probeTarget{
+		{kind: "tcp", target: listener.Addr().String(), label: "open"},
+		{kind: "tcp", target: closedAddr, label: "closed"},
+		{kind: "dns", target: "mole.test", server: dns, label: "dns"},
+		{kind: "dns", target: "missing.test", server: dns, label: "nx"},
+	})
+
+	var results []ProbeResult
+	for range 3 {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		results = p.Run(ctx)
+		cancel()
+	}
+	open, refused, resolved, missing := results[0], results[1], results[2], results[3]
+
+	if !open.OK || open.LatencyMs <= 0 || open.Loss != 0 || len(open.History) != 3 {
+		t.Errorf("open port = %+v", open)
+	}
+	if refused.OK || refused.Error == "" || refused.Loss != 100 || refused.LatencyMs != 0 {
+		t.Errorf("closed port = %+v", refused)
+	}
+	if !resolved.OK || resolved.LatencyMs <= 0 || resolved.Target != "mole.test@"+dns {
+		t.Errorf("dns probe = %+v", resolved)
+	}
+	if missing.OK || missing.Error != "not found" {
+		t.Errorf("nxdomain probe = %+v", missing)
+	}
+}
+
+func TestProbeStats(t *testing.T) {
+	hist := []probeSample{
+		{ok: true, latency: 10},
+		{ok: true, latency: 14},
+		{ok: false},
+		{ok: true, latency: 12},
+		{ok: true, latency: 12},
+	}
+	jitter, loss := probeStats(hist)
+	// Changes between successes: 4, 2, 0.
+	if math.Abs(jitter-2) > 1e-9 || loss != 20 {
+		t.Errorf("probeStats = %v, %v; want 2, 20", jitter, loss)
+	}
+	if jitter, loss := probeStats(nil); jitter != 0 || loss != 0 {
+		t.Errorf("empty history = %v, %v", jitter, loss)
+	}
+}
+
+func TestNetworkCardProbes(t *testing.T) {
+	card := renderNetworkCard(nil, NetworkHistory{}, ProxyStatus{}, NetworkConfig{}, []ProbeResult{
+		{Name: "internet", OK: true, LatencyMs: 23.4, JitterMs: 3.2, History: []float64{20, 25, 23.4}},
+		{Name: "dns", OK: true, LatencyMs: 180, JitterMs: 40, Loss: 10, History: []float64{100, 180}},
+		{Name: "office", Error: "connection refused"},
+	}, nil, 40)
+	got := ansiRe.ReplaceAllString(strings.Join(card.lines, "\n"), "")
+	for _, want := range []string{"inter… ", "23ms ±3.2ms", "180ms ±40ms 10% loss", "office", "connection refused"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("card missing %q:\n%s", want, got)
+		}
+	}
+}
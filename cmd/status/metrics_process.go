@@ -2,52 +2,297 @@ package main
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
 )
 
-func collectTopProcesses() []ProcessInfo {
-	if runtime.GOOS != "darwin" {
-		return nil
-	}
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
+const (
+	topProcessCount = 5
+	topIOCount      = 3
+	processTimeout  = 2 * time.Second
+)
+
+// ProcessDetail is one process as shown on the process screen.
+type ProcessDetail struct {
+	PID       int32   `json:"pid"`
+	PPID      int32   `json:"ppid"`
+	Name      string  `json:"name"`
+	User      string  `json:"user"`
+	CPU       float64 `json:"cpu"` // Percent of one core since the last sample
+	RSS       uint64  `json:"rss"`
+	ReadRate  float64 `json:"read_rate_mbs"`  // Disk, not socket or pipe, MB/s
+	WriteRate float64 `json:"write_rate_mbs"` // Disk, not socket or pipe, MB/s
+	OpenFiles int32   `json:"open_files"`     // -1 when not readable
+	Threads   int32   `json:"threads"`
+	Cmdline   string  `json:"cmdline"`
+}
+
+// procRaw is a process as read from the OS, with cumulative counters.
+type procRaw struct {
+	ProcessDetail
+	created    int64 // Distinguishes a reused PID
+	cpuSeconds float64
+	readBytes  uint64
+	writeBytes uint64
+}
 
-	// Use ps to get top processes by CPU.
-	out, err := runCmd(ctx, "ps", "-Aceo", "pcpu,pmem,comm", "-r")
+// procReader lists processes. With detail set it also fills user, command
+// line, open files and threads, which cost extra reads per process.
+type procReader func(ctx context.Context, detail bool) ([]procRaw, error)
+
+// processSampler turns cumulative process counters into rates between samples.
+type processSampler struct {
+	read   procReader
+	now    func() time.Time
+	prev   map[int32]procRaw
+	lastAt time.Time
+}
+
+func newProcessSampler() *processSampler {
+	return &processSampler{read: newGopsutilProcReader(), now: time.Now}
+}
+
+// Sample returns every process. CPU and IO rates are zero on the first call.
+func (s *processSampler) Sample(detail bool) ([]ProcessDetail, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), processTimeout)
+	defer cancel()
+	raws, err := s.read(ctx, detail)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
-	var procs []ProcessInfo
-	i := 0
-	for line := range strings.Lines(strings.TrimSpace(out)) {
-		if i == 0 {
-			i++
-			continue
+	now := s.now()
+	elapsed := 0.0
+	if !s.lastAt.IsZero() {
+		elapsed = now.Sub(s.lastAt).Seconds()
+	}
+	const mb = 1024 * 1024
+
+	out := make([]ProcessDetail, 0, len(raws))
+	next := make(map[int32]procRaw, len(raws))
+	for _, raw := range raws {
+		d := raw.ProcessDetail
+		if prev, ok := s.prev[raw.PID]; ok && prev.created == raw.created && elapsed > 0 {
+			d.CPU = max(raw.cpuSeconds-prev.cpuSeconds, 0) / elapsed * 100
+			if raw.readBytes >= prev.readBytes {
+				d.ReadRate = float64(raw.readBytes-prev.readBytes) / mb / elapsed
+			}
+			if raw.writeBytes >= prev.writeBytes {
+				d.WriteRate = float64(raw.writeBytes-prev.writeBytes) / mb / elapsed
+			}
+		}
+		next[raw.PID] = raw
+		out = append(out, d)
+	}
+	s.prev = next
+	s.lastAt = now
+	return out, nil
+}
+
+// topProcesses returns the busiest processes for the dashboard card.
+func topProcesses(procs []ProcessDetail, totalMem uint64, n int) []ProcessInfo {
+	sorted := make([]ProcessDetail, len(procs))
+	copy(sorted, procs)
+	sortProcesses(sorted, sortByCPU)
+
+	var top []ProcessInfo
+	for _, p := range sorted[:min(n, len(sorted))] {
+		info := ProcessInfo{Name: p.Name, CPU: p.CPU}
+		if totalMem > 0 {
+			info.Memory = float64(p.RSS) / float64(totalMem) * 100
 		}
-		if i > 5 {
+		top = append(top, info)
+	}
+	return top
+}
+
+// ProcessIO is a process ranked by disk throughput.
+type ProcessIO struct {
+	PID       int32   `json:"pid"`
+	Name      string  `json:"name"`
+	ReadRate  float64 `json:"read_rate_mbs"`
+	WriteRate float64 `json:"write_rate_mbs"`
+}
+
+// topIOProcesses returns the processes doing the most disk IO, skipping idle ones.
+func topIOProcesses(procs []ProcessDetail, n int) []ProcessIO {
+	sorted := make([]ProcessDetail, len(procs))
+	copy(sorted, procs)
+	sortProcesses(sorted, sortByIO)
+
+	var top []ProcessIO
+	for _, p := range sorted {
+		if len(top) == n || p.ReadRate+p.WriteRate < 0.01 {
 			break
 		}
-		i++
-		fields := strings.Fields(line)
-		if len(fields) < 3 {
+		top = append(top, ProcessIO{PID: p.PID, Name: p.Name, ReadRate: p.ReadRate, WriteRate: p.WriteRate})
+	}
+	return top
+}
+
+// processDiskIO returns cumulative bytes a process read from and wrote to storage.
+// gopsutil reports rchar/wchar on Linux, which include sockets and pipes, so
+// /proc/<pid>/io is read directly there.
+func processDiskIO(ctx context.Context, p *process.Process) (read, write uint64) {
+	if runtime.GOOS == "linux" {
+		data, err := os.ReadFile(filepath.Join(hostRoot, "proc", strconv.Itoa(int(p.Pid)), "io"))
+		if err != nil {
+			return 0, 0 // Other users' processes need root.
+		}
+		return parseProcIO(data)
+	}
+	io, err := p.IOCountersWithContext(ctx)
+	if err != nil {
+		return 0, 0 // Not available on macOS.
+	}
+	if io.DiskReadBytes > 0 || io.DiskWriteBytes > 0 {
+		return io.DiskReadBytes, io.DiskWriteBytes
+	}
+	return io.ReadBytes, io.WriteBytes
+}
+
+// parseProcIO reads storage bytes from /proc/<pid>/io. Writes later
+// truncated away before reaching disk are subtracted.
+func parseProcIO(data []byte) (read, write uint64) {
+	var cancelled uint64
+	for line := range strings.Lines(string(data)) {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
 			continue
 		}
-		cpuVal, _ := strconv.ParseFloat(fields[0], 64)
-		memVal, _ := strconv.ParseFloat(fields[1], 64)
-		name := fields[len(fields)-1]
-		// Strip path from command name.
-		if idx := strings.LastIndex(name, "/"); idx >= 0 {
-			name = name[idx+1:]
+		switch name {
+		case "read_bytes":
+			read = n
+		case "write_bytes":
+			write = n
+		case "cancelled_write_bytes":
+			cancelled = n
 		}
-		procs = append(procs, ProcessInfo{
-			Name:   name,
-			CPU:    cpuVal,
-			Memory: memVal,
-		})
 	}
-	return procs
+	if cancelled < write {
+		write -= cancelled
+	} else {
+		write = 0
+	}
+	return read, write
+}
+
+// procStatic caches what does not change over a process's life.
+type procStatic struct {
+	created int64
+	ppid    int32
+	name    string
+	user    string
+	cmdline string
+	full    bool // user and cmdline were read
+}
+
+// newGopsutilProcReader reads processes through gopsutil, caching static fields per PID.
+func newGopsutilProcReader() procReader {
+	cache := make(map[int32]procStatic)
+	return func(ctx context.Context, detail bool) ([]procRaw, error) {
+		procs, err := process.ProcessesWithContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[int32]procStatic, len(procs))
+		out := make([]procRaw, 0, len(procs))
+		for _, p := range procs {
+			if ctx.Err() != nil {
+				break
+			}
+			created, _ := p.CreateTimeWithContext(ctx)
+			static, ok := cache[p.Pid]
+			if !ok || static.created != created {
+				name, err := p.NameWithContext(ctx)
+				if err != nil {
+					continue // Exited while listing.
+				}
+				ppid, _ := p.PpidWithContext(ctx)
+				static = procStatic{created: created, ppid: ppid, name: name}
+			}
+			if detail && !static.full {
+				static.user, _ = p.UsernameWithContext(ctx)
+				static.cmdline, _ = p.CmdlineWithContext(ctx)
+				static.full = true
+			}
+			seen[p.Pid] = static
+
+			raw := procRaw{
+				ProcessDetail: ProcessDetail{PID: p.Pid, PPID: static.ppid, Name: static.name, OpenFiles: -1},
+				created:       created,
+			}
+			if times, err := p.TimesWithContext(ctx); err == nil {
+				raw.cpuSeconds = times.User + times.System
+			}
+			if mem, err := p.MemoryInfoWithContext(ctx); err == nil {
+				raw.RSS = mem.RSS
+			}
+			raw.readBytes, raw.writeBytes = processDiskIO(ctx, p)
+			if detail {
+				raw.User, raw.Cmdline = static.user, static.cmdline
+				if fds, err := p.NumFDsWithContext(ctx); err == nil {
+					raw.OpenFiles = fds
+				}
+				raw.Threads, _ = p.NumThreadsWithContext(ctx)
+			}
+			out = append(out, raw)
+		}
+		cache = seen
+		return out, nil
+	}
+}
+
+// processSort is a column of the process screen.
+type processSort int
+
+const (
+	sortByCPU processSort = iota
+	sortByMemory
+	sortByIO
+	sortByPID
+	sortByName
+	processSortCount
+)
+
+func (s processSort) String() string {
+	return [...]string{"cpu", "memory", "io", "pid", "name"}[s]
+}
+
+// less orders busiest first for usage columns and ascending for PID and name.
+func (s processSort) less(a, b ProcessDetail) bool {
+	switch s {
+	case sortByMemory:
+		if a.RSS != b.RSS {
+			return a.RSS > b.RSS
+		}
+	case sortByIO:
+		if ai, bi := a.ReadRate+a.WriteRate, b.ReadRate+b.WriteRate; ai != bi {
+			return ai > bi
+		}
+	case sortByName:
+		if an, bn := strings.ToLower(a.Name), strings.ToLower(b.Name); an != bn {
+			return an < bn
+		}
+	case sortByCPU:
+		if a.CPU != b.CPU {
+			return a.CPU > b.CPU
+		}
+	}
+	return a.PID < b.PID
+}
+
+func sortProcesses(procs []ProcessDetail, by processSort) {
+	sort.SliceStable(procs, func(i, j int) bool { return by.less(procs[i], procs[j]) })
 }
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestProcessSamplerRates(t *testing.T) {
+	samples := [][]procRaw{
+		{
+			{ProcessDetail: ProcessDetail{PID: 10, Name: "backupd"}, created: 1, cpuSeconds: 5, readBytes: 1 << 20},
+			{ProcessDetail: ProcessDetail{PID: 20, Name: "old"}, created: 1, cpuSeconds: 100},
+		},
+		{
+			{ProcessDetail: ProcessDetail{PID: 10, Name: "backupd"}, created: 1, cpuSeconds: 6, readBytes: 5 << 20, writeBytes: 2 << 20},
+			{ProcessDetail: ProcessDetail{PID: 20, Name: "new"}, created: 2, cpuSeconds: 1}, // PID reused
+		},
+	}
+	clock := time.Unix(1700000000, 0)
+	call := 0
+	sampler := &processSampler{
+		read: func(context.Context, bool) ([]procRaw, error) {
+			out := samples[call]
+			call++
+			return out, nil
+		},
+		now: func() time.Time { return clock },
+	}
+
+	first, _ := sampler.Sample(false)
+	if first[0].CPU != 0 || first[0].ReadRate != 0 {
+		t.Errorf("first sample should have no rates: %+v", first[0])
+	}
+
+	clock = clock.Add(2 * time.Second)
+	second, _ := sampler.Sample(false)
+	if second[0].CPU != 50 || second[0].ReadRate != 2 || second[0].WriteRate != 1 {
+		t.Errorf("rates = cpu %v read %v write %v, want 50, 2, 1", second[0].CPU, second[0].ReadRate, second[0].WriteRate)
+	}
+	if second[1].CPU != 0 {
+		t.Errorf("a reused PID should not inherit counters: %+v", second[1])
+	}
+}
+
+func TestTopProcesses(t *testing.T) {
+	procs := []ProcessDetail{
+		{PID: 1, Name: "idle", CPU: 0, RSS: 100},
+		{PID: 2, Name: "busy", CPU: 80, RSS: 400},
+		{PID: 3, Name: "mid", CPU: 20},
+	}
+	top := topProcesses(procs, 1000, 2)
+	if len(top) != 2 || top[0].Name != "busy" || top[1].Name != "mid" {
+		t.Fatalf("topProcesses = %+v", top)
+	}
+	if top[0].Memory != 40 {
+		t.Errorf("memory percent = %v, want 40", top[0].Memory)
+	}
+	if procs[0].Name != "idle" {
+		t.Errorf("topProcesses should not reorder its input")
+	}
+}
+
+var treeProcs = []ProcessDetail{
+	{PID: 1, PPID: 0, Name: "launchd"},
+	{PID: 50, PPID: 1, Name: "Terminal", CPU: 5},
+	{PID: 51, PPID: 50, Name: "zsh", Cmdline: "-zsh"},
+	{PID: 60, PPID: 51, Name: "go", CPU: 90, Cmdline: "go test ./..."},
+	{PID: 70, PPID: 1, Name: "Safari", CPU: 30},
+}
+
+func TestProcessTree(t *testing.T) {
+	rows := processTree(treeProcs, treeProcs, sortByCPU)
+	var got []string
+	for _, r := range rows {
+		got = append(got, r.prefix+r.Name)
+	}
+	want := []string{"launchd", "├─ Safari", "└─ Terminal", "   └─ zsh", "      └─ go"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Filtering keeps the ancestors of a match.
+	v := processView{tree: true, filter: "go test"}
+	rows = v.rows(treeProcs)
+	if len(rows) != 4 || rows[3].Name != "go" {
+		t.Errorf("filtered tree = %+v", rows)
+	}
+}
+
+func keyRunes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestProcessViewKeys(t *testing.T) {
+	var v processView
+	v.handleKey(keyRunes("s"), treeProcs, 10)
+	if v.sortBy != sortByMemory {
+		t.Errorf("s should cycle the sort, got %v", v.sortBy)
+	}
+
+	v.handleKey(keyRunes("/"), treeProcs, 10)
+	for _, r := range "saf" {
+		v.handleKey(keyRunes(string(r)), treeProcs, 10)
+	}
+	v.handleKey(tea.KeyMsg{Type: tea.KeyEnter}, treeProcs, 10)
+	if v.filtering || v.filter != "saf" {
+		t.Fatalf("filter = %q filtering=%v", v.filter, v.filtering)
+	}
+	if rows := v.rows(treeProcs); len(rows) != 1 || rows[0].Name != "Safari" {
+		t.Errorf("filtered rows = %+v", rows)
+	}
+
+	if open, _ := v.handleKey(keyRunes("p"), treeProcs, 10); open {
+		t.Errorf("p should close the screen")
+	}
+}
+
+func TestProcessViewConfirmsSignal(t *testing.T) {
+	var sent []int32
+	orig := signalProcess
+	signalProcess = func(pid int32, kill bool) error {
+		if kill {
+			sent = append(sent, pid)
+		}
+		return nil
+	}
+	defer func() { signalProcess = orig }()
+
+	v := processView{filter: "safari"}
+	v.handleKey(keyRunes("X"), treeProcs, 10)
+	if v.confirm == nil || v.confirm.pid != 70 || !v.confirm.kill {
+		t.Fatalf("X should ask to SIGKILL Safari, got %+v", v.confirm)
+	}
+	if out := renderProcessView(treeProcs, v, 120, 30); !strings.Contains(out, "Send SIGKILL to 70 (Safari)?") {
+		t.Errorf("confirmation prompt missing:\n%s", out)
+	}
+
+	// Any key but y cancels.
+	v.handleKey(keyRunes("n"), treeProcs, 10)
+	if v.confirm != nil || v.status != "Cancelled" {
+		t.Errorf("n should cancel: %+v", v)
+	}
+
+	v.handleKey(keyRunes("X"), treeProcs, 10)
+	_, cmd := v.handleKey(keyRunes("y"), treeProcs, 10)
+	if cmd == nil {
+		t.Fatal("y should send the signal")
+	}
+	msg := cmd().(processSignalMsg)
+	if msg.err != nil || len(sent) != 1 || sent[0] != 70 {
+		t.Errorf("signal not sent: %+v, sent %v", msg, sent)
+	}
+
+	if msg := sendProcessSignal(processSignal{pid: 1})().(processSignalMsg); msg.err == nil {
+		t.Errorf("signalling PID 1 should be refused")
+	}
+}
+
+func TestRenderProcessView(t *testing.T) {
+	if out := renderProcessView(nil, processView{}, 100, 30); !strings.Contains(out, "Loading processes") {
+		t.Errorf("nil processes should show loading:\n%s", out)
+	}
+	out := renderProcessView(treeProcs, processView{}, 120, 30)
+	lines := strings.Split(out, "\n")
+	if len(lines) < 4 || !strings.Contains(lines[1], "COMMAND") {
+		t.Fatalf("missing header:\n%s", out)
+	}
+	if !strings.Contains(lines[2], "go") || !strings.Contains(lines[2], "go test ./...") {
+		t.Errorf("busiest process should be first with its command line:\n%s", out)
+	}
+}
+
+func TestParseProcIO(t *testing.T) {
+	data := []byte(`rchar: 323934931
+wchar: 323929600
+syscr: 632687
+syscw: 632675
+read_bytes: 4096
+write_bytes: 1048576
+cancelled_write_bytes: 24576
+`)
+	read, write := parseProcIO(data)
+	if read != 4096 || write != 1048576-24576 {
+		t.Errorf("parseProcIO = %d, %d; rchar/wchar must not count as disk IO", read, write)
+	}
+	if _, write := parseProcIO([]byte("write_bytes: 10\ncancelled_write_bytes: 20\n")); write != 0 {
+		t.Errorf("cancelled writes beyond written bytes should clamp to 0, got %d", write)
+	}
+}
+
+func TestTopIOProcesses(t *testing.T) {
+	procs := []ProcessDetail{
+		{PID: 1, Name: "idle"},
+		{PID: 2, Name: "backupd", ReadRate: 120, WriteRate: 30},
+		{PID: 3, Name: "mds", WriteRate: 5},
+	}
+	top := topIOProcesses(procs, 3)
+	if len(top) != 2 || top[0].Name != "backupd" || top[1].Name != "mds" {
+		t.Fatalf("topIOProcesses = %+v", top)
+	}
+
+	card := renderDiskCard([]DiskStatus{{Mount: "/", UsedPercent: 50, Total: 100}}, DiskIOStatus{ReadRate: 120}, top, MetricsHistory{})
+	text := strings.Join(card.lines, "\n")
+	if !strings.Contains(text, "backupd") || !strings.Contains(text, "R 120.0M/s") {
+		t.Errorf("disk card missing IO consumers:\n%s", text)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	topNetCount       = 3
+	processNetTimeout = 3 * time.Second // nettop samples for about a second
+)
+
+// ProcessNet is a process ranked by network throughput.
+type ProcessNet struct {
+	PID    int32   `json:"pid"`
+	Name   string  `json:"name"`
+	RxRate float64 `json:"rx_rate_mbs"`
+	TxRate float64 `json:"tx_rate_mbs"`
+}
+
+// procNetBytes is a process's cumulative socket traffic.
+type procNetBytes struct {
+	name   string
+	rx, tx uint64
+}
+
+// procNetReader returns cumulative traffic per PID.
+type procNetReader func(ctx context.Context) (map[int32]procNetBytes, error)
+
+// processNetSampler turns cumulative per-process traffic into rates.
+type processNetSampler struct {
+	read   procNetReader
+	now    func() time.Time
+	prev   map[int32]procNetBytes
+	lastAt time.Time
+}
+
+// newProcessNetSampler reads nettop on macOS and ss on Linux. ss only sees
+// open TCP sockets, so traffic on sockets closed between samples is missed.
+func newProcessNetSampler() *processNetSampler {
+	s := &processNetSampler{now: time.Now}
+	switch {
+	case runtime.GOOS == "darwin" && commandExists("nettop"):
+		s.read = func(ctx context.Context) (map[int32]procNetBytes, error) {
+			out, err := runCmd(ctx, "nettop", "-P", "-L", "1", "-x", "-J", "bytes_in,bytes_out")
+			if err != nil {
+				return nil, err
+			}
+			return parseNettop(out), nil
+		}
+	case runtime.GOOS == "linux" && commandExists("ss"):
+		s.read = func(ctx context.Context) (map[int32]procNetBytes, error) {
+			out, err := runCmd(ctx, "ss", "-tinpH")
+			if err != nil {
+				return nil, err
+			}
+			return parseSSProcessBytes(out), nil
+		}
+	}
+	return s
+}
+
+// Sample returns the busiest processes. Rates are empty on the first call.
+func (s *processNetSampler) Sample(ctx context.Context, n int) ([]ProcessNet, error) {
+	if s.read == nil {
+		return nil, nil
+	}
+	cur, err := s.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	elapsed := 0.0
+	if !s.lastAt.IsZero() {
+		elapsed = now.Sub(s.lastAt).Seconds()
+	}
+	const mb = 1024 * 1024
+
+	var top []ProcessNet
+	for pid, b := range cur {
+		prev, ok := s.prev[pid]
+		if !ok || elapsed <= 0 || prev.name != b.name {
+			continue
+		}
+		p := ProcessNet{PID: pid, Name: b.name}
+		if b.rx >= prev.rx {
+			p.RxRate = float64(b.rx-prev.rx) / mb / elapsed
+		}
+		if b.tx >= prev.tx {
+			p.TxRate = float64(b.tx-prev.tx) / mb / elapsed
+		}
+		if p.RxRate+p.TxRate >= 0.01 {
+			top = append(top, p)
+		}
+	}
+	s.prev = cur
+	s.lastAt = now
+
+	sort.Slice(top, func(i, j int) bool {
+		if a, b := top[i].RxRate+top[i].TxRate, top[j].RxRate+top[j].TxRate; a != b {
+			return a > b
+		}
+		return top[i].PID < top[j].PID
+	})
+	return top[:min(n, len(top))], nil
+}
+
+// parseNettop reads "nettop -P -x -J bytes_in,bytes_out" CSV, whose rows
+// are "name.pid,bytes_in,bytes_out,".
+func parseNettop(out string) map[int32]procNetBytes {
+	procs := make(map[int32]procNetBytes)
+	for line := range strings.Lines(out) {
+		fields := strings.Split(strings.TrimSpace(line), ",")
+		if len(fields) < 3 {
+			continue
+		}
+		dot := strings.LastIndexByte(fields[0], '.')
+		if dot <= 0 {
+			continue // The header row starts with an empty column.
+		}
+		pid, err := strconv.ParseInt(fields[0][dot+1:], 10, 32)
+		if err != nil {
+			continue
+		}
+		rx, err1 := strconv.ParseUint(fields[1], 10, 64)
+		tx, err2 := strconv.ParseUint(fields[2], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		procs[int32(pid)] = procNetBytes{name: fields[0][:dot], rx: rx, tx: tx}
+	}
+	return procs
+}
+
+// parseSSProcessBytes sums "ss -tinpH" byte counters per owning process.
+// A socket starts on an unindented line naming its users; the counters
+// follow on the same or the next, indented, line. Shared sockets count
+// towards the first user; loopback sockets are skipped like the lo interface.
+func parseSSProcessBytes(out string) map[int32]procNetBytes {
+	procs := make(map[int32]procNetBytes)
+	var pid int32 = -1
+	var name string
+	for line := range strings.Lines(out) {
+		if line != "" && line[0] != ' ' && line[0] != '\t' {
+			pid, name = -1, ""
+			if fields := strings.Fields(line); len(fields) > 3 && isLoopbackAddr(fields[3]) {
+				continue
+			}
+			if _, users, ok := strings.Cut(line, `users:(("`); ok {
+				n, rest, _ := strings.Cut(users, `"`)
+				if _, p, ok := strings.Cut(rest, "pid="); ok {
+					p, _, _ = strings.Cut(p, ",")
+					if v, err := strconv.ParseInt(p, 10, 32); err == nil {
+						pid, name = int32(v), n
+					}
+				}
+			}
+		}
+		if pid < 0 {
+			continue
+		}
+		b := procs[pid]
+		b.name = name
+		for _, field := range strings.Fields(line) {
+			key, value, ok := strings.Cut(field, ":")
+			if !ok || (key != "bytes_sent" && key != "bytes_received") {
+				continue
+			}
+			v, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			if key == "bytes_sent" {
+				b.tx += v
+			} else {
+				b.rx += v
+			}
+		}
+		procs[pid] = b
+	}
+	return procs
+}
+
+func isLoopbackAddr(hostPort string) bool {
+	return strings.HasPrefix(hostPort, "127.") || strings.HasPrefix(hostPort, "[::1]") || strings.HasPrefix(hostPort, "[::ffff:127.")
+}
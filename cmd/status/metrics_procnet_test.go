@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseNettop(t *testing.T) {
+	out := `,bytes_in,bytes_out,
+launchd.1,0,0,
+com.apple.WebKit.Networking.812,7340032,1048576,
+garbage
+`
+	got := parseNettop(out)
+	if len(got) != 2 {
+		t.Fatalf("parsed %d processes: %+v", len(got), got)
+	}
+	if p := got[812]; p.name != "com.apple.WebKit.Networking" || p.rx != 7<<20 || p.tx != 1<<20 {
+		t.Errorf("WebKit = %+v", p)
+	}
+}
+
+func TestParseSSProcessBytes(t *testing.T) {
+	out := `ESTAB 0 0 10.0.0.2:22 10.0.0.1:50000 users:(("sshd",pid=812,fd=4))
+	 cubic wscale:7,7 rto:204 bytes_sent:3000 bytes_acked:3000 bytes_received:500 segs_out:20
+ESTAB 0 0 10.0.0.2:22 10.0.0.1:50001 users:(("sshd",pid=812,fd=5),("sshd",pid=900,fd=5))
+	 cubic bytes_sent:1000 bytes_received:1500
+ESTAB 0 0 10.0.0.2:443 1.1.1.1:443
+	 cubic bytes_sent:99 bytes_received:99
+ESTAB 0 0 127.0.0.1:42798 127.0.0.1:48271 users:(("agent",pid=55,fd=16))
+	 cubic bytes_sent:99 bytes_received:99
+ESTAB 0 0 10.0.0.2:40000 1.1.1.1:443 users:(("curl",pid=77,fd=3)) cubic bytes_sent:10 bytes_received:20
+`
+	got := parseSSProcessBytes(out)
+	if p := got[812]; p.name != "sshd" || p.tx != 4000 || p.rx != 2000 {
+		t.Errorf("sshd = %+v", p)
+	}
+	if p := got[77]; p.name != "curl" || p.tx != 10 || p.rx != 20 {
+		t.Errorf("details on the socket line should count, got %+v", p)
+	}
+	if len(got) != 2 {
+		t.Errorf("loopback sockets, sockets without a user and later users should not count: %+v", got)
+	}
+}
+
+func TestProcessNetSamplerRates(t *testing.T) {
+	samples := []map[int32]procNetBytes{
+		{10: {name: "curl", rx: 1 << 20}, 20: {name: "idle"}, 30: {name: "old", tx: 8 << 20}},
+		{10: {name: "curl", rx: 9 << 20, tx: 2 << 20}, 20: {name: "idle"}, 30: {name: "new", tx: 9 << 20}},
+	}
+	clock := time.Unix(1700000000, 0)
+	call := 0
+	sampler := &processNetSampler{
+		read: func(context.Context) (map[int32]procNetBytes, error) {
+			out := samples[call]
+			call++
+			return out, nil
+		},
+		now: func() time.Time { return clock },
+	}
+
+	if first, _ := sampler.Sample(context.Background(), 3); len(first) != 0 {
+		t.Errorf("first sample should have no rates: %+v", first)
+	}
+	clock = clock.Add(2 * time.Second)
+	top, _ := sampler.Sample(context.Background(), 3)
+	if len(top) != 1 || top[0].Name != "curl" || top[0].RxRate != 4 || top[0].TxRate != 1 {
+		t.Errorf("top = %+v, want only curl at 4 and 1 MB/s", top)
+	}
+
+	card := renderNetworkCard([]NetworkStatus{{Name: "en0"}}, NetworkHistory{}, ProxyStatus{}, NetworkConfig{}, nil, top, 60)
+	if got := ansiRe.ReplaceAllString(strings.Join(card.lines, "\n"), ""); !strings.Contains(got, "curl          ↓ 4.0M/s  ↑ 1.0M/s") {
+		t.Errorf("network card should list the busiest processes:\n%s", got)
+	}
+}
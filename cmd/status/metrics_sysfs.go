@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Linux collectors read procfs, sysfs and /etc. Each takes the filesystem root
+// so tests can run against fixture trees; the collectors pass hostRoot.
+var hostRoot = "/"
+
+// PSI thresholds (avg10, percent of time stalled) for the memory pressure levels.
+const (
+	psiSomeWarn     = 10.0
+	psiSomeCritical = 40.0
+	psiFullCritical = 10.0
+)
+
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readSysInt reads an integer sysfs attribute.
+func readSysInt(path string) (int64, bool) {
+	v, err := strconv.ParseInt(readTrimmed(path), 10, 64)
+	return v, err == nil
+}
+
+// hwmon driver names by the component they measure.
+var (
+	cpuHwmonNames = []string{"coretemp", "k10temp", "zenpower", "cpu_thermal", "soc_thermal", "acpitz"}
+	gpuHwmonNames = []string{"amdgpu", "radeon", "nouveau", "i915", "xe"}
+	cpuZoneTypes  = []string{"x86_pkg_temp", "cpu-thermal", "cpu_thermal", "soc_thermal", "acpitz"}
+)
+
+// readLinuxThermal reads temperatures and fans from hwmon, falling back to
+// thermal zones for the CPU temperature.
+func readLinuxThermal(root string) ThermalStatus {
+	var thermal ThermalStatus
+	cpuRank := len(cpuHwmonNames)
+
+	hwmons, _ := filepath.Glob(filepath.Join(root, "sys/class/hwmon/hwmon*"))
+	slices.Sort(hwmons)
+	for _, dir := range hwmons {
+		name := readTrimmed(filepath.Join(dir, "name"))
+
+		if rank := slices.Index(cpuHwmonNames, name); rank >= 0 && rank < cpuRank {
+			if temp := hwmonCPUTemp(dir); temp > 0 {
+				thermal.CPUTemp = temp
+				cpuRank = rank
+			}
+		}
+		if slices.Contains(gpuHwmonNames, name) && thermal.GPUTemp == 0 {
+			if milli, ok := readSysInt(filepath.Join(dir, "temp1_input")); ok && milli > 0 {
+				thermal.GPUTemp = float64(milli) / 1000
+			}
+		}
+
+		fans, _ := filepath.Glob(filepath.Join(dir, "fan*_input"))
+		for _, fan := range fans {
+			if rpm, ok := readSysInt(fan); ok && rpm > 0 {
+				thermal.FanCount++
+				thermal.FanSpeed = max(thermal.FanSpeed, int(rpm))
+			}
+		}
+	}
+
+	if thermal.CPUTemp == 0 {
+		thermal.CPUTemp = thermalZoneCPUTemp(root)
+	}
+	return thermal
+}
+
+// hwmonCPUTemp prefers the package sensor ("Package id 0", "Tctl"), else the hottest core.
+func hwmonCPUTemp(dir string) float64 {
+	inputs, _ := filepath.Glob(filepath.Join(dir, "temp*_input"))
+	slices.Sort(inputs)
+	hottest := 0.0
+	for _, input := range inputs {
+		milli, ok := readSysInt(input)
+		if !ok || milli <= 0 {
+			continue
+		}
+		temp := float64(milli) / 1000
+		label := readTrimmed(strings.TrimSuffix(input, "_input") + "_label")
+		if strings.HasPrefix(label, "Package id") || label == "Tctl" || label == "Tdie" {
+			return temp
+		}
+		hottest = max(hottest, temp)
+	}
+	return hottest
+}
+
+// thermalZoneCPUTemp reads the CPU-like thermal zone, or the hottest zone.
+func thermalZoneCPUTemp(root string) float64 {
+	zones, _ := filepath.Glob(filepath.Join(root, "sys/class/thermal/thermal_zone*"))
+	slices.Sort(zones)
+	best, bestRank := 0.0, len(cpuZoneTypes)+1
+	for _, zone := range zones {
+		milli, ok := readSysInt(filepath.Join(zone, "temp"))
+		if !ok || milli <= 0 {
+			continue
+		}
+		temp := float64(milli) / 1000
+		rank := slices.Index(cpuZoneTypes, readTrimmed(filepath.Join(zone, "type")))
+		if rank < 0 {
+			rank = len(cpuZoneTypes)
+		}
+		if rank < bestRank || (rank == bestRank && temp > best) {
+			best, bestRank = temp, rank
+		}
+	}
+	return best
+}
+
+// Placeholders firmware vendors leave in DMI fields.
+var dmiPlaceholders = []string{"", "to be filled by o.e.m.", "system product name", "default string", "not applicable", "none"}
+
+// readDMIProductName returns the machine model from DMI, e.g. "Dell XPS 13 9310".
+func readDMIProductName(root string) string {
+	dmi := filepath.Join(root, "sys/class/dmi/id")
+	vendor := readTrimmed(filepath.Join(dmi, "sys_vendor"))
+	product := readTrimmed(filepath.Join(dmi, "product_name"))
+	// Lenovo keeps the marketing name ("ThinkPad X1 Carbon") in product_version.
+	if strings.EqualFold(vendor, "LENOVO") {
+		if version := readTrimmed(filepath.Join(dmi, "product_version")); !slices.Contains(dmiPlaceholders, strings.ToLower(version)) {
+			product = version
+		}
+	}
+	if slices.Contains(dmiPlaceholders, strings.ToLower(product)) {
+		return ""
+	}
+	vendor = strings.TrimSuffix(strings.TrimSuffix(vendor, " Inc."), ",")
+	if vendor == "" || slices.Contains(dmiPlaceholders, strings.ToLower(vendor)) ||
+		strings.HasPrefix(strings.ToLower(product), strings.ToLower(strings.Fields(vendor)[0])) {
+		return product
+	}
+	if strings.EqualFold(vendor, "LENOVO") {
+		vendor = "Lenovo"
+	}
+	return vendor + " " + product
+}
+
+var cpuModelNoise = regexp.MustCompile(`\((R|TM|r|tm)\)|\s+CPU\b|\s+@\s+[\d.]+\s*GHz|\s+\d+-Core Processor`)
+
+// readCPUModel returns the CPU model from /proc/cpuinfo.
+func readCPUModel(root string) string {
+	data, err := os.ReadFile(filepath.Join(root, "proc/cpuinfo"))
+	if err != nil {
+		return ""
+	}
+	var hardware string
+	for line := range strings.Lines(string(data)) {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "model name":
+			return strings.Join(strings.Fields(cpuModelNoise.ReplaceAllString(value, "")), " ")
+		case "Hardware", "Model":
+			if hardware == "" {
+				hardware = value // ARM boards have no "model name".
+			}
+		}
+	}
+	return hardware
+}
+
+// readOSRelease returns PRETTY_NAME from os-release, e.g. "Ubuntu 24.04 LTS".
+func readOSRelease(root string) string {
+	for _, path := range []string{"etc/os-release", "usr/lib/os-release"} {
+		data, err := os.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			continue
+		}
+		fields := make(map[string]string)
+		for line := range strings.Lines(string(data)) {
+			key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+			if !ok || strings.HasPrefix(key, "#") {
+				continue
+			}
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			} else {
+				value = strings.Trim(value, `'"`)
+			}
+			fields[key] = value
+		}
+		if name := fields["PRETTY_NAME"]; name != "" {
+			return name
+		}
+		if name := strings.TrimSpace(fields["NAME"] + " " + fields["VERSION_ID"]); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// readPSIMemoryPressure maps /proc/pressure/memory to normal/warn/critical.
+// It returns "" when PSI is not available (kernels before 4.20 or disabled).
+func readPSIMemoryPressure(root string) string {
+	data, err := os.ReadFile(filepath.Join(root, "proc/pressure/memory"))
+	if err != nil {
+		return ""
+	}
+	var some, full float64
+	for line := range strings.Lines(string(data)) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		avg10, ok := strings.CutPrefix(fields[1], "avg10=")
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(avg10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "some":
+			some = v
+		case "full":
+			full = v
+		}
+	}
+	switch {
+	case full >= psiFullCritical || some >= psiSomeCritical:
+		return "critical"
+	case some >= psiSomeWarn:
+		return "warn"
+	}
+	return "normal"
+}
+
+var drmCardRe = regexp.MustCompile(`^card\d+$`)
+
+// PCI vendor IDs of GPUs seen in DRM.
+var gpuVendors = map[string]string{"0x1002": "AMD", "0x8086": "Intel", "0x10de": "NVIDIA"}
+
+// readDRMGPUs returns GPUs that expose gpu_busy_percent in DRM sysfs (amdgpu
+// and some newer drivers). VRAM is reported in MiB like nvidia-smi.
+func readDRMGPUs(root string) []GPUStatus {
+	cards, _ := filepath.Glob(filepath.Join(root, "sys/class/drm/card*"))
+	slices.Sort(cards)
+	var gpus []GPUStatus
+	for _, card := range cards {
+		if !drmCardRe.MatchString(filepath.Base(card)) {
+			continue // Connectors such as card0-DP-1.
+		}
+		device := filepath.Join(card, "device")
+		busy, ok := readSysInt(filepath.Join(device, "gpu_busy_percent"))
+		if !ok {
+			continue
+		}
+		gpu := GPUStatus{Usage: float64(busy)}
+
+		vendor := gpuVendors[readTrimmed(filepath.Join(device, "vendor"))]
+		driver := ""
+		for line := range strings.Lines(readTrimmed(filepath.Join(device, "uevent"))) {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(line), "DRIVER="); ok {
+				driver = v
+			}
+		}
+		gpu.Name = strings.TrimSpace(fmt.Sprintf("%s GPU (%s)", vendor, filepath.Base(card)))
+		if driver != "" {
+			gpu.Name = strings.TrimSpace(fmt.Sprintf("%s %s (%s)", vendor, driver, filepath.Base(card)))
+		}
+
+		if used, ok := readSysInt(filepath.Join(device, "mem_info_vram_used")); ok {
+			gpu.MemoryUsed = float64(used) / (1 << 20)
+		}
+		if total, ok := readSysInt(filepath.Join(device, "mem_info_vram_total")); ok {
+			gpu.MemoryTotal = float64(total) / (1 << 20)
+		}
+		gpus = append(gpus, gpu)
+	}
+	return gpus
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+const (
+	desktopRoot = "testdata/linux/desktop"
+	laptopRoot  = "testdata/linux/laptop"
+)
+
+func TestReadLinuxThermal(t *testing.T) {
+	got := readLinuxThermal(desktopRoot)
+	want := ThermalStatus{CPUTemp: 62, GPUTemp: 51, FanSpeed: 1200, FanCount: 2}
+	if got != want {
+		t.Errorf("hwmon thermal = %+v, want %+v", got, want)
+	}
+
+	// Without hwmon the package thermal zone wins over hotter non-CPU zones.
+	if got := readLinuxThermal(laptopRoot); got.CPUTemp != 58 || got.FanCount != 0 {
+		t.Errorf("thermal zone fallback = %+v, want CPU 58", got)
+	}
+	if got := readLinuxThermal(t.TempDir()); got != (ThermalStatus{}) {
+		t.Errorf("empty root = %+v", got)
+	}
+}
+
+func TestLinuxHardware(t *testing.T) {
+	tests := []struct {
+		root string
+		want HardwareInfo
+	}{
+		{desktopRoot, HardwareInfo{Model: "Dell XPS 13 9310", CPUModel: "11th Gen Intel Core i7-1185G7", OSVersion: "Ubuntu 24.04.1 LTS"}},
+		{laptopRoot, HardwareInfo{Model: "Lenovo ThinkPad X1 Carbon Gen 9", CPUModel: "BCM2835", OSVersion: "Fedora Linux 40"}},
+	}
+	for _, tt := range tests {
+		got := linuxHardware(tt.root)
+		if got.Model != tt.want.Model || got.CPUModel != tt.want.CPUModel || got.OSVersion != tt.want.OSVersion {
+			t.Errorf("%s: linuxHardware = %+v, want %+v", tt.root, got, tt.want)
+		}
+	}
+
+	got := linuxHardware(t.TempDir())
+	if got.Model != "Unknown" || got.OSVersion != "Linux" || got.CPUModel == "" {
+		t.Errorf("empty root should fall back, got %+v", got)
+	}
+}
+
+func TestReadDMIProductNameSkipsPlaceholders(t *testing.T) {
+	root := t.TempDir()
+	dmi := filepath.Join(root, "sys/class/dmi/id")
+	if err := os.MkdirAll(dmi, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dmi, "sys_vendor"), []byte("System manufacturer\n"), 0o644)
+	os.WriteFile(filepath.Join(dmi, "product_name"), []byte("To Be Filled By O.E.M.\n"), 0o644)
+	if got := readDMIProductName(root); got != "" {
+		t.Errorf("placeholder product = %q, want empty", got)
+	}
+}
+
+func TestReadPSIMemoryPressure(t *testing.T) {
+	if got := readPSIMemoryPressure(desktopRoot); got != "warn" {
+		t.Errorf("desktop fixture = %q, want warn", got)
+	}
+	if got := readPSIMemoryPressure(laptopRoot); got != "" {
+		t.Errorf("missing PSI = %q, want empty", got)
+	}
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"some avg10=0.00 avg60=0.00 avg300=0.00 total=0\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n", "normal"},
+		{"some avg10=45.00 avg60=20.00 avg300=5.00 total=1\nfull avg10=1.00 avg60=0.00 avg300=0.00 total=1\n", "critical"},
+		{"some avg10=15.00 avg60=0.00 avg300=0.00 total=1\nfull avg10=12.00 avg60=0.00 avg300=0.00 total=1\n", "critical"},
+	}
+	for _, tt := range tests {
+		root := t.TempDir()
+		path := filepath.Join(root, "proc/pressure/memory")
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := readPSIMemoryPressure(root); got != tt.want {
+			t.Errorf("pressure for %q = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestReadDRMGPUs(t *testing.T) {
+	gpus := readDRMGPUs(desktopRoot)
+	if len(gpus) != 1 {
+		t.Fatalf("readDRMGPUs = %+v, want only card0", gpus)
+	}
+	want := GPUStatus{Name: "AMD amdgpu (card0)", Usage: 37, MemoryUsed: 1024, MemoryTotal: 8192}
+	if gpus[0] != want {
+		t.Errorf("gpu = %+v, want %+v", gpus[0], want)
+	}
+	if gpus := readDRMGPUs(laptopRoot); len(gpus) != 0 {
+		t.Errorf("no DRM should yield no GPUs, got %+v", gpus)
+	}
+
+	if runtime.GOOS != "linux" || commandExists("nvidia-smi") {
+		return
+	}
+	defer func(root string) { hostRoot = root }(hostRoot)
+	hostRoot = laptopRoot
+	if gpus, err := collectGPU(); gpus != nil || err != nil {
+		t.Errorf("without DRM or nvidia-smi the GPU card should be hidden, got %+v, %v", gpus, err)
+	}
+}
+
+func TestReadLinuxBatteries(t *testing.T) {
+	batts := readLinuxBatteries(laptopRoot)
+	if len(batts) != 2 {
+		t.Fatalf("got %d batteries, want BAT0 and BAT1 (not AC): %+v", len(batts), batts)
+	}
+	bat0 := batts[0]
+	want := BatteryStatus{Name: "BAT0", Percent: 62, Status: "Discharging", CycleCount: 312, Capacity: 86,
+		EnergyNow: 30.38, EnergyFull: 49, EnergyDesign: 57, Power: 9.8}
+	if bat0.Name != want.Name || bat0.Percent != want.Percent || bat0.Status != want.Status || bat0.CycleCount != want.CycleCount ||
+		bat0.Capacity != want.Capacity || bat0.EnergyNow != want.EnergyNow || bat0.EnergyFull != want.EnergyFull ||
+		bat0.EnergyDesign != want.EnergyDesign || bat0.Power != want.Power {
+		t.Errorf("energy_* battery = %+v, want %+v", bat0, want)
+	}
+
+	// charge_* in µAh converts through the design voltage; 2 Ah at 11.1 V.
+	bat1 := batts[1]
+	if bat1.Name != "BAT1" || bat1.Status != "Full" || bat1.Health != "Good" || bat1.Capacity != 80 ||
+		math.Abs(bat1.EnergyFull-22.2) > 1e-9 || math.Abs(bat1.EnergyDesign-27.75) > 1e-9 || bat1.Power != 0 || bat1.CycleCount != 0 {
+		t.Errorf("charge_* battery = %+v", bat1)
+	}
+
+	if got := readLinuxBatteries(desktopRoot); len(got) != 0 {
+		t.Errorf("desktop has no battery, got %+v", got)
+	}
+}
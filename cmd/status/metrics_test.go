@@ -179,3 +179,29 @@ func TestRingBuffer_NegativeAndZeroValues(t *testing.T) {
 		t.Errorf("Slice() with negative/zero values = %v, want %v", got, want)
 	}
 }
+
+func TestHistoryBuffersAdd(t *testing.T) {
+	h := newHistoryBuffers()
+	for i := range 3 {
+		h.Add(MetricsSnapshot{
+			CPU:    CPUStatus{Usage: float64(i * 10), PerCore: []float64{float64(i), 50}},
+			Memory: MemoryStatus{UsedPercent: 40, SwapUsed: 1, SwapTotal: 4},
+		})
+	}
+	hist := h.Add(MetricsSnapshot{CPU: CPUStatus{Usage: 30, PerCore: []float64{3, 50}}})
+
+	if got := hist.Series[histCPU]; len(got) != 4 || got[3] != 30 {
+		t.Errorf("cpu series = %v", got)
+	}
+	if got := hist.Series[histSwap]; len(got) != 4 || got[0] != 25 {
+		t.Errorf("swap series = %v", got)
+	}
+	if len(hist.PerCore) != 2 || len(hist.PerCore[0]) != 4 || hist.PerCore[0][3] != 3 {
+		t.Errorf("per-core series = %v", hist.PerCore)
+	}
+
+	estimated := h.Add(MetricsSnapshot{CPU: CPUStatus{PerCore: []float64{1, 1}, PerCoreEstimated: true}})
+	if estimated.PerCore != nil {
+		t.Errorf("estimated per-core values should not be graphed")
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// jsonSchemaVersion is bumped whenever a JSON field is renamed, removed or changes meaning.
+// Adding fields does not bump it.
+const jsonSchemaVersion = 1
+
+// snapshotJSON is the wire format of --json and --stream.
+type snapshotJSON struct {
+	SchemaVersion int    `json:"schema_version"`
+	Error         string `json:"error,omitempty"` // Partial collection failures
+	MetricsSnapshot
+}
+
+// snapshotSource returns the next metrics sample.
+type snapshotSource func() (MetricsSnapshot, error)
+
+func newSnapshotJSON(snapshot MetricsSnapshot, err error) snapshotJSON {
+	out := snapshotJSON{
+		SchemaVersion:   jsonSchemaVersion,
+		MetricsSnapshot: snapshot,
+	}
+	if err != nil {
+		out.Error = err.Error()
+	}
+	return out
+}
+
+// writeSnapshotJSON prints one snapshot. Rates (network, disk IO) need two
+// samples, so the source is primed and sampled again after warmup.
+func writeSnapshotJSON(w io.Writer, collect snapshotSource, warmup time.Duration) error {
+	if warmup > 0 {
+		_, _ = collect()
+		time.Sleep(warmup)
+	}
+	snapshot, err := collect()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(newSnapshotJSON(snapshot, err))
+}
+
+// streamSnapshotsJSON prints one snapshot per line every interval until ctx is done.
+func streamSnapshotsJSON(ctx context.Context, w io.Writer, collect snapshotSource, interval time.Duration) error {
+	// Prime rate counters so the first line has real network and disk IO rates.
+	_, _ = collect()
+
+	enc := json.NewEncoder(w)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		snapshot, err := collect()
+		if encErr := enc.Encode(newSnapshotJSON(snapshot, err)); encErr != nil {
+			return encErr
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSnapshotJSONFieldNames(t *testing.T) {
+	snapshot := MetricsSnapshot{
+		Host:    "box",
+		CPU:     CPUStatus{Usage: 12.5, Load1: 1},
+		Memory:  MemoryStatus{UsedPercent: 40},
+		Disks:   []DiskStatus{{Mount: "/", UsedPercent: 70}},
+		Network: []NetworkStatus{{Name: "en0", RxRateMBs: 1.5}},
+		DiskIO:  DiskIOStatus{ReadRate: 2},
+	}
+	data, err := json.Marshal(newSnapshotJSON(snapshot, errors.New("gpu: timeout")))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	// These keys are a public contract; renaming one requires a jsonSchemaVersion bump.
+	wantKeys := []string{
+		"schema_version", "error", "collected_at", "host", "platform", "uptime", "procs",
+		"hardware", "health_score", "health_score_msg", "health_breakdown", "cpu", "gpu", "memory", "disks",
+		"disk_io", "network", "network_history", "proxy", "batteries", "thermal",
+		"sensors", "bluetooth", "top_processes", "top_io", "container", "docker", "network_config", "probes",
+	}
+	for _, key := range wantKeys {
+		if _, ok := got[key]; !ok {
+			t.Errorf("missing top-level key %q", key)
+		}
+	}
+	if got["schema_version"] != float64(jsonSchemaVersion) {
+		t.Errorf("schema_version = %v, want %d", got["schema_version"], jsonSchemaVersion)
+	}
+	if got["error"] != "gpu: timeout" {
+		t.Errorf("error = %v", got["error"])
+	}
+
+	cpu := got["cpu"].(map[string]any)
+	if cpu["usage"] != 12.5 || cpu["load1"] != 1.0 {
+		t.Errorf("unexpected cpu object: %v", cpu)
+	}
+	netw := got["network"].([]any)[0].(map[string]any)
+	if netw["rx_rate_mbs"] != 1.5 {
+		t.Errorf("unexpected network object: %v", netw)
+	}
+	if got["disk_io"].(map[string]any)["read_rate_mbs"] != 2.0 {
+		t.Errorf("unexpected disk_io object: %v", got["disk_io"])
+	}
+}
+
+func TestSnapshotJSONLeavesInterfaceHistoryToTheScreen(t *testing.T) {
+	snapshot := MetricsSnapshot{NetworkHistory: NetworkHistory{
+		RxHistory:  []float64{1},
+		Interfaces: map[string]NetworkHistory{"en0": {RxHistory: []float64{1, 2}}},
+	}}
+	data, err := json.Marshal(newSnapshotJSON(snapshot, nil))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if bytes.Contains(data, []byte(`"interfaces"`)) || !bytes.Contains(data, []byte(`"rx_history":[1]`)) {
+		t.Errorf("network_history should carry only the totals: %s", data)
+	}
+}
+
+func TestSnapshotJSONOmitsEmptyError(t *testing.T) {
+	data, err := json.Marshal(newSnapshotJSON(MetricsSnapshot{}, nil))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if bytes.Contains(data, []byte(`"error"`)) {
+		t.Errorf("error field should be omitted when collection succeeds: %s", data)
+	}
+}
+
+func TestWriteSnapshotJSONPrimesRates(t *testing.T) {
+	calls := 0
+	collect := func() (MetricsSnapshot, error) {
+		calls++
+		return MetricsSnapshot{Procs: uint64(calls)}, nil
+	}
+
+	var buf bytes.Buffer
+	if err := writeSnapshotJSON(&buf, collect, time.Millisecond); err != nil {
+		t.Fatalf("writeSnapshotJSON: %v", err)
+	}
+	var got snapshotJSON
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if calls != 2 || got.Procs != 2 {
+		t.Errorf("expected the second sample to be printed, got calls=%d procs=%d", calls, got.Procs)
+	}
+}
+
+// lockedBuffer guards a bytes.Buffer written by the stream goroutine.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []string
+	scanner := bufio.NewScanner(bytes.NewReader(b.buf.Bytes()))
+	for scanner.Scan() {
+		out = append(out, scanner.Text())
+	}
+	return out
+}
+
+func TestStreamSnapshotsJSON(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	collect := func() (MetricsSnapshot, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return MetricsSnapshot{Procs: uint64(calls)}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &lockedBuffer{}
+	done := make(chan error, 1)
+	go func() { done <- streamSnapshotsJSON(ctx, out, collect, 5*time.Millisecond) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(out.lines()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("streamSnapshotsJSON: %v", err)
+	}
+
+	lines := out.lines()
+	if len(lines) < 3 {
+		t.Fatalf("expected at least 3 lines, got %d", len(lines))
+	}
+	var procs []uint64
+	for _, line := range lines {
+		var got snapshotJSON
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("line is not a JSON object: %q: %v", line, err)
+		}
+		if got.SchemaVersion != jsonSchemaVersion {
+			t.Errorf("schema_version = %d", got.SchemaVersion)
+		}
+		procs = append(procs, got.Procs)
+	}
+	// The priming sample (procs=1) is never printed.
+	if procs[0] != 2 || !slices.IsSorted(procs) {
+		t.Errorf("unexpected sample order: %v", procs)
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/host"
+)
+
+// Update merges a provider's result into a snapshot. Updates are applied in
+// registration order, so a provider may read fields set by earlier ones.
+type Update func(*MetricsSnapshot)
+
+// Provider collects one part of MetricsSnapshot on its own schedule.
+type Provider interface {
+	Name() string
+	Interval() time.Duration // Minimum time between runs
+	Timeout() time.Duration  // How long a run may take before its context is cancelled
+	Supported() bool         // False skips the provider on this platform
+	// Collect returns the update to apply. A nil update keeps the previous result.
+	Collect(ctx context.Context, now time.Time) (Update, error)
+}
+
+type funcProvider struct {
+	name      string
+	interval  time.Duration
+	timeout   time.Duration
+	platforms []string // GOOS values; empty means every platform
+	collect   func(ctx context.Context, now time.Time) (Update, error)
+}
+
+// NewProvider builds a Provider from a function. With no platforms it runs everywhere.
+func NewProvider(name string, interval, timeout time.Duration, platforms []string, collect func(ctx context.Context, now time.Time) (Update, error)) Provider {
+	return funcProvider{name: name, interval: interval, timeout: timeout, platforms: platforms, collect: collect}
+}
+
+func (p funcProvider) Name() string            { return p.name }
+func (p funcProvider) Interval() time.Duration { return p.interval }
+func (p funcProvider) Timeout() time.Duration  { return p.timeout }
+
+func (p funcProvider) Supported() bool {
+	return len(p.platforms) == 0 || slices.Contains(p.platforms, runtime.GOOS)
+}
+
+func (p funcProvider) Collect(ctx context.Context, now time.Time) (Update, error) {
+	return p.collect(ctx, now)
+}
+
+// CardRenderer draws an extra dashboard card. ok false hides it for this snapshot.
+type CardRenderer func(m MetricsSnapshot, width int) (card cardData, ok bool)
+
+var (
+	registryMu          sync.Mutex
+	registeredProviders []Provider
+	registeredCards     []CardRenderer
+)
+
+// RegisterProvider adds a provider to collectors created afterwards. Call it
+// from an init function; results usually go in MetricsSnapshot.Extensions.
+func RegisterProvider(p Provider) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registeredProviders = append(registeredProviders, p)
+}
+
+// RegisterCard adds a card after the built-in ones.
+func RegisterCard(render CardRenderer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registeredCards = append(registeredCards, render)
+}
+
+func extraCards() []CardRenderer {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return slices.Clone(registeredCards)
+}
+
+// SetExtension stores a registered provider's value under its name.
+func (m *MetricsSnapshot) SetExtension(name string, value any) {
+	if m.Extensions == nil {
+		m.Extensions = make(map[string]any)
+	}
+	m.Extensions[name] = value
+}
+
+// builtinProviders lists the collectors behind every built-in card.
+func (c *Collector) builtinProviders() []Provider {
+	cgroups := newCgroupSampler(hostRoot)
+	docker := newDockerSampler(dockerHost)
+	targets, probesErr := loadProbeTargets(probeTargetsFile)
+	probes := newProber(targets)
+	return []Provider{
+		NewProvider("host", time.Second, time.Second, nil, func(ctx context.Context, _ time.Time) (Update, error) {
+			info, err := host.InfoWithContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return func(s *MetricsSnapshot) {
+				s.Host = info.Hostname
+				s.Platform = fmt.Sprintf("%s %s", info.Platform, info.PlatformVersion)
+				s.Uptime = formatUptime(info.Uptime)
+				s.Procs = info.Procs
+			}, nil
+		}),
+		NewProvider("cpu", time.Second, time.Second, nil, func(ctx context.Context, _ time.Time) (Update, error) {
+			stats, err := collectCPU(ctx)
+			return func(s *MetricsSnapshot) { s.CPU = stats }, err
+		}),
+		NewProvider("memory", time.Second, time.Second, nil, func(ctx context.Context, _ time.Time) (Update, error) {
+			stats, err := collectMemory(ctx)
+			return func(s *MetricsSnapshot) { s.Memory = stats }, err
+		}),
+		NewProvider("disks", 5*time.Second, 2*time.Second, nil, func(_ context.Context, now time.Time) (Update, error) {
+			mounts, err := collectDisks()
+			c.diskTrend.Annotate(mounts, now)
+			disks := dataVolumes(mounts)
+			return func(s *MetricsSnapshot) {
+				s.Mounts = mounts
+				s.Disks = disks
+			}, err
+		}),
+		NewProvider("disk_io", time.Second, time.Second, nil, func(_ context.Context, now time.Time) (Update, error) {
+			io, devices := c.collectDiskIO(now)
+			return func(s *MetricsSnapshot) {
+				s.DiskIO = io
+				s.Mounts = withDeviceRates(s.Mounts, devices)
+				s.Disks = withDeviceRates(s.Disks, devices)
+			}, nil
+		}),
+		NewProvider("network", time.Second, time.Second, nil, func(_ context.Context, now time.Time) (Update, error) {
+			stats, err := c.collectNetwork(now)
+			history := NetworkHistory{RxHistory: c.rxHistoryBuf.Slice(), TxHistory: c.txHistoryBuf.Slice(), Interfaces: c.ifaceHistory.Snapshot()}
+			return func(s *MetricsSnapshot) {
+				s.Network = stats
+				s.NetworkHistory = history
+			}, err
+		}),
+		NewProvider("container", time.Second, time.Second, []string{"linux"}, func(_ context.Context, now time.Time) (Update, error) {
+			container := cgroups.Sample(now)
+			return func(s *MetricsSnapshot) { s.Container = container }, nil
+		}),
+		NewProvider("docker", dockerInterval, dockerTimeout, nil, func(ctx context.Context, now time.Time) (Update, error) {
+			status, err := docker.Sample(ctx, now)
+			return func(s *MetricsSnapshot) { s.Docker = status }, err
+		}),
+		// Disk usage is slow to compute, so it refreshes apart from the container stats.
+		NewProvider("docker_df", dockerDFInterval, dockerDFTimeout, nil, func(ctx context.Context, _ time.Time) (Update, error) {
+			usage, err := docker.DiskUsage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return func(s *MetricsSnapshot) {
+				if s.Docker == nil || usage == nil {
+					return
+				}
+				d := *s.Docker
+				d.Images, d.Volumes, d.BuildCache = usage.images, usage.volumes, usage.buildCache
+				s.Docker = &d
+			}, nil
+		}),
+		NewProvider("network_config", 30*time.Second, time.Second, nil, func(ctx context.Context, _ time.Time) (Update, error) {
+			cfg := collectNetworkConfig(ctx)
+			return func(s *MetricsSnapshot) { s.NetworkConfig = cfg }, nil
+		}),
+		NewProvider("probes", probeInterval, probeTimeout+time.Second, nil, func(ctx context.Context, _ time.Time) (Update, error) {
+			if probesErr != nil {
+				return nil, probesErr
+			}
+			ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+			defer cancel()
+			results := probes.Run(ctx)
+			return func(s *MetricsSnapshot) { s.Probes = results }, nil
+		}),
+		NewProvider("connections", 2*time.Second, connectionsTimeout, nil, func(ctx context.Context, _ time.Time) (Update, error) {
+			if !c.connectionDetail.Load() {
+				return func(s *MetricsSnapshot) { s.Connections = nil }, nil
+			}
+			conns, err := c.connections.Sample(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return func(s *MetricsSnapshot) { s.Connections = conns }, nil
+		}),
+		NewProvider("proxy", 10*time.Second, time.Second, nil, func(context.Context, time.Time) (Update, error) {
+			proxy := collectProxy()
+			return func(s *MetricsSnapshot) { s.Proxy = proxy }, nil
+		}),
+		NewProvider("battery", 5*time.Second, 3*time.Second, nil, func(ctx context.Context, now time.Time) (Update, error) {
+			batts, _ := collectBatteries(ctx) // Desktops have none
+			err := c.batteries.Annotate(batts, now)
+			return func(s *MetricsSnapshot) { s.Batteries = batts }, err
+		}),
+		NewProvider("thermal", 2*time.Second, 2*time.Second, nil, func(ctx context.Context, _ time.Time) (Update, error) {
+			thermal := collectThermal(ctx)
+			return func(s *MetricsSnapshot) { s.Thermal = thermal }, nil
+		}),
+		NewProvider("gpu_info", macGPUInfoTTL, systemProfilerTimeout, []string{"darwin"}, func(context.Context, time.Time) (Update, error) {
+			gpus, err := readMacGPUInfo()
+			if err != nil || len(gpus) == 0 {
+				return nil, err
+			}
+			return func(s *MetricsSnapshot) { s.GPU = gpus }, nil
+		}),
+		NewProvider("gpu", time.Second, powermetricsTimeout, nil, func(context.Context, time.Time) (Update, error) {
+			if runtime.GOOS == "darwin" {
+				usage := getMacGPUUsage()
+				return func(s *MetricsSnapshot) {
+					// Usage belongs to the first GPU listed by gpu_info (Apple Silicon).
+					if len(s.GPU) > 0 {
+						s.GPU = slices.Clone(s.GPU)
+						s.GPU[0].Usage = usage
+					}
+				}, nil
+			}
+			gpus, err := collectGPU()
+			return func(s *MetricsSnapshot) { s.GPU = gpus }, err
+		}),
+		NewProvider("bluetooth", bluetoothCacheTTL, systemProfilerTimeout+bluetoothctlTimeout, nil, func(context.Context, time.Time) (Update, error) {
+			devices := collectBluetooth()
+			return func(s *MetricsSnapshot) { s.Bluetooth = devices }, nil
+		}),
+		// Hardware rarely changes; it runs after memory and disks to size them.
+		NewProvider("hardware", 10*time.Minute, 5*time.Second, nil, func(ctx context.Context, _ time.Time) (Update, error) {
+			hw := collectHardware(ctx)
+			return func(s *MetricsSnapshot) {
+				s.Hardware = hw
+				s.Hardware.TotalRAM = humanBytes(s.Memory.Total)
+				s.Hardware.DiskSize = "Unknown"
+				if len(s.Disks) > 0 {
+					s.Hardware.DiskSize = humanBytes(s.Disks[0].Total)
+				}
+			}, nil
+		}),
+		NewProvider("processes", time.Second, processTimeout, nil, func(context.Context, time.Time) (Update, error) {
+			procs, err := c.procs.Sample(c.processDetail.Load())
+			if err != nil {
+				return nil, err
+			}
+			return func(s *MetricsSnapshot) { s.Processes = procs }, nil
+		}),
+		NewProvider("process_net", 2*time.Second, processNetTimeout, []string{"darwin", "linux"}, func(ctx context.Context, _ time.Time) (Update, error) {
+			top, err := c.procNet.Sample(ctx, topNetCount)
+			return func(s *MetricsSnapshot) { s.TopNet = top }, err
+		}),
+	}
+}
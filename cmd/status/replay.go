@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	replaySeekStep = 10 * time.Second
+	// Recorded gaps are clamped so a suspended laptop does not freeze playback.
+	replayMaxGap = 5 * time.Second
+)
+
+// snapshotRecorder appends snapshots in the --stream format, one JSON object per line.
+type snapshotRecorder struct {
+	file *os.File
+	enc  *json.Encoder
+}
+
+func openSnapshotRecorder(path string) (*snapshotRecorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshotRecorder{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Record writes one snapshot. A nil recorder does nothing.
+func (r *snapshotRecorder) Record(snapshot MetricsSnapshot, err error) error {
+	if r == nil {
+		return nil
+	}
+	return r.enc.Encode(newSnapshotJSON(snapshot, err))
+}
+
+func (r *snapshotRecorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	return r.file.Close()
+}
+
+// replayFrame is one recorded snapshot.
+type replayFrame struct {
+	snapshot MetricsSnapshot
+	err      string
+}
+
+// replayLog is a loaded recording. Output of --record and --stream both work.
+type replayLog struct {
+	frames  []replayFrame
+	samples []historySample // For rebuilding graph history at any position
+}
+
+func loadReplay(path string) (*replayLog, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseReplay(file)
+}
+
+func parseReplay(r io.Reader) (*replayLog, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	log := &replayLog{}
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var rec snapshotJSON
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if rec.SchemaVersion > jsonSchemaVersion {
+			return nil, fmt.Errorf("line %d: schema version %d is newer than this build (%d)", line, rec.SchemaVersion, jsonSchemaVersion)
+		}
+		log.frames = append(log.frames, replayFrame{snapshot: rec.MetricsSnapshot, err: rec.Error})
+		log.samples = append(log.samples, sampleFromSnapshot(rec.MetricsSnapshot))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(log.frames) == 0 {
+		return nil, errors.New("no snapshots recorded")
+	}
+	return log, nil
+}
+
+// frame returns the snapshot at pos with its graph history rebuilt, as the
+// collector would have had it when the snapshot was taken.
+func (l *replayLog) frame(pos int) replayFrame {
+	f := l.frames[pos]
+	start := max(pos-MetricsHistorySize+1, 0)
+
+	var hist MetricsHistory
+	for i := range hist.Series {
+		series := make([]float64, 0, pos-start+1)
+		for _, s := range l.samples[start : pos+1] {
+			series = append(series, s.Values[i])
+		}
+		hist.Series[i] = series
+	}
+	if cpu := f.snapshot.CPU; !cpu.PerCoreEstimated {
+		hist.PerCore = make([][]float64, len(cpu.PerCore))
+		for core := range cpu.PerCore {
+			for _, prev := range l.frames[start : pos+1] {
+				if core < len(prev.snapshot.CPU.PerCore) {
+					hist.PerCore[core] = append(hist.PerCore[core], prev.snapshot.CPU.PerCore[core])
+				}
+			}
+		}
+	}
+	f.snapshot.History = hist
+	return f
+}
+
+// elapsed is the recording time from the first frame to pos.
+func (l *replayLog) elapsed(pos int) time.Duration {
+	return l.frames[pos].snapshot.CollectedAt.Sub(l.frames[0].snapshot.CollectedAt)
+}
+
+// seek returns the first frame at or after the recording offset.
+func (l *replayLog) seek(offset time.Duration) int {
+	at := l.frames[0].snapshot.CollectedAt.Add(offset)
+	pos := sort.Search(len(l.frames), func(i int) bool {
+		return !l.frames[i].snapshot.CollectedAt.Before(at)
+	})
+	return min(pos, len(l.frames)-1)
+}
+
+// delay is how long frame pos stays on screen at speed.
+func (l *replayLog) delay(pos int, speed float64) time.Duration {
+	gap := refreshInterval
+	if pos+1 < len(l.frames) {
+		if d := l.frames[pos+1].snapshot.CollectedAt.Sub(l.frames[pos].snapshot.CollectedAt); d > 0 {
+			gap = min(d, replayMaxGap)
+		}
+	}
+	return time.Duration(float64(gap) / speed)
+}
+
+// parseReplaySpeed accepts "4x", "4" or "0.5x".
+func parseReplaySpeed(s string) (float64, error) {
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "x"), 64)
+	if err != nil || speed <= 0 {
+		return 0, fmt.Errorf("invalid speed %q (want e.g. 4x)", s)
+	}
+	return speed, nil
+}
+
+// replayState is the playback position of --replay. log is nil when live.
+type replayState struct {
+	log    *replayLog
+	pos    int
+	speed  float64
+	paused bool
+	gen    int // Invalidates ticks scheduled before a pause or seek
+}
+
+type replayTickMsg struct{ gen int }
+
+func (r replayState) tick() tea.Cmd {
+	gen := r.gen
+	return tea.Tick(r.log.delay(r.pos, r.speed), func(time.Time) tea.Msg { return replayTickMsg{gen: gen} })
+}
+
+// newReplayModel plays a recording instead of collecting.
+func newReplayModel(log *replayLog, speed float64) model {
+	m := model{replay: replayState{log: log, speed: speed}}
+	return m.showReplayFrame()
+}
+
+func (m model) showReplayFrame() model {
+	f := m.replay.log.frame(m.replay.pos)
+	m.metrics = f.snapshot
+	m.lastUpdated = f.snapshot.CollectedAt
+	m.errMessage = f.err
+	m.ready = true
+	return m
+}
+
+// updateReplay handles playback ticks and keys. handled is false for keys
+// that the dashboard should process instead.
+func (m model) updateReplay(msg tea.Msg) (model, tea.Cmd, bool) {
+	r := &m.replay
+	switch msg := msg.(type) {
+	case replayTickMsg:
+		if msg.gen != r.gen || r.paused {
+			return m, nil, true
+		}
+		if r.pos+1 >= len(r.log.frames) {
+			r.paused = true
+			return m, nil, true
+		}
+		r.pos++
+		return m.showReplayFrame(), r.tick(), true
+	case tea.KeyMsg:
+		last := len(r.log.frames) - 1
+		switch msg.String() {
+		case " ":
+			r.paused = !r.paused
+			if !r.paused && r.pos == last {
+				r.pos = 0 // Play again from the start.
+			}
+		case "left":
+			r.pos = r.log.seek(r.log.elapsed(r.pos) - replaySeekStep)
+		case "right":
+			r.pos = r.log.seek(r.log.elapsed(r.pos) + replaySeekStep)
+		case "home":
+			r.pos = 0
+		case "end":
+			r.pos = last
+		default:
+			return m, nil, false
+		}
+		r.gen++
+		m = m.showReplayFrame()
+		if m.replay.paused {
+			return m, nil, true
+		}
+		return m, m.replay.tick(), true
+	}
+	return m, nil, false
+}
+
+func formatReplayClock(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d >= time.Hour {
+		return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// renderReplayBar shows the playback position under the header.
+func renderReplayBar(r replayState) string {
+	state := okStyle.Render("▶ Replay")
+	if r.paused {
+		state = warnStyle.Render("⏸ Paused")
+	}
+	last := len(r.log.frames) - 1
+	info := fmt.Sprintf("%s / %s · frame %d/%d · %sx",
+		formatReplayClock(r.log.elapsed(r.pos)), formatReplayClock(r.log.elapsed(last)),
+		r.pos+1, last+1, strconv.FormatFloat(r.speed, 'f', -1, 64))
+	return state + "  " + subtleStyle.Render(info+" · space pause · ←→ seek 10s")
+}
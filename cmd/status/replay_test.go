@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata/golden")
+
+var ansiRe = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// checkGolden compares plain-text output with testdata/golden/name.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	got = ansiRe.ReplaceAllString(got, "") + "\n"
+	path := filepath.Join("testdata", "golden", name)
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch (run go test -update to accept)\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func loadTestReplay(t *testing.T) *replayLog {
+	t.Helper()
+	log, err := loadReplay(filepath.Join("testdata", "replay", "session.jsonl"))
+	if err != nil {
+		t.Fatalf("loadReplay: %v", err)
+	}
+	return log
+}
+
+func TestParseReplayErrors(t *testing.T) {
+	tests := []struct {
+		data    string
+		wantErr string
+	}{
+		{"", "no snapshots"},
+		{`{"schema_version":1}` + "\n" + `{"cpu":`, "line 2"},
+		{`{"schema_version":99}`, "newer than this build"},
+	}
+	for _, tt := range tests {
+		if _, err := parseReplay(strings.NewReader(tt.data)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("parseReplay(%q) error = %v, want %q", tt.data, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSnapshotRecorderRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	rec, err := openSnapshotRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	_ = rec.Record(MetricsSnapshot{CollectedAt: at, CPU: CPUStatus{Usage: 10}}, nil)
+	_ = rec.Record(MetricsSnapshot{CollectedAt: at.Add(time.Second), CPU: CPUStatus{Usage: 30}}, errors.New("disks: timeout"))
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := loadReplay(path)
+	if err != nil {
+		t.Fatalf("loadReplay: %v", err)
+	}
+	f := log.frame(1)
+	if len(log.frames) != 2 || f.err != "disks: timeout" || !f.snapshot.CollectedAt.Equal(at.Add(time.Second)) {
+		t.Fatalf("frames = %+v", log.frames)
+	}
+	if got := f.snapshot.History.Series[histCPU]; !slices.Equal(got, []float64{10, 30}) {
+		t.Errorf("rebuilt cpu history = %v", got)
+	}
+}
+
+func TestReplaySeek(t *testing.T) {
+	log := loadTestReplay(t)
+	if got := log.seek(replaySeekStep); got != 5 {
+		t.Errorf("seek(10s) = frame %d, want 5", got)
+	}
+	if got := log.seek(-replaySeekStep); got != 0 {
+		t.Errorf("seek before start = frame %d, want 0", got)
+	}
+	if got := log.seek(time.Hour); got != 5 {
+		t.Errorf("seek past end = frame %d, want 5", got)
+	}
+	if got := log.delay(0, 4); got != 500*time.Millisecond {
+		t.Errorf("2s gap at 4x = %v, want 500ms", got)
+	}
+	if got := log.frame(3).snapshot.History.Series[histCPU]; !slices.Equal(got, []float64{20, 34, 48, 62}) {
+		t.Errorf("history at frame 3 = %v", got)
+	}
+}
+
+func TestParseReplaySpeed(t *testing.T) {
+	for in, want := range map[string]float64{"4x": 4, "0.5x": 0.5, "2": 2} {
+		if got, err := parseReplaySpeed(in); err != nil || got != want {
+			t.Errorf("parseReplaySpeed(%q) = %v, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"fast", "0x", "-1"} {
+		if _, err := parseReplaySpeed(in); err == nil {
+			t.Errorf("parseReplaySpeed(%q) should fail", in)
+		}
+	}
+}
+
+func replayUpdate(t *testing.T, m model, msg tea.Msg) (model, tea.Cmd) {
+	t.Helper()
+	next, cmd := m.Update(msg)
+	return next.(model), cmd
+}
+
+func TestReplayPlayback(t *testing.T) {
+	m := newReplayModel(loadTestReplay(t), 4)
+	if m.metrics.CPU.Usage != 20 || !m.ready {
+		t.Fatalf("replay should start on the first frame, got cpu %v", m.metrics.CPU.Usage)
+	}
+
+	m, cmd := replayUpdate(t, m, replayTickMsg{gen: m.replay.gen})
+	if m.replay.pos != 1 || cmd == nil {
+		t.Fatalf("tick should advance and schedule the next frame: pos %d", m.replay.pos)
+	}
+
+	m, _ = replayUpdate(t, m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if !m.replay.paused {
+		t.Fatal("space should pause")
+	}
+	m, _ = replayUpdate(t, m, replayTickMsg{gen: m.replay.gen})
+	if m.replay.pos != 1 {
+		t.Errorf("paused replay advanced to %d", m.replay.pos)
+	}
+
+	m, _ = replayUpdate(t, m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	stale := m.replay.gen
+	m, _ = replayUpdate(t, m, tea.KeyMsg{Type: tea.KeyRight})
+	if m.replay.pos != 5 || m.errMessage != "gpu: nvidia-smi timed out" {
+		t.Errorf("seek should show frame 5 with its error: pos %d err %q", m.replay.pos, m.errMessage)
+	}
+	if m, _ = replayUpdate(t, m, replayTickMsg{gen: stale}); m.replay.paused {
+		t.Errorf("a tick from before the seek should be ignored")
+	}
+	if m, _ = replayUpdate(t, m, replayTickMsg{gen: m.replay.gen}); !m.replay.paused || m.replay.pos != 5 {
+		t.Errorf("playback should pause on the last frame")
+	}
+
+	if _, cmd := replayUpdate(t, m, keyRunes("q")); cmd == nil {
+		t.Errorf("q should still quit during replay")
+	}
+}
+
+func TestReplayGoldenView(t *testing.T) {
+	log := loadTestReplay(t)
+	for _, width := range []int{80, 120} {
+		m := newReplayModel(log, 1)
+		m.catHidden = true
+		m, _ = replayUpdate(t, m, tea.WindowSizeMsg{Width: width, Height: 40})
+		for range 3 {
+			m, _ = replayUpdate(t, m, replayTickMsg{gen: m.replay.gen})
+		}
+		checkGolden(t, fmt.Sprintf("replay_%d.txt", width), m.View())
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// collectWait is how long a collection waits for providers it started.
+	// Runs still going afterwards land in a later snapshot.
+	collectWait = 500 * time.Millisecond
+	// scheduleSlack lets a provider run on a tick that arrives slightly early.
+	scheduleSlack = 100 * time.Millisecond
+)
+
+// scheduler runs each provider at its own interval and keeps its latest result.
+type scheduler struct {
+	mu      sync.Mutex
+	entries []*providerEntry
+	wait    time.Duration
+}
+
+type providerEntry struct {
+	provider Provider
+	running  bool
+	lastRun  time.Time
+	ran      bool // Finished at least once
+	update   Update
+	err      error
+}
+
+func newScheduler(providers []Provider) *scheduler {
+	s := &scheduler{wait: collectWait}
+	for _, p := range providers {
+		if p.Supported() {
+			s.entries = append(s.entries, &providerEntry{provider: p})
+		}
+	}
+	return s
+}
+
+// Run starts the providers due at now, waits for them briefly, and returns
+// the latest update of every provider in order with their current errors.
+// A provider still running from an earlier call is not started again, so a
+// slow one never holds back the others.
+func (s *scheduler) Run(now time.Time) ([]Update, error) {
+	type started struct {
+		done  chan struct{}
+		until time.Time
+	}
+	var runs []started
+
+	s.mu.Lock()
+	for _, e := range s.entries {
+		if e.running || (!e.lastRun.IsZero() && now.Sub(e.lastRun)+scheduleSlack < e.provider.Interval()) {
+			continue
+		}
+		e.running = true
+		e.lastRun = now
+		// The first run is waited for in full so the first snapshot is complete.
+		wait := e.provider.Timeout()
+		if e.ran {
+			wait = min(wait, s.wait)
+		}
+		done := make(chan struct{})
+		runs = append(runs, started{done: done, until: time.Now().Add(wait)})
+		go s.run(e, now, done)
+	}
+	s.mu.Unlock()
+
+	for _, r := range runs {
+		timer := time.NewTimer(time.Until(r.until))
+		select {
+		case <-r.done:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var (
+		updates  []Update
+		mergeErr error
+	)
+	for _, e := range s.entries {
+		if e.update != nil {
+			updates = append(updates, e.update)
+		}
+		if e.err == nil {
+			continue
+		}
+		err := fmt.Errorf("%s: %w", e.provider.Name(), e.err)
+		if mergeErr == nil {
+			mergeErr = err
+		} else {
+			mergeErr = fmt.Errorf("%v; %w", mergeErr, err)
+		}
+	}
+	return updates, mergeErr
+}
+
+func (s *scheduler) run(e *providerEntry, now time.Time, done chan struct{}) {
+	defer close(done)
+	ctx, cancel := context.WithTimeout(context.Background(), e.provider.Timeout())
+	defer cancel()
+
+	var (
+		update Update
+		err    error
+	)
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		update, err = e.provider.Collect(ctx, now)
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.running = false
+	e.ran = true
+	e.err = err
+	if update != nil {
+		e.update = update
+	}
+}
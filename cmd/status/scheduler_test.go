@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func countingProvider(name string, interval time.Duration, calls *atomic.Int32) Provider {
+	return NewProvider(name, interval, time.Second, nil, func(context.Context, time.Time) (Update, error) {
+		n := calls.Add(1)
+		return func(s *MetricsSnapshot) { s.SetExtension(name, n) }, nil
+	})
+}
+
+func apply(updates []Update) MetricsSnapshot {
+	var s MetricsSnapshot
+	for _, u := range updates {
+		u(&s)
+	}
+	return s
+}
+
+func TestSchedulerIntervals(t *testing.T) {
+	var fast, slow atomic.Int32
+	s := newScheduler([]Provider{
+		countingProvider("fast", time.Second, &fast),
+		countingProvider("slow", 10*time.Second, &slow),
+		NewProvider("elsewhere", time.Second, time.Second, []string{"plan9"}, func(context.Context, time.Time) (Update, error) {
+			t.Error("unsupported provider ran")
+			return nil, nil
+		}),
+	})
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 11 {
+		// Ticks arriving a little early still count as due.
+		updates, err := s.Run(start.Add(time.Duration(i)*time.Second - 20*time.Millisecond))
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if got := apply(updates).Extensions["slow"]; got == nil {
+			t.Fatalf("tick %d: slow provider's last value should be kept", i)
+		}
+	}
+	if fast.Load() != 11 || slow.Load() != 2 {
+		t.Errorf("runs = fast %d, slow %d; want 11 and 2", fast.Load(), slow.Load())
+	}
+}
+
+func TestSchedulerSlowProviderDoesNotStall(t *testing.T) {
+	release := make(chan struct{})
+	var fast atomic.Int32
+	s := newScheduler([]Provider{
+		countingProvider("fast", time.Second, &fast),
+		NewProvider("hung", time.Second, 50*time.Millisecond, nil, func(context.Context, time.Time) (Update, error) {
+			<-release
+			return func(s *MetricsSnapshot) { s.Host = "late" }, nil
+		}),
+	})
+	s.wait = 10 * time.Millisecond
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// The first run waits up to the hung provider's timeout, never longer.
+	began := time.Now()
+	updates, _ := s.Run(now)
+	if elapsed := time.Since(began); elapsed > time.Second {
+		t.Fatalf("first Run took %v", elapsed)
+	}
+	if snap := apply(updates); snap.Host != "" || snap.Extensions["fast"] != int32(1) {
+		t.Errorf("first snapshot = host %q, ext %v", snap.Host, snap.Extensions)
+	}
+
+	// While it is still running, later ticks return promptly with fresh fast values.
+	for i := 1; i <= 3; i++ {
+		began = time.Now()
+		updates, _ = s.Run(now.Add(time.Duration(i) * time.Second))
+		if elapsed := time.Since(began); elapsed > 200*time.Millisecond {
+			t.Errorf("tick %d stalled for %v", i, elapsed)
+		}
+		if got := apply(updates).Extensions["fast"]; got != int32(i+1) {
+			t.Errorf("tick %d: fast = %v", i, got)
+		}
+	}
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for apply(updates).Host != "late" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		updates, _ = s.Run(now.Add(4 * time.Second))
+	}
+	if apply(updates).Host != "late" {
+		t.Errorf("late result should land in a later snapshot")
+	}
+}
+
+func TestSchedulerKeepsLastValueOnError(t *testing.T) {
+	fail := false
+	s := newScheduler([]Provider{
+		NewProvider("sensor", time.Second, time.Second, nil, func(context.Context, time.Time) (Update, error) {
+			if fail {
+				return nil, errors.New("device busy")
+			}
+			return func(s *MetricsSnapshot) { s.Thermal.CPUTemp = 60 }, nil
+		}),
+		NewProvider("broken", time.Hour, time.Second, nil, func(context.Context, time.Time) (Update, error) {
+			panic("bad sysfs")
+		}),
+	})
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := s.Run(now); err == nil || !strings.Contains(err.Error(), "broken: panic: bad sysfs") {
+		t.Errorf("panic should be reported with the provider name, got %v", err)
+	}
+
+	fail = true
+	updates, err := s.Run(now.Add(time.Second))
+	if err == nil || !strings.Contains(err.Error(), "sensor: device busy") {
+		t.Errorf("error = %v", err)
+	}
+	if got := apply(updates).Thermal.CPUTemp; got != 60 {
+		t.Errorf("failed run should keep the previous value, got %v", got)
+	}
+}
+
+func TestRegisteredCardIsRendered(t *testing.T) {
+	defer func(cards []CardRenderer) { registeredCards = cards }(registeredCards)
+	RegisterCard(func(m MetricsSnapshot, _ int) (cardData, bool) {
+		builds, ok := m.Extensions["ci"].(int)
+		return cardData{title: "CI", lines: []string{"Builds " + strings.Repeat("▮", builds)}}, ok
+	})
+
+	if cards := buildCards(MetricsSnapshot{}, MetricsHistory{}, graphWindows[0], 0); cards[len(cards)-1].title == "CI" {
+		t.Errorf("card without data should be hidden")
+	}
+	var m MetricsSnapshot
+	m.SetExtension("ci", 3)
+	cards := buildCards(m, MetricsHistory{}, graphWindows[0], 0)
+	if last := cards[len(cards)-1]; last.title != "CI" || last.lines[0] != "Builds ▮▮▮" {
+		t.Errorf("registered card = %+v", last)
+	}
+}
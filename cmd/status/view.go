@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -18,9 +19,48 @@ var (
 	lineStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#404040"))
 
 	primaryStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#BD93F9"))
+
+	// scoreColors shade the health score from best to worst; nil is uncolored.
+	scoreColors = []string{"#87FF87", "#87D787", "#FFD75F", "#FFAF5F", "#FF6B6B"}
+)
+
+// Thresholds from the preferences.
+var (
+	percentWarn, percentDanger = 60.0, 85.0
+	tempWarn, tempDanger       = 56.0, 76.0
 )
 
+// byteUnit is 1024 for iec units, 1000 for si.
+var byteUnit uint64 = 1024
+
+// applyTheme swaps the styles for the theme preference. dark is the default.
+func applyTheme(theme string) {
+	switch theme {
+	case "light":
+		titleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#8E44AD")).Bold(true)
+		subtleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#6C6C6C"))
+		warnStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#AF8700"))
+		dangerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#D70000")).Bold(true)
+		okStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#2E7D32"))
+		lineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#BCBCBC"))
+		primaryStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#6C3FC4"))
+		scoreColors = []string{"#2E7D32", "#5F8700", "#AF8700", "#D75F00", "#D70000"}
+	case "mono":
+		titleStyle = lipgloss.NewStyle().Bold(true)
+		subtleStyle = lipgloss.NewStyle().Faint(true)
+		warnStyle = lipgloss.NewStyle()
+		dangerStyle = lipgloss.NewStyle().Bold(true)
+		okStyle = lipgloss.NewStyle()
+		lineStyle = lipgloss.NewStyle().Faint(true)
+		primaryStyle = lipgloss.NewStyle().Bold(true)
+		scoreColors = nil
+	}
+}
+
 const (
+	trendWidth     = 16 // Same width as progressBar
+	minTrendPoints = 2  // Below this a graph is just a bar
+
 	colWidth    = 38
 	iconCPU     = "◉"
 	iconMemory  = "◫"
@@ -30,6 +70,10 @@ const (
 	iconBattery = "◪"
 	iconSensors = "◈"
 	iconProcs   = "❊"
+	iconDocker  = "▣"
+	iconBlue    = "◎"
+
+	diskCardLimit = 3
 )
 
 // Mole body frames (facing right).
@@ -125,9 +169,13 @@ func getMoleFrame(animFrame int, termWidth int) string {
 }
 
 type cardData struct {
-	icon  string
-	title string
-	lines []string
+	id      string // Layout key; registered cards default to the lowercased title
+	icon    string
+	title   string
+	lines   []string
+	more    []string // Extra detail shown when the card is expanded or zoomed
+	column  string   // left or right from the layout, empty to fill the shorter column
+	focused bool
 }
 
 func renderHeader(m MetricsSnapshot, errMsg string, animFrame int, termWidth int, catHidden bool) string {
@@ -135,6 +183,9 @@ func renderHeader(m MetricsSnapshot, errMsg string, animFrame int, termWidth int
 
 	scoreStyle := getScoreStyle(m.HealthScore)
 	scoreText := subtleStyle.Render("Health ") + scoreStyle.Render(fmt.Sprintf("● %d", m.HealthScore))
+	if cost := healthCostText(m.HealthBreakdown); cost != "" {
+		scoreText += " " + subtleStyle.Render("("+cost+")")
+	}
 
 	// Hardware info for a single line.
 	infoParts := []string{}
@@ -169,6 +220,13 @@ func renderHeader(m MetricsSnapshot, errMsg string, animFrame int, termWidth int
 		infoParts = append(infoParts, subtleStyle.Render("up "+m.Uptime))
 	}
 
+	if m.Container != nil {
+		label := envLabels[m.Container.Env]
+		if label == "" {
+			label = m.Container.Env
+		}
+		title += " " + warnStyle.Render("["+label+"]")
+	}
 	headerLine := title + "  " + scoreText + "  " + strings.Join(infoParts, " · ")
 
 	// Show cat unless hidden
@@ -189,23 +247,44 @@ func renderHeader(m MetricsSnapshot, errMsg string, animFrame int, termWidth int
 	return headerLine + "\n" + mole
 }
 
+// healthCostText lists the components that cost at least a point, worst first.
+func healthCostText(components []HealthComponent) string {
+	sorted := slices.Clone(components)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Penalty > sorted[j].Penalty })
+	var parts []string
+	for _, c := range sorted {
+		if c.Penalty < 1 {
+			break
+		}
+		name := strings.ReplaceAll(c.Name, "_", " ")
+		if c.Name == "disk" && c.Detail != "" {
+			name += " " + c.Detail
+		}
+		parts = append(parts, fmt.Sprintf("%s −%.0f", name, c.Penalty))
+	}
+	return strings.Join(parts, ", ")
+}
+
 func getScoreStyle(score int) lipgloss.Style {
+	if scoreColors == nil {
+		return lipgloss.NewStyle().Bold(true)
+	}
+	shade := 4
 	switch {
 	case score >= 90:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("#87FF87")).Bold(true)
+		shade = 0
 	case score >= 75:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("#87D787")).Bold(true)
+		shade = 1
 	case score >= 60:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("#FFD75F")).Bold(true)
+		shade = 2
 	case score >= 40:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("#FFAF5F")).Bold(true)
-	default:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("#FF6B6B")).Bold(true)
+		shade = 3
 	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(scoreColors[shade])).Bold(true)
 }
 
-func renderCPUCard(cpu CPUStatus, thermal ThermalStatus) cardData {
-	var lines []string
+func renderCPUCard(cpu CPUStatus, thermal ThermalStatus, container *ContainerStatus, hist MetricsHistory, window string) cardData {
+	var lines, more []string
 
 	// Line 1: Usage + Temp (Format: 15% @ 30.4°C)
 	usageBar := progressBar(cpu.Usage)
@@ -216,6 +295,17 @@ func renderCPUCard(cpu CPUStatus, thermal ThermalStatus) cardData {
 	}
 
 	lines = append(lines, fmt.Sprintf("Total  %s  %s", usageBar, headerText))
+	if container != nil && container.CgroupVersion > 0 {
+		limit := subtleStyle.Render(fmt.Sprintf("of %d CPUs, no limit", cpu.LogicalCPU))
+		if container.CPULimit > 0 {
+			limit = fmt.Sprintf("of %s CPUs", strconv.FormatFloat(container.CPULimit, 'f', -1, 64))
+		}
+		lines = append(lines, fmt.Sprintf("Cgroup %s  %5.1f%% %s", progressBar(container.CPUUsage), container.CPUUsage, limit))
+	}
+	if series := hist.Series[histCPU]; len(series) >= minTrendPoints {
+		avg, _ := seriesStats(series)
+		lines = append(lines, fmt.Sprintf("Trend  %s  %s", trendGraph(series, true), subtleStyle.Render(fmt.Sprintf("avg %.1f%% · %s", avg, window))))
+	}
 
 	if cpu.PerCoreEstimated {
 		lines = append(lines, subtleStyle.Render("Per-core data unavailable, using averaged load"))
@@ -230,10 +320,17 @@ func renderCPUCard(cpu CPUStatus, thermal ThermalStatus) cardData {
 		}
 		sort.Slice(cores, func(i, j int) bool { return cores[i].val > cores[j].val })
 
-		maxCores := min(len(cores), 3)
-		for i := 0; i < maxCores; i++ {
-			c := cores[i]
-			lines = append(lines, fmt.Sprintf("Core%-2d %s  %5.1f%%", c.idx+1, progressBar(c.val), c.val))
+		for i, c := range cores {
+			bar := progressBar(c.val)
+			if c.idx < len(hist.PerCore) && len(hist.PerCore[c.idx]) >= minTrendPoints {
+				bar = trendGraph(hist.PerCore[c.idx], true)
+			}
+			line := fmt.Sprintf("Core%-2d %s  %5.1f%%", c.idx+1, bar, c.val)
+			if i < 3 {
+				lines = append(lines, line)
+			} else {
+				more = append(more, line)
+			}
 		}
 	}
 
@@ -246,16 +343,29 @@ func renderCPUCard(cpu CPUStatus, thermal ThermalStatus) cardData {
 			cpu.Load1, cpu.Load5, cpu.Load15, cpu.LogicalCPU))
 	}
 
-	return cardData{icon: iconCPU, title: "CPU", lines: lines}
+	return cardData{id: "cpu", icon: iconCPU, title: "CPU", lines: lines, more: more}
 }
 
-func renderMemoryCard(mem MemoryStatus) cardData {
+func renderMemoryCard(mem MemoryStatus, container *ContainerStatus, hist MetricsHistory, window string) cardData {
 	// Check if swap is being used (or at least allocated).
 	hasSwap := mem.SwapTotal > 0 || mem.SwapUsed > 0
 
 	var lines []string
 	// Line 1: Used
 	lines = append(lines, fmt.Sprintf("Used   %s  %5.1f%%", progressBar(mem.UsedPercent), mem.UsedPercent))
+	if container != nil && container.CgroupVersion > 0 {
+		if container.MemoryLimit > 0 {
+			pct := container.MemoryPercent()
+			lines = append(lines, fmt.Sprintf("Cgroup %s  %5.1f%% %s/%s", progressBar(pct), pct,
+				humanBytesCompact(container.MemoryUsed), humanBytesCompact(container.MemoryLimit)))
+		} else {
+			lines = append(lines, fmt.Sprintf("Cgroup %s %s", humanBytes(container.MemoryUsed), subtleStyle.Render("no limit")))
+		}
+	}
+	if series := hist.Series[histMemory]; len(series) >= minTrendPoints {
+		avg, _ := seriesStats(series)
+		lines = append(lines, fmt.Sprintf("Trend  %s  %s", trendGraph(series, true), subtleStyle.Render(fmt.Sprintf("avg %.1f%% · %s", avg, window))))
+	}
 
 	// Line 2: Free
 	freePercent := 100 - mem.UsedPercent
@@ -271,7 +381,11 @@ func renderMemoryCard(mem MemoryStatus) cardData {
 			swapPercent = (float64(mem.SwapUsed) / float64(mem.SwapTotal)) * 100.0
 		}
 		swapText := fmt.Sprintf("%s/%s", humanBytesCompact(mem.SwapUsed), humanBytesCompact(mem.SwapTotal))
-		lines = append(lines, fmt.Sprintf("Swap   %s  %5.1f%% %s", progressBar(swapPercent), swapPercent, swapText))
+		swapBar := progressBar(swapPercent)
+		if series := hist.Series[histSwap]; len(series) >= minTrendPoints {
+			swapBar = trendGraph(series, true)
+		}
+		lines = append(lines, fmt.Sprintf("Swap   %s  %5.1f%% %s", swapBar, swapPercent, swapText))
 
 		lines = append(lines, fmt.Sprintf("Total  %s / %s", humanBytes(mem.Used), humanBytes(mem.Total)))
 		lines = append(lines, fmt.Sprintf("Avail  %s", humanBytes(mem.Total-mem.Used))) // Simplified avail logic for consistency
@@ -305,15 +419,16 @@ func renderMemoryCard(mem MemoryStatus) cardData {
 		}
 		lines = append(lines, pressureStyle.Render(pressureText))
 	}
-	return cardData{icon: iconMemory, title: "Memory", lines: lines}
+	return cardData{id: "memory", icon: iconMemory, title: "Memory", lines: lines}
 }
 
-func renderDiskCard(disks []DiskStatus, io DiskIOStatus) cardData {
-	var lines []string
+func renderDiskCard(disks []DiskStatus, io DiskIOStatus, topIO []ProcessIO, hist MetricsHistory) cardData {
+	var lines, more []string
 	if len(disks) == 0 {
 		lines = append(lines, subtleStyle.Render("Collecting..."))
 	} else {
-		internal, external := splitDisks(disks)
+		// The largest mounts; d opens the disk screen with all of them.
+		internal, external := splitDisks(disks[:min(len(disks), diskCardLimit)])
 		addGroup := func(prefix string, list []DiskStatus) {
 			if len(list) == 0 {
 				return
@@ -328,12 +443,25 @@ func renderDiskCard(disks []DiskStatus, io DiskIOStatus) cardData {
 		if len(lines) == 0 {
 			lines = append(lines, subtleStyle.Render("No disks detected"))
 		}
+		for _, d := range disks[min(len(disks), diskCardLimit):] {
+			more = append(more, formatDiskLine(shorten(d.Mount, 6), d))
+		}
 	}
 	readBar := ioBar(io.ReadRate)
 	writeBar := ioBar(io.WriteRate)
+	if series := hist.Series[histDiskRead]; len(series) >= minTrendPoints {
+		readBar = sparkline(series, io.ReadRate, trendWidth)
+	}
+	if series := hist.Series[histDiskWrite]; len(series) >= minTrendPoints {
+		writeBar = sparkline(series, io.WriteRate, trendWidth)
+	}
 	lines = append(lines, fmt.Sprintf("Read   %s  %.1f MB/s", readBar, io.ReadRate))
 	lines = append(lines, fmt.Sprintf("Write  %s  %.1f MB/s", writeBar, io.WriteRate))
-	return cardData{icon: iconDisk, title: "Disk", lines: lines}
+	for _, p := range topIO {
+		rates := fmt.Sprintf("R %s  W %s", formatProcessRate(p.ReadRate), formatProcessRate(p.WriteRate))
+		lines = append(lines, fmt.Sprintf("%-12s  %s", shorten(p.Name, 12), subtleStyle.Render(rates)))
+	}
+	return cardData{id: "disk", icon: iconDisk, title: "Disk", lines: lines, more: more}
 }
 
 func splitDisks(disks []DiskStatus) (internal, external []DiskStatus) {
@@ -380,30 +508,98 @@ func ioBar(rate float64) string {
 }
 
 func renderProcessCard(procs []ProcessInfo) cardData {
-	var lines []string
+	var lines, more []string
 	maxProcs := 3
 	for i, p := range procs {
-		if i >= maxProcs {
-			break
-		}
 		name := shorten(p.Name, 12)
 		cpuBar := miniBar(p.CPU)
-		lines = append(lines, fmt.Sprintf("%-12s  %s  %5.1f%%", name, cpuBar, p.CPU))
+		line := fmt.Sprintf("%-12s  %s  %5.1f%%", name, cpuBar, p.CPU)
+		if i < maxProcs {
+			lines = append(lines, line)
+		} else {
+			more = append(more, line)
+		}
 	}
 	if len(lines) == 0 {
 		lines = append(lines, subtleStyle.Render("No data"))
 	}
-	return cardData{icon: iconProcs, title: "Processes", lines: lines}
+	return cardData{id: "processes", icon: iconProcs, title: "Processes", lines: lines, more: more}
 }
 
-func buildCards(m MetricsSnapshot, width int) []cardData {
+func renderDockerCard(d DockerStatus) cardData {
+	var cpu, netRx, netTx, blockRead, blockWrite float64
+	var mem uint64
+	for _, c := range d.Containers {
+		cpu += c.CPU
+		mem += c.MemoryUsed
+		netRx += c.NetRxRate
+		netTx += c.NetTxRate
+		blockRead += c.BlockReadRate
+		blockWrite += c.BlockWriteRate
+	}
+	var lines, more []string
+	if len(d.Containers) == 0 {
+		lines = append(lines, subtleStyle.Render("No running containers"))
+	} else {
+		lines = append(lines, fmt.Sprintf("Running %d · CPU %.1f%% · Mem %s", len(d.Containers), cpu, humanBytesCompact(mem)))
+		for i, c := range d.Containers {
+			line := fmt.Sprintf("%-12s  %s  %5.1f%%  %6s", shorten(c.Name, 12), miniBar(c.CPU), c.CPU, humanBytesCompact(c.MemoryUsed))
+			if i < 3 {
+				lines = append(lines, line)
+			} else {
+				more = append(more, line)
+			}
+		}
+		lines = append(lines, fmt.Sprintf("Net    ↓ %.1f  ↑ %.1f MB/s", netRx, netTx))
+		lines = append(lines, fmt.Sprintf("Block  R %.1f  W %.1f MB/s", blockRead, blockWrite))
+	}
+	// Disk usage arrives on its own, slower schedule.
+	for _, u := range []struct {
+		label string
+		usage DockerUsage
+	}{{"Images", d.Images}, {"Volume", d.Volumes}, {"Cache", d.BuildCache}} {
+		if u.usage.Count == 0 {
+			continue
+		}
+		detail := fmt.Sprintf("%d · %s reclaimable", u.usage.Count, humanBytesCompact(u.usage.Reclaimable))
+		lines = append(lines, fmt.Sprintf("%-7s%s  %s", u.label, humanBytesCompact(u.usage.Size), subtleStyle.Render(detail)))
+	}
+	title := "Docker"
+	if d.Engine == "podman" {
+		title = "Podman"
+	}
+	return cardData{id: "docker", icon: iconDocker, title: title, lines: lines, more: more}
+}
+
+func buildCards(m MetricsSnapshot, hist MetricsHistory, window graphWindow, width int) []cardData {
+	netHistory := m.NetworkHistory
+	if window.duration > 0 {
+		netHistory = NetworkHistory{RxHistory: hist.Series[histNetRx], TxHistory: hist.Series[histNetTx]}
+	}
 	cards := []cardData{
-		renderCPUCard(m.CPU, m.Thermal),
-		renderMemoryCard(m.Memory),
-		renderDiskCard(m.Disks, m.DiskIO),
-		renderBatteryCard(m.Batteries, m.Thermal),
+		renderCPUCard(m.CPU, m.Thermal, m.Container, hist, window.Label()),
+		renderMemoryCard(m.Memory, m.Container, hist, window.Label()),
+		renderDiskCard(m.Disks, m.DiskIO, m.TopIO, hist),
+		renderBatteryCard(m.Batteries, m.Thermal, hist),
 		renderProcessCard(m.TopProcesses),
-		renderNetworkCard(m.Network, m.NetworkHistory, m.Proxy, width),
+		renderNetworkCard(m.Network, netHistory, m.Proxy, m.NetworkConfig, m.Probes, m.TopNet, width),
+	}
+	if m.Docker != nil {
+		cards = append(cards, renderDockerCard(*m.Docker))
+	}
+	if len(m.GPU) > 0 {
+		cards = append(cards, renderGPUCard(m.GPU, hist, window.Label()))
+	}
+	if card, ok := renderBluetoothCard(m.Bluetooth); ok {
+		cards = append(cards, card)
+	}
+	for _, render := range extraCards() {
+		if card, ok := render(m, width); ok {
+			if card.id == "" {
+				card.id = strings.ToLower(card.title)
+			}
+			cards = append(cards, card)
+		}
 	}
 	// Sensors card disabled - redundant with CPU temp
 	// if hasSensorData(m.Sensors) {
@@ -412,6 +608,69 @@ func buildCards(m MetricsSnapshot, width int) []cardData {
 	return cards
 }
 
+func renderGPUCard(gpus []GPUStatus, hist MetricsHistory, window string) cardData {
+	var lines []string
+	for i, g := range gpus {
+		name := g.Name
+		if g.CoreCount > 0 {
+			name += fmt.Sprintf(" · %d cores", g.CoreCount)
+		}
+		lines = append(lines, shorten(name, 40))
+		if g.Usage >= 0 {
+			lines = append(lines, fmt.Sprintf("Usage  %s  %5.1f%%", progressBar(g.Usage), g.Usage))
+			// History follows the first GPU only.
+			if series := hist.Series[histGPU]; i == 0 && len(series) >= minTrendPoints {
+				avg, _ := seriesStats(series)
+				lines = append(lines, fmt.Sprintf("Trend  %s  %s", trendGraph(series, true), subtleStyle.Render(fmt.Sprintf("avg %.1f%% · %s", avg, window))))
+			}
+		}
+		if g.MemoryTotal > 0 {
+			percent := g.MemoryUsed / g.MemoryTotal * 100
+			lines = append(lines, fmt.Sprintf("VRAM   %s  %5.1f%%, %s/%s", progressBar(percent), percent,
+				humanBytesShort(uint64(g.MemoryUsed*(1<<20))), humanBytesShort(uint64(g.MemoryTotal*(1<<20)))))
+		}
+		if g.Note != "" {
+			lines = append(lines, subtleStyle.Render(g.Note))
+		}
+	}
+	return cardData{id: "gpu", icon: iconGPU, title: "GPU", lines: lines}
+}
+
+// renderBluetoothCard lists connected devices first; ok is false when the
+// collector found no Bluetooth information.
+func renderBluetoothCard(devices []BluetoothDevice) (cardData, bool) {
+	var connected, known []BluetoothDevice
+	for _, d := range devices {
+		switch {
+		case d.Name == "" || d.Name == "No Bluetooth info":
+		case d.Connected:
+			connected = append(connected, d)
+		default:
+			known = append(known, d)
+		}
+	}
+	if len(connected)+len(known) == 0 {
+		return cardData{}, false
+	}
+	var lines, more []string
+	for i, d := range append(connected, known...) {
+		state := subtleStyle.Render("paired")
+		if d.Connected {
+			state = okStyle.Render("connected")
+		}
+		if d.Battery != "" {
+			state += subtleStyle.Render(" · " + d.Battery)
+		}
+		line := fmt.Sprintf("%-16s %s", shorten(d.Name, 16), state)
+		if i < 4 {
+			lines = append(lines, line)
+		} else {
+			more = append(more, line)
+		}
+	}
+	return cardData{id: "bluetooth", icon: iconBlue, title: "Bluetooth", lines: lines, more: more}, true
+}
+
 func miniBar(percent float64) string {
 	filled := min(int(percent/20), 5)
 	if filled < 0 {
@@ -420,10 +679,10 @@ func miniBar(percent float64) string {
 	return colorizePercent(percent, strings.Repeat("▮", filled)+strings.Repeat("▯", 5-filled))
 }
 
-func renderNetworkCard(netStats []NetworkStatus, history NetworkHistory, proxy ProxyStatus, cardWidth int) cardData {
-	var lines []string
+func renderNetworkCard(netStats []NetworkStatus, history NetworkHistory, proxy ProxyStatus, cfg NetworkConfig, probes []ProbeResult, topNet []ProcessNet, cardWidth int) cardData {
+	var lines, more []string
 	var totalRx, totalTx float64
-	var primaryIP string
+	var primaryIP, firstIP string
 
 	for _, n := range netStats {
 		totalRx += n.RxRateMBs
@@ -431,6 +690,12 @@ func renderNetworkCard(netStats []NetworkStatus, history NetworkHistory, proxy P
 		if primaryIP == "" && n.IP != "" && n.Name == "en0" {
 			primaryIP = n.IP
 		}
+		if firstIP == "" && n.IP != "" {
+			firstIP = n.IP // Busiest interface; Linux has no en0
+		}
+	}
+	if primaryIP == "" {
+		primaryIP = firstIP
 	}
 
 	if len(netStats) == 0 {
@@ -453,6 +718,17 @@ func renderNetworkCard(netStats []NetworkStatus, history NetworkHistory, proxy P
 		txSparkline := sparkline(history.TxHistory, totalTx, graphWidth)
 		lines = append(lines, fmt.Sprintf("Down   %s  %s", rxSparkline, formatRate(totalRx)))
 		lines = append(lines, fmt.Sprintf("Up     %s  %s", txSparkline, formatRate(totalTx)))
+		if len(netStats) > 1 {
+			for i, n := range netStats {
+				line := fmt.Sprintf("%-6s %s", shorten(n.Name, 6),
+					subtleStyle.Render(fmt.Sprintf("↓ %s  ↑ %s", formatRate(n.RxRateMBs), formatRate(n.TxRateMBs))))
+				if i < 3 {
+					lines = append(lines, line)
+				} else {
+					more = append(more, line)
+				}
+			}
+		}
 		// Show proxy and IP on one line.
 		var infoParts []string
 		if proxy.Enabled {
@@ -464,8 +740,27 @@ func renderNetworkCard(netStats []NetworkStatus, history NetworkHistory, proxy P
 		if len(infoParts) > 0 {
 			lines = append(lines, strings.Join(infoParts, " · "))
 		}
+		if summary := networkSummary(cfg); summary != "" {
+			lines = append(lines, subtleStyle.Render(shorten(summary, max(cardWidth, colWidth)-2)))
+		}
+		for _, p := range topNet {
+			rates := fmt.Sprintf("↓ %s  ↑ %s", formatProcessRate(p.RxRate), formatProcessRate(p.TxRate))
+			lines = append(lines, fmt.Sprintf("%-12s  %s", shorten(p.Name, 12), subtleStyle.Render(rates)))
+		}
 	}
-	return cardData{icon: iconNetwork, title: "Network", lines: lines}
+	for _, p := range probes {
+		lines = append(lines, fmt.Sprintf("%-6s %s  %s", shorten(p.Name, 6), probeGraph(p.History), formatProbe(p)))
+	}
+	return cardData{id: "network", icon: iconNetwork, title: "Network", lines: lines, more: more}
+}
+
+// trendGraph draws a trendWidth graph, padding short histories on the left.
+func trendGraph(series []float64, percent bool) string {
+	if len(series) < trendWidth {
+		padded := make([]float64, trendWidth-len(series), trendWidth)
+		series = append(padded, series...)
+	}
+	return historyGraph(series, trendWidth, percent)
 }
 
 // 8 levels: ▁▂▃▄▅▆▇█
@@ -518,7 +813,7 @@ func sparkline(history []float64, current float64, width int) string {
 	return okStyle.Render(result)
 }
 
-func renderBatteryCard(batts []BatteryStatus, thermal ThermalStatus) cardData {
+func renderBatteryCard(batts []BatteryStatus, thermal ThermalStatus, hist MetricsHistory) cardData {
 	var lines []string
 	if len(batts) == 0 {
 		lines = append(lines, subtleStyle.Render("No battery"))
@@ -530,6 +825,9 @@ func renderBatteryCard(batts []BatteryStatus, thermal ThermalStatus) cardData {
 			percentText = dangerStyle.Render(percentText)
 		}
 		lines = append(lines, fmt.Sprintf("Level  %s  %s", batteryProgressBar(b.Percent), percentText))
+		if series := hist.Series[histBattery]; len(series) >= minTrendPoints {
+			lines = append(lines, fmt.Sprintf("Trend  %s", trendGraph(series, true)))
+		}
 
 		// Add capacity line if available.
 		if b.Capacity > 0 {
@@ -541,6 +839,9 @@ func renderBatteryCard(batts []BatteryStatus, thermal ThermalStatus) cardData {
 			}
 			lines = append(lines, fmt.Sprintf("Health %s  %s", batteryProgressBar(float64(b.Capacity)), capacityText))
 		}
+		if b.Wear != nil && b.Wear.PerMonth != 0 {
+			lines = append(lines, fmt.Sprintf("Wear   %s  %s", wearGraph(b.Wear.Samples, 16), formatWear(b.Wear.PerMonth)))
+		}
 
 		statusIcon := ""
 		statusStyle := subtleStyle
@@ -567,8 +868,13 @@ func renderBatteryCard(batts []BatteryStatus, thermal ThermalStatus) cardData {
 		} else if thermal.BatteryPower > 0 {
 			// Only show battery power when discharging (positive value)
 			statusText += fmt.Sprintf(" · %.0fW", thermal.BatteryPower)
+		} else if b.DischargeRate > 0 {
+			statusText += fmt.Sprintf(" · %.0fW", b.DischargeRate)
 		}
 		lines = append(lines, statusStyle.Render(statusText+statusIcon))
+		if s := b.LastSession; s != nil {
+			lines = append(lines, subtleStyle.Render("Last   "+formatChargeSession(*s)))
+		}
 
 		healthParts := []string{}
 		if b.Health != "" {
@@ -592,13 +898,16 @@ func renderBatteryCard(batts []BatteryStatus, thermal ThermalStatus) cardData {
 		}
 	}
 
-	return cardData{icon: iconBattery, title: "Power", lines: lines}
+	return cardData{id: "battery", icon: iconBattery, title: "Power", lines: lines}
 }
 
 func renderCard(data cardData, width int, height int) string {
 	titleText := data.icon + " " + data.title
 	lineLen := max(width-lipgloss.Width(titleText)-2, 4)
 	header := titleStyle.Render(titleText) + "  " + lineStyle.Render(strings.Repeat("╌", lineLen))
+	if data.focused {
+		header = primaryStyle.Render(titleText) + "  " + primaryStyle.Render(strings.Repeat("━", lineLen))
+	}
 	content := header + "\n" + strings.Join(data.lines, "\n")
 
 	lines := strings.Split(content, "\n")
@@ -652,9 +961,9 @@ func batteryProgressBar(percent float64) string {
 
 func colorizePercent(percent float64, s string) string {
 	switch {
-	case percent >= 85:
+	case percent >= percentDanger:
 		return dangerStyle.Render(s)
-	case percent >= 60:
+	case percent >= percentWarn:
 		return warnStyle.Render(s)
 	default:
 		return okStyle.Render(s)
@@ -674,9 +983,9 @@ func colorizeBattery(percent float64, s string) string {
 
 func colorizeTemp(t float64) string {
 	switch {
-	case t >= 76:
+	case t >= tempDanger:
 		return dangerStyle.Render(fmt.Sprintf("%.1f", t))
-	case t >= 56:
+	case t >= tempWarn:
 		return warnStyle.Render(fmt.Sprintf("%.1f", t))
 	default:
 		return okStyle.Render(fmt.Sprintf("%.1f", t))
@@ -696,56 +1005,49 @@ func formatRate(mb float64) string {
 	return fmt.Sprintf("%.0f MB/s", mb)
 }
 
+// byteScale divides v by the largest unit it exceeds (or reaches, unless
+// strict) and returns that unit's letter, "" below a kilobyte.
+func byteScale(v uint64, strict bool) (float64, string) {
+	div := byteUnit * byteUnit * byteUnit * byteUnit
+	for _, letter := range []string{"T", "G", "M", "K"} {
+		if v > div || (!strict && v == div) {
+			return float64(v) / float64(div), letter
+		}
+		div /= byteUnit
+	}
+	return float64(v), ""
+}
+
 func humanBytes(v uint64) string {
-	switch {
-	case v > 1<<40:
-		return fmt.Sprintf("%.1f TB", float64(v)/(1<<40))
-	case v > 1<<30:
-		return fmt.Sprintf("%.1f GB", float64(v)/(1<<30))
-	case v > 1<<20:
-		return fmt.Sprintf("%.1f MB", float64(v)/(1<<20))
-	case v > 1<<10:
-		return fmt.Sprintf("%.1f KB", float64(v)/(1<<10))
-	default:
+	n, letter := byteScale(v, true)
+	if letter == "" {
 		return strconv.FormatUint(v, 10) + " B"
 	}
+	return fmt.Sprintf("%.1f %sB", n, letter)
 }
 
 func humanBytesShort(v uint64) string {
-	switch {
-	case v >= 1<<40:
-		return fmt.Sprintf("%.0fT", float64(v)/(1<<40))
-	case v >= 1<<30:
-		return fmt.Sprintf("%.0fG", float64(v)/(1<<30))
-	case v >= 1<<20:
-		return fmt.Sprintf("%.0fM", float64(v)/(1<<20))
-	case v >= 1<<10:
-		return fmt.Sprintf("%.0fK", float64(v)/(1<<10))
-	default:
+	n, letter := byteScale(v, false)
+	if letter == "" {
 		return strconv.FormatUint(v, 10)
 	}
+	return fmt.Sprintf("%.0f%s", n, letter)
 }
 
 func humanBytesCompact(v uint64) string {
-	switch {
-	case v >= 1<<40:
-		return fmt.Sprintf("%.1fT", float64(v)/(1<<40))
-	case v >= 1<<30:
-		return fmt.Sprintf("%.1fG", float64(v)/(1<<30))
-	case v >= 1<<20:
-		return fmt.Sprintf("%.1fM", float64(v)/(1<<20))
-	case v >= 1<<10:
-		return fmt.Sprintf("%.1fK", float64(v)/(1<<10))
-	default:
+	n, letter := byteScale(v, false)
+	if letter == "" {
 		return strconv.FormatUint(v, 10)
 	}
+	return fmt.Sprintf("%.1f%s", n, letter)
 }
 
 func shorten(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	r := []rune(s)
+	if len(r) <= maxLen {
 		return s
 	}
-	return s[:maxLen-1] + "…"
+	return string(r[:maxLen-1]) + "…"
 }
 
 func renderTwoColumns(cards []cardData, width int) string {
@@ -756,20 +1058,41 @@ func renderTwoColumns(cards []cardData, width int) string {
 	if width > 0 && width/2-2 > cw {
 		cw = width/2 - 2
 	}
+	// Cards without a column go to the one with fewer cards, so by default
+	// they alternate left and right.
+	var leftCards, rightCards []cardData
+	for _, c := range cards {
+		switch {
+		case c.column == "left":
+			leftCards = append(leftCards, c)
+		case c.column == "right":
+			rightCards = append(rightCards, c)
+		case len(rightCards) < len(leftCards):
+			rightCards = append(rightCards, c)
+		default:
+			leftCards = append(leftCards, c)
+		}
+	}
 	var rows []string
-	for i := 0; i < len(cards); i += 2 {
-		left := renderCard(cards[i], cw, 0)
-		right := ""
-		if i+1 < len(cards) {
-			right = renderCard(cards[i+1], cw, 0)
+	for i := range max(len(leftCards), len(rightCards)) {
+		var left, right string
+		if i < len(leftCards) {
+			left = renderCard(leftCards[i], cw, 0)
+		}
+		if i < len(rightCards) {
+			right = renderCard(rightCards[i], cw, 0)
 		}
 		targetHeight := maxInt(lipgloss.Height(left), lipgloss.Height(right))
-		left = renderCard(cards[i], cw, targetHeight)
-		if right != "" {
-			right = renderCard(cards[i+1], cw, targetHeight)
-			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, left, "  ", right))
-		} else {
+		switch {
+		case right == "":
 			rows = append(rows, left)
+		case left == "":
+			// Keep a right-only card in the right column.
+			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, strings.Repeat(" ", cw), "  ", right))
+		default:
+			left = renderCard(leftCards[i], cw, targetHeight)
+			right = renderCard(rightCards[i], cw, targetHeight)
+			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, left, "  ", right))
 		}
 	}
 
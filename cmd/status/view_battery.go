@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// batteryViewSessions is how many sessions the battery screen lists.
+const batteryViewSessions = 12
+
+// wearGraph draws daily capacity scaled to its own range, so a drift of a
+// few points is visible.
+func wearGraph(samples []BatteryCapacitySample, width int) string {
+	if len(samples) == 0 {
+		return strings.Repeat(" ", width)
+	}
+	low := samples[0].Capacity
+	for _, s := range samples {
+		low = min(low, s.Capacity)
+	}
+	series := make([]float64, len(samples))
+	for i, s := range samples {
+		series[i] = s.Capacity - low + 1
+	}
+	if len(series) < width {
+		series = append(make([]float64, width-len(series), width), series...)
+	}
+	return historyGraph(series, width, false)
+}
+
+// formatWear shows the capacity trend per month, colored when it is steep.
+func formatWear(perMonth float64) string {
+	text := fmt.Sprintf("%+.1f%%/mo", perMonth)
+	switch {
+	case perMonth <= -2:
+		return dangerStyle.Render(text)
+	case perMonth <= -1:
+		return warnStyle.Render(text)
+	}
+	return text
+}
+
+func formatChargeSession(s ChargeSession) string {
+	verb := "Charged"
+	if s.Kind == "discharge" {
+		verb = "Drained"
+	}
+	took := formatUptime(uint64(max(s.End.Sub(s.Start), 0).Seconds()))
+	return fmt.Sprintf("%s %.0f→%.0f%% in %s", verb, s.From, s.To, took)
+}
+
+// renderBatteryView draws each battery's energy, wear trend and sessions.
+func renderBatteryView(batts []BatteryStatus, width int) string {
+	lines := []string{titleStyle.Render("Battery")}
+	if len(batts) == 0 {
+		lines = append(lines, "", subtleStyle.Render("No battery"), "", subtleStyle.Render("b back"))
+		return strings.Join(lines, "\n")
+	}
+
+	for i, b := range batts {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		name := b.Name
+		if name == "" {
+			name = fmt.Sprintf("Battery %d", i+1)
+		}
+		status := fmt.Sprintf("%.0f%% · %s", b.Percent, b.Status)
+		if b.TimeLeft != "" {
+			status += " · " + b.TimeLeft + " left"
+		}
+		lines = append(lines, titleStyle.Render(name)+"  "+subtleStyle.Render(status))
+
+		var energy []string
+		if b.EnergyNow > 0 {
+			energy = append(energy, fmt.Sprintf("Now %.1f Wh", b.EnergyNow))
+		}
+		if b.EnergyFull > 0 {
+			energy = append(energy, fmt.Sprintf("Full %.1f Wh", b.EnergyFull))
+		}
+		if b.EnergyDesign > 0 {
+			energy = append(energy, fmt.Sprintf("Design %.1f Wh", b.EnergyDesign))
+		}
+		if b.Power > 0 {
+			energy = append(energy, fmt.Sprintf("Power %.1f W", b.Power))
+		}
+		if b.DischargeRate > 0 {
+			energy = append(energy, fmt.Sprintf("Avg drain %.1f W", b.DischargeRate))
+		}
+		if len(energy) > 0 {
+			lines = append(lines, " "+strings.Join(energy, " · "))
+		}
+
+		var health []string
+		if b.Capacity > 0 {
+			health = append(health, fmt.Sprintf("Capacity %d%%", b.Capacity))
+		}
+		if b.CycleCount > 0 {
+			health = append(health, fmt.Sprintf("%d cycles", b.CycleCount))
+		}
+		if b.Health != "" {
+			health = append(health, b.Health)
+		}
+		if len(health) > 0 {
+			lines = append(lines, " "+strings.Join(health, " · "))
+		}
+
+		if b.Wear != nil && len(b.Wear.Samples) > 0 {
+			first, last := b.Wear.Samples[0], b.Wear.Samples[len(b.Wear.Samples)-1]
+			graphWidth := min(max(width-40, 16), 60)
+			trend := subtleStyle.Render("trend after two weeks")
+			if b.Wear.PerMonth != 0 {
+				trend = formatWear(b.Wear.PerMonth)
+			}
+			lines = append(lines, fmt.Sprintf(" Wear %s  %.1f%% → %.1f%%  %s", wearGraph(b.Wear.Samples, graphWidth), first.Capacity, last.Capacity, trend))
+			lines = append(lines, subtleStyle.Render(fmt.Sprintf("      %s to %s, %d days logged", first.Date, last.Date, len(b.Wear.Samples))))
+		}
+
+		lines = append(lines, "", subtleStyle.Render(fmt.Sprintf(" %-10s %-16s %9s %13s %9s", "SESSION", "STARTED", "DURATION", "LEVEL", "RATE")))
+		if len(b.Sessions) == 0 {
+			lines = append(lines, subtleStyle.Render(" No sessions yet"))
+		}
+		sessions := slices.Clone(b.Sessions)
+		slices.Reverse(sessions)
+		for _, s := range sessions[:min(len(sessions), batteryViewSessions)] {
+			lines = append(lines, formatSessionRow(s))
+		}
+	}
+
+	lines = append(lines, "", subtleStyle.Render("* ongoing · b back"))
+	return strings.Join(lines, "\n")
+}
+
+// formatSessionRow is one line of the session table; the rate is percent
+// per hour.
+func formatSessionRow(s ChargeSession) string {
+	d := max(s.End.Sub(s.Start), 0)
+	rate := "-"
+	if d >= time.Minute {
+		rate = fmt.Sprintf("%+.0f%%/h", (s.To-s.From)/d.Hours())
+	}
+	kind := s.Kind
+	if s.Ongoing {
+		kind += "*"
+	}
+	level := fmt.Sprintf("%.0f%% → %.0f%%", s.From, s.To)
+	return fmt.Sprintf(" %-10s %-16s %9s %13s %9s", kind, s.Start.Local().Format("Jan 02 15:04"), formatUptime(uint64(d.Seconds())), level, rate)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// diskView is the state of the disk screen.
+type diskView struct {
+	cursor int
+	status string
+}
+
+type analyzeDoneMsg struct {
+	mount string
+	err   error
+}
+
+// analyzeCommand builds the command that opens analyze on a mount.
+var analyzeCommand = defaultAnalyzeCommand
+
+// defaultAnalyzeCommand runs the analyze binary installed next to status,
+// else mole on PATH.
+func defaultAnalyzeCommand(mount string) (*exec.Cmd, error) {
+	suffix := ""
+	if runtime.GOOS == "windows" {
+		suffix = ".exe"
+	}
+	if exe, err := os.Executable(); err == nil {
+		dir := filepath.Dir(exe)
+		for _, name := range []string{"analyze", "analyze-go"} {
+			if path := filepath.Join(dir, name+suffix); fileExists(path) {
+				return exec.Command(path, mount), nil
+			}
+		}
+	}
+	for _, name := range []string{"mole", "mo"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return exec.Command(path, "analyze", mount), nil
+		}
+	}
+	return nil, errors.New("analyze not found next to status or on PATH")
+}
+
+// openAnalyze suspends the dashboard while analyze runs in the terminal.
+func openAnalyze(mount string) tea.Cmd {
+	cmd, err := analyzeCommand(mount)
+	if err != nil {
+		return func() tea.Msg { return analyzeDoneMsg{mount: mount, err: err} }
+	}
+	return tea.ExecProcess(cmd, func(err error) tea.Msg { return analyzeDoneMsg{mount: mount, err: err} })
+}
+
+// handleKey applies a key on the disk screen. It returns false when the
+// screen should close.
+func (v *diskView) handleKey(msg tea.KeyMsg, disks []DiskStatus) (bool, tea.Cmd) {
+	v.status = ""
+	switch msg.String() {
+	case "esc", "d":
+		return false, nil
+	case "up", "k":
+		v.cursor--
+	case "down", "j":
+		v.cursor++
+	case "home", "g":
+		v.cursor = 0
+	case "end", "G":
+		v.cursor = len(disks) - 1
+	case "enter", "a":
+		if len(disks) == 0 {
+			return true, nil
+		}
+		v.cursor = min(max(v.cursor, 0), len(disks)-1)
+		mount := disks[v.cursor].Mount
+		v.status = "Opening analyze on " + mount
+		return true, openAnalyze(mount)
+	}
+	v.cursor = min(max(v.cursor, 0), max(len(disks)-1, 0))
+	return true, nil
+}
+
+// formatGrowth shows a used-bytes trend per day, "-" before there is one.
+func formatGrowth(perDay float64) string {
+	switch {
+	case perDay >= 1<<20:
+		return "+" + humanBytesCompact(uint64(perDay)) + "/d"
+	case perDay <= -(1 << 20):
+		return "-" + humanBytesCompact(uint64(-perDay)) + "/d"
+	}
+	return "-"
+}
+
+// formatFullIn renders a days-until-full projection, colored by urgency.
+func formatFullIn(days float64) string {
+	var text string
+	switch {
+	case days <= 0:
+		return subtleStyle.Render("-")
+	case days < 1:
+		text = fmt.Sprintf("~%dh", max(int(math.Round(days*24)), 1))
+	default:
+		text = fmt.Sprintf("~%dd", int(math.Round(days)))
+	}
+	switch {
+	case days < 7:
+		return dangerStyle.Render(text)
+	case days < 30:
+		return warnStyle.Render(text)
+	}
+	return text
+}
+
+// renderDiskView draws the full disk screen.
+func renderDiskView(disks []DiskStatus, v diskView, width int) string {
+	title := titleStyle.Render("Disks") + "  " + subtleStyle.Render(fmt.Sprintf("%d mounts", len(disks)))
+	if len(disks) == 0 {
+		return title + "\n\n" + subtleStyle.Render("No disks detected")
+	}
+
+	const fixed = 82 // Every column but the mount
+	mountWidth := min(max(width-fixed, 12), 40)
+	header := fmt.Sprintf(" %-*s %-10s %-5s %6s %6s %6s %6s %8s %8s %8s %7s",
+		mountWidth, "MOUNT", "DEVICE", "FS", "USED", "SIZE", "USE%", "INODE%", "READ", "WRITE", "GROWTH", "FULL IN")
+	lines := []string{title, subtleStyle.Render(header)}
+
+	cursor := min(max(v.cursor, 0), len(disks)-1)
+	for i, d := range disks {
+		inodes := fmt.Sprintf("%6s", "-")
+		if d.InodesTotal > 0 {
+			inodes = colorizePercent(d.InodesPercent, fmt.Sprintf("%5.1f%%", d.InodesPercent))
+		}
+		marker := " "
+		if i == cursor {
+			marker = primaryStyle.Render("›")
+		}
+		line := marker + fmt.Sprintf("%-*s %-10s %-5s %6s %6s %s %s %8s %8s %8s %s",
+			mountWidth, shorten(d.Mount, mountWidth), shorten(strings.TrimPrefix(d.Device, "/dev/"), 10), shorten(d.Fstype, 5),
+			humanBytesCompact(d.Used), humanBytesCompact(d.Total),
+			colorizePercent(d.UsedPercent, fmt.Sprintf("%5.1f%%", d.UsedPercent)), inodes,
+			formatProcessRate(d.ReadRate), formatProcessRate(d.WriteRate), formatGrowth(d.GrowthRate), formatFullIn(d.FullInDays))
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "")
+	if v.status != "" {
+		lines = append(lines, warnStyle.Render(v.status))
+	} else {
+		lines = append(lines, subtleStyle.Render("↑↓ select · enter analyze mount · d back"))
+	}
+	return strings.Join(lines, "\n")
+}
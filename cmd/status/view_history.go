@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const historyViewWindow = 24 * time.Hour
+
+// historyRow describes one graph line of the history screen.
+type historyRow struct {
+	label    string
+	metric   historyMetric
+	percent  bool // Percent series scale to 100; rates scale to their max
+	optional bool // Hidden when the series never left zero (no swap, GPU or battery)
+}
+
+var historyRows = []historyRow{
+	{"CPU", histCPU, true, false},
+	{"Memory", histMemory, true, false},
+	{"Swap", histSwap, true, true},
+	{"Read", histDiskRead, false, false},
+	{"Write", histDiskWrite, false, false},
+	{"Down", histNetRx, false, false},
+	{"Up", histNetTx, false, false},
+	{"GPU", histGPU, true, true},
+	{"Battery", histBattery, true, true},
+}
+
+// renderHistoryView draws the "last 24h" screen from recorded history.
+func renderHistoryView(samples []historySample, recording bool, width int) string {
+	title := titleStyle.Render("History") + "  " + subtleStyle.Render("last 24h · h to return")
+	if len(samples) == 0 {
+		msg := "No history recorded yet."
+		if !recording {
+			msg = "History is off. Start with --history or MO_STATUS_HISTORY=1 to record."
+		}
+		return title + "\n\n" + subtleStyle.Render(msg)
+	}
+
+	graphWidth := min(max(width-34, 10), 96)
+	first, last := samples[0].Time, samples[len(samples)-1].Time
+	span := subtleStyle.Render(fmt.Sprintf("%s → %s", first.Format("Jan 2 15:04"), last.Format("Jan 2 15:04")))
+
+	lines := []string{title, span, ""}
+	for _, row := range historyRows {
+		series := historySeries(samples, row.metric)
+		avg, peak := seriesStats(series)
+		if row.optional && peak == 0 {
+			continue
+		}
+		graph := historyGraph(series, graphWidth, row.percent)
+		var stats string
+		if row.percent {
+			stats = fmt.Sprintf("avg %5.1f%%  max %5.1f%%", avg, peak)
+		} else {
+			stats = fmt.Sprintf("avg %s  max %s", formatRate(avg), formatRate(peak))
+		}
+		lines = append(lines, fmt.Sprintf("%-8s %s  %s", row.label, graph, subtleStyle.Render(stats)))
+	}
+
+	hogs := topProcessByHour(samples, 12)
+	if len(hogs) > 0 {
+		lines = append(lines, "", titleStyle.Render("Top CPU by hour"))
+		for _, h := range hogs {
+			lines = append(lines, fmt.Sprintf("%s  %-20s %s", subtleStyle.Render(h.Time.Format("Jan 2 15:00")), shorten(h.TopProc, 20), colorizePercent(h.TopProcCPU, fmt.Sprintf("%5.1f%%", h.TopProcCPU))))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// historyGraph downsamples series to width columns by averaging and draws it as blocks.
+func historyGraph(series []float64, width int, percent bool) string {
+	blocks := []rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+	cols := downsample(series, width)
+
+	scale := 100.0
+	if !percent {
+		scale = 0.1
+		for _, v := range cols {
+			scale = max(scale, v)
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range cols {
+		level := int(v / scale * float64(len(blocks)-1))
+		level = min(max(level, 0), len(blocks)-1)
+		b.WriteRune(blocks[level])
+	}
+	graph := b.String()
+	if percent {
+		_, peak := seriesStats(cols)
+		return colorizePercent(peak, graph)
+	}
+	return okStyle.Render(graph)
+}
+
+// downsample averages series into at most width buckets.
+func downsample(series []float64, width int) []float64 {
+	if width <= 0 || len(series) == 0 {
+		return nil
+	}
+	if len(series) <= width {
+		return series
+	}
+	out := make([]float64, width)
+	for i := range width {
+		start := i * len(series) / width
+		end := (i + 1) * len(series) / width
+		var sum float64
+		for _, v := range series[start:end] {
+			sum += v
+		}
+		out[i] = sum / float64(end-start)
+	}
+	return out
+}
+
+func seriesStats(series []float64) (avg, peak float64) {
+	if len(series) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range series {
+		sum += v
+		peak = max(peak, v)
+	}
+	return sum / float64(len(series)), peak
+}
+
+// topProcessByHour returns, newest first, the busiest process of each hour.
+func topProcessByHour(samples []historySample, limit int) []historySample {
+	var hours []historySample
+	for _, s := range samples {
+		if s.TopProc == "" {
+			continue
+		}
+		hour := s.Time.Truncate(time.Hour)
+		if n := len(hours); n > 0 && hours[n-1].Time.Equal(hour) {
+			if s.TopProcCPU > hours[n-1].TopProcCPU {
+				hours[n-1].TopProc, hours[n-1].TopProcCPU = s.TopProc, s.TopProcCPU
+			}
+			continue
+		}
+		hours = append(hours, historySample{Time: hour, TopProc: s.TopProc, TopProcCPU: s.TopProcCPU})
+	}
+	out := make([]historySample, 0, min(limit, len(hours)))
+	for i := len(hours) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, hours[i])
+	}
+	return out
+}
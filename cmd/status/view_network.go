@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// networkView is the state of the network screen.
+type networkView struct {
+	offset int // First body line shown
+}
+
+// handleKey applies a key on the network screen. It returns false when the
+// screen should close.
+func (v *networkView) handleKey(msg tea.KeyMsg, pageSize int) bool {
+	switch msg.String() {
+	case "esc", "n":
+		return false
+	case "up", "k":
+		v.offset--
+	case "down", "j":
+		v.offset++
+	case "pgup":
+		v.offset -= pageSize
+	case "pgdown":
+		v.offset += pageSize
+	case "home", "g":
+		v.offset = 0
+	}
+	v.offset = max(v.offset, 0)
+	return true
+}
+
+func formatLinkSpeed(mbps int) string {
+	switch {
+	case mbps <= 0:
+		return "-"
+	case mbps >= 1000 && mbps%1000 == 0:
+		return fmt.Sprintf("%d Gb/s", mbps/1000)
+	case mbps >= 1000:
+		return fmt.Sprintf("%.1f Gb/s", float64(mbps)/1000)
+	}
+	return fmt.Sprintf("%d Mb/s", mbps)
+}
+
+// networkSummary is the gateway and DNS line shared by the card and the screen.
+func networkSummary(cfg NetworkConfig) string {
+	var parts []string
+	if cfg.Gateway != "" {
+		gw := "GW " + cfg.Gateway
+		if cfg.GatewayInterface != "" {
+			gw += " (" + cfg.GatewayInterface + ")"
+		}
+		parts = append(parts, gw)
+	}
+	if len(cfg.DNS) > 0 {
+		parts = append(parts, "DNS "+strings.Join(cfg.DNS, ", "))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// renderNetworkView draws interfaces, listeners and connections per process.
+func renderNetworkView(m MetricsSnapshot, v networkView, width, height int) string {
+	title := titleStyle.Render("Network")
+	if summary := networkSummary(m.NetworkConfig); summary != "" {
+		title += "  " + subtleStyle.Render(summary)
+	}
+
+	body := []string{subtleStyle.Render(fmt.Sprintf(" %-10s %-15s %-24s %9s %-8s %10s %10s %7s %7s",
+		"IFACE", "IPV4", "IPV6", "SPEED", "TREND", "DOWN", "UP", "ERRORS", "DROPS"))}
+	for _, n := range m.Network {
+		trend := "-"
+		if h, ok := m.NetworkHistory.Interfaces[n.Name]; ok {
+			combined := make([]float64, len(h.RxHistory))
+			for i := range combined {
+				combined[i] = h.RxHistory[i]
+				if i < len(h.TxHistory) {
+					combined[i] += h.TxHistory[i]
+				}
+			}
+			trend = sparkline(combined, n.RxRateMBs+n.TxRateMBs, 8)
+		}
+		errs := fmt.Sprintf("%7d", n.ErrorsIn+n.ErrorsOut)
+		if n.ErrorsIn+n.ErrorsOut > 0 {
+			errs = warnStyle.Render(errs)
+		}
+		body = append(body, fmt.Sprintf(" %-10s %-15s %-24s %9s %s %10s %10s %s %7d",
+			shorten(n.Name, 10), orDash(n.IP), shorten(orDash(n.IPv6), 24), formatLinkSpeed(n.SpeedMbps), trend,
+			formatRate(n.RxRateMBs), formatRate(n.TxRateMBs), errs, n.DropsIn+n.DropsOut))
+	}
+	if m.NetworkConfig.Gateway6 != "" {
+		body = append(body, subtleStyle.Render(" IPv6 gateway "+m.NetworkConfig.Gateway6))
+	}
+
+	if len(m.Probes) > 0 {
+		body = append(body, "", titleStyle.Render("Probes"))
+		body = append(body, subtleStyle.Render(fmt.Sprintf(" %-12s %-4s %-36s %-8s  %s", "NAME", "KIND", "TARGET", "TREND", "LATENCY")))
+		for _, p := range m.Probes {
+			body = append(body, fmt.Sprintf(" %-12s %-4s %-36s %s  %s",
+				shorten(p.Name, 12), p.Kind, shorten(p.Target, 36), probeGraph(p.History), formatProbe(p)))
+		}
+	}
+
+	body = append(body, "")
+	if m.Connections == nil {
+		body = append(body, subtleStyle.Render("Loading connections..."))
+	} else {
+		listening := listeningSockets(m.Connections)
+		body = append(body, titleStyle.Render(fmt.Sprintf("Listening (%d)", len(listening))))
+		body = append(body, subtleStyle.Render(fmt.Sprintf(" %-5s %-30s %7s  %s", "PROTO", "ADDRESS", "PID", "PROCESS")))
+		for _, c := range listening {
+			body = append(body, fmt.Sprintf(" %-5s %-30s %7s  %s",
+				c.Proto, shorten(formatHostPort(c.LocalAddr, c.LocalPort), 30), formatPID(c.PID), orDash(c.Process)))
+		}
+
+		groups := establishedByProcess(m.Connections)
+		body = append(body, "", titleStyle.Render(fmt.Sprintf("Established by process (%d)", len(groups))))
+		remoteWidth := max(width-36, 20)
+		for _, g := range groups {
+			name := fmt.Sprintf("%s (%s)", orDash(g.Process), formatPID(g.PID))
+			remotes := strings.Join(g.Remotes, ", ")
+			body = append(body, fmt.Sprintf(" %-28s %4d  %s", shorten(name, 28), g.Count, subtleStyle.Render(shorten(remotes, remoteWidth))))
+		}
+	}
+
+	pageSize := networkPageSize(height)
+	offset := min(v.offset, max(len(body)-pageSize, 0))
+	end := min(offset+pageSize, len(body))
+	lines := append([]string{title}, body[offset:end]...)
+	lines = append(lines, "", subtleStyle.Render("↑↓ scroll · n back"))
+	return strings.Join(lines, "\n")
+}
+
+// probeGraph draws a probe's recent latencies; the thresholds of sparkline
+// are for MB/s, so it uses the neutral history graph.
+func probeGraph(history []float64) string {
+	const width = 8
+	if len(history) < width {
+		history = append(make([]float64, width-len(history), width), history...)
+	}
+	return historyGraph(history, width, false)
+}
+
+// formatProbe shows the last latency with its jitter and loss, or why it failed.
+func formatProbe(p ProbeResult) string {
+	if !p.OK {
+		return dangerStyle.Render(orDash(p.Error))
+	}
+	latency := formatLatency(p.LatencyMs)
+	switch {
+	case p.LatencyMs >= 500:
+		latency = dangerStyle.Render(latency)
+	case p.LatencyMs >= 150:
+		latency = warnStyle.Render(latency)
+	}
+	text := latency + subtleStyle.Render(" ±"+formatLatency(p.JitterMs))
+	if p.Loss > 0 {
+		text += " " + warnStyle.Render(fmt.Sprintf("%.0f%% loss", p.Loss))
+	}
+	return text
+}
+
+func formatLatency(ms float64) string {
+	if ms < 10 {
+		return fmt.Sprintf("%.1fms", ms)
+	}
+	return fmt.Sprintf("%.0fms", ms)
+}
+
+func formatPID(pid int32) string {
+	if pid <= 0 {
+		return "-"
+	}
+	return fmt.Sprint(pid)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// networkPageSize is how many body lines fit between the title and footer.
+func networkPageSize(height int) int {
+	if height <= 0 {
+		return 40
+	}
+	return max(height-3, 5)
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// processView is the state of the process screen.
+type processView struct {
+	sortBy    processSort
+	tree      bool
+	filter    string
+	filtering bool // Typing into the filter
+	cursor    int
+	selected  int32 // PID under the cursor, kept across refreshes
+	confirm   *processSignal
+	status    string
+}
+
+// processSignal is a pending or sent SIGTERM/SIGKILL.
+type processSignal struct {
+	pid  int32
+	name string
+	kill bool
+}
+
+func (s processSignal) verb() string {
+	if s.kill {
+		return "SIGKILL"
+	}
+	return "SIGTERM"
+}
+
+type processSignalMsg struct {
+	signal processSignal
+	err    error
+}
+
+// signalProcess sends SIGTERM, or SIGKILL when kill is set.
+var signalProcess = func(pid int32, kill bool) error {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return err
+	}
+	if kill {
+		return p.Kill()
+	}
+	return p.Terminate()
+}
+
+func sendProcessSignal(sig processSignal) tea.Cmd {
+	return func() tea.Msg {
+		if sig.pid <= 1 || int(sig.pid) == os.Getpid() {
+			return processSignalMsg{signal: sig, err: errors.New("refusing to signal this process")}
+		}
+		return processSignalMsg{signal: sig, err: signalProcess(sig.pid, sig.kill)}
+	}
+}
+
+// processRow is a process placed on screen.
+type processRow struct {
+	ProcessDetail
+	prefix string // Tree branches; empty in the flat view
+}
+
+// matchesFilter checks name, user and command line, ignoring case.
+func matchesFilter(p ProcessDetail, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	filter = strings.ToLower(filter)
+	return strings.Contains(strings.ToLower(p.Name), filter) ||
+		strings.Contains(strings.ToLower(p.User), filter) ||
+		strings.Contains(strings.ToLower(p.Cmdline), filter) ||
+		strconv.Itoa(int(p.PID)) == filter
+}
+
+// rows returns the visible processes in display order.
+func (v processView) rows(procs []ProcessDetail) []processRow {
+	var matched []ProcessDetail
+	for _, p := range procs {
+		if matchesFilter(p, v.filter) {
+			matched = append(matched, p)
+		}
+	}
+	if v.tree {
+		return processTree(procs, matched, v.sortBy)
+	}
+	sortProcesses(matched, v.sortBy)
+	rows := make([]processRow, len(matched))
+	for i, p := range matched {
+		rows[i] = processRow{ProcessDetail: p}
+	}
+	return rows
+}
+
+// processTree nests matched processes under their parents. Ancestors of a
+// match are kept so every match stays reachable from a root.
+func processTree(all, matched []ProcessDetail, by processSort) []processRow {
+	byPID := make(map[int32]ProcessDetail, len(all))
+	for _, p := range all {
+		byPID[p.PID] = p
+	}
+	keep := make(map[int32]bool, len(matched))
+	for _, p := range matched {
+		for pid := p.PID; !keep[pid]; {
+			keep[pid] = true
+			parent, ok := byPID[byPID[pid].PPID]
+			if !ok || parent.PID == pid {
+				break
+			}
+			pid = parent.PID
+		}
+	}
+
+	children := make(map[int32][]ProcessDetail)
+	var roots []ProcessDetail
+	for pid := range keep {
+		p := byPID[pid]
+		if _, ok := byPID[p.PPID]; ok && p.PPID != p.PID && keep[p.PPID] {
+			children[p.PPID] = append(children[p.PPID], p)
+		} else {
+			roots = append(roots, p)
+		}
+	}
+
+	var rows []processRow
+	visited := make(map[int32]bool, len(keep))
+	var walk func(list []ProcessDetail, indent string, top bool)
+	walk = func(list []ProcessDetail, indent string, top bool) {
+		sortProcesses(list, by)
+		for i, p := range list {
+			if visited[p.PID] {
+				continue
+			}
+			visited[p.PID] = true
+			branch, next := "├─ ", "│  "
+			if i == len(list)-1 {
+				branch, next = "└─ ", "   "
+			}
+			if top {
+				branch, next = "", ""
+			}
+			rows = append(rows, processRow{ProcessDetail: p, prefix: indent + branch})
+			walk(children[p.PID], indent+next, false)
+		}
+	}
+	walk(roots, "", true)
+	return rows
+}
+
+// syncCursor moves the cursor to the selected PID after the rows changed.
+func (v *processView) syncCursor(rows []processRow) {
+	for i, r := range rows {
+		if r.PID == v.selected {
+			v.cursor = i
+			return
+		}
+	}
+	v.cursor = min(max(v.cursor, 0), max(len(rows)-1, 0))
+	if len(rows) > 0 {
+		v.selected = rows[v.cursor].PID
+	}
+}
+
+func (v *processView) move(rows []processRow, delta int) {
+	if len(rows) == 0 {
+		return
+	}
+	v.cursor = min(max(v.cursor+delta, 0), len(rows)-1)
+	v.selected = rows[v.cursor].PID
+}
+
+// handleKey applies a key on the process screen. It returns false when the
+// screen should close.
+func (v *processView) handleKey(msg tea.KeyMsg, procs []ProcessDetail, pageSize int) (bool, tea.Cmd) {
+	key := msg.String()
+	if v.confirm != nil {
+		sig := *v.confirm
+		v.confirm = nil
+		if key == "y" || key == "Y" {
+			return true, sendProcessSignal(sig)
+		}
+		v.status = "Cancelled"
+		return true, nil
+	}
+	if v.filtering {
+		switch msg.Type {
+		case tea.KeyEnter:
+			v.filtering = false
+		case tea.KeyEsc:
+			v.filter, v.filtering = "", false
+		case tea.KeyBackspace:
+			if r := []rune(v.filter); len(r) > 0 {
+				v.filter = string(r[:len(r)-1])
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			v.filter += string(msg.Runes)
+		}
+		return true, nil
+	}
+
+	rows := v.rows(procs)
+	v.syncCursor(rows)
+	switch key {
+	case "esc", "p":
+		return false, nil
+	case "up", "k":
+		v.move(rows, -1)
+	case "down", "j":
+		v.move(rows, 1)
+	case "pgup":
+		v.move(rows, -pageSize)
+	case "pgdown":
+		v.move(rows, pageSize)
+	case "home", "g":
+		v.move(rows, -len(rows))
+	case "end", "G":
+		v.move(rows, len(rows))
+	case "s":
+		v.sortBy = (v.sortBy + 1) % processSortCount
+	case "t":
+		v.tree = !v.tree
+	case "/":
+		v.filtering = true
+	case "x", "X":
+		if len(rows) > 0 {
+			r := rows[v.cursor]
+			v.confirm = &processSignal{pid: r.PID, name: r.Name, kill: key == "X"}
+		}
+	}
+	v.status = ""
+	return true, nil
+}
+
+func formatProcessRate(mb float64) string {
+	if mb < 0.01 {
+		return "-"
+	}
+	return humanBytesCompact(uint64(mb*1024*1024)) + "/s"
+}
+
+// renderProcessView draws the full process screen.
+func renderProcessView(procs []ProcessDetail, v processView, width, height int) string {
+	rows := v.rows(procs)
+	v.syncCursor(rows)
+
+	mode := "flat"
+	if v.tree {
+		mode = "tree"
+	}
+	title := titleStyle.Render("Processes") + "  " +
+		subtleStyle.Render(fmt.Sprintf("%d shown · sort %s · %s", len(rows), v.sortBy, mode))
+	if v.filter != "" || v.filtering {
+		cursor := ""
+		if v.filtering {
+			cursor = "█"
+		}
+		title += "  " + primaryStyle.Render("/"+v.filter+cursor)
+	}
+	lines := []string{title}
+	if procs == nil {
+		return title + "\n\n" + subtleStyle.Render("Loading processes...")
+	}
+
+	header := fmt.Sprintf(" %7s %-10s %6s %7s %8s %8s %5s %4s  %s", "PID", "USER", "CPU%", "RSS", "READ", "WRITE", "FDS", "THR", "COMMAND")
+	lines = append(lines, subtleStyle.Render(header))
+
+	pageSize := processPageSize(height)
+	offset := max(v.cursor-pageSize+1, 0)
+	end := min(offset+pageSize, len(rows))
+
+	cmdWidth := max(width-len(header)+len("COMMAND"), 20)
+	for i := offset; i < end; i++ {
+		r := rows[i]
+		fds := "-"
+		if r.OpenFiles >= 0 {
+			fds = strconv.Itoa(int(r.OpenFiles))
+		}
+		command := r.prefix + r.Name
+		if r.Cmdline != "" && r.Cmdline != r.Name {
+			command += " " + subtleStyle.Render(shorten(r.Cmdline, max(cmdWidth-len([]rune(command))-1, 8)))
+		}
+		marker := " "
+		if i == v.cursor {
+			marker = primaryStyle.Render("›")
+		}
+		line := marker + fmt.Sprintf("%7d %-10s %s %7s %8s %8s %5s %4d  %s",
+			r.PID, shorten(r.User, 10), colorizePercent(r.CPU, fmt.Sprintf("%6.1f", r.CPU)), humanBytesCompact(r.RSS),
+			formatProcessRate(r.ReadRate), formatProcessRate(r.WriteRate), fds, r.Threads, command)
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "")
+	switch {
+	case v.confirm != nil:
+		lines = append(lines, dangerStyle.Render(fmt.Sprintf("Send %s to %d (%s)? y to confirm, any other key cancels", v.confirm.verb(), v.confirm.pid, v.confirm.name)))
+	case v.status != "":
+		lines = append(lines, warnStyle.Render(v.status))
+	default:
+		lines = append(lines, subtleStyle.Render("↑↓ select · s sort · t tree · / filter · x SIGTERM · X SIGKILL · p back"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// processPageSize is how many rows fit under the title, header and footer.
+func processPageSize(height int) int {
+	if height <= 0 {
+		return 20
+	}
+	return max(height-4, 5)
+}
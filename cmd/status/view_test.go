@@ -1,8 +1,13 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func TestFormatRate(t *testing.T) {
@@ -285,6 +290,57 @@ func TestHumanBytesCompact(t *testing.T) {
 	}
 }
 
+func TestPrefsChangeUnitsAndThresholds(t *testing.T) {
+	saved := []float64{percentWarn, percentDanger, tempWarn, tempDanger}
+	savedInterval := refreshInterval
+	t.Cleanup(func() {
+		percentWarn, percentDanger, tempWarn, tempDanger = saved[0], saved[1], saved[2], saved[3]
+		byteUnit, refreshInterval = 1024, savedInterval
+	})
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	body := `{"units": "si", "refresh_interval": "2s", "thresholds": {"warn_percent": 70, "danger_percent": 90}}`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadPrefs(path); err != nil {
+		t.Fatalf("loadPrefs: %v", err)
+	}
+
+	if got := humanBytes(1500 * 1000 * 1000); got != "1.5 GB" {
+		t.Errorf("si humanBytes = %q, want 1.5 GB", got)
+	}
+	if got := humanBytesShort(1000); got != "1K" {
+		t.Errorf("si humanBytesShort(1000) = %q, want 1K", got)
+	}
+	if refreshInterval != 2*time.Second {
+		t.Errorf("refreshInterval = %v", refreshInterval)
+	}
+	// Temperatures keep their defaults.
+	if percentWarn != 70 || percentDanger != 90 || tempWarn != 56 || tempDanger != 76 {
+		t.Errorf("thresholds = %v %v %v %v", percentWarn, percentDanger, tempWarn, tempDanger)
+	}
+
+	// A schema error warns and falls back to the defaults instead of stopping.
+	if err := os.WriteFile(path, []byte(`{"units": "metric"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := loadPrefs(path)
+	if err == nil || p.Units != "iec" || refreshInterval != time.Second || percentWarn != 60 {
+		t.Errorf("broken file = %+v, %v; refresh %v, warn %v", p, err, refreshInterval, percentWarn)
+	}
+
+	// A bad override is skipped; the file values stay.
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MO_UNITS", "kb")
+	p, err = loadPrefs(path)
+	if err == nil || !strings.Contains(err.Error(), "MO_UNITS") || p.Units != "si" || refreshInterval != 2*time.Second || percentWarn != 70 {
+		t.Errorf("bad override = %+v, %v; refresh %v, warn %v", p, err, refreshInterval, percentWarn)
+	}
+}
+
 func TestSplitDisks(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -978,3 +1034,68 @@ func containsMiddle(s, substr string) bool {
 	}
 	return false
 }
+
+func TestBuildCardsDrawsTrends(t *testing.T) {
+	snapshot := MetricsSnapshot{
+		CPU:    CPUStatus{Usage: 50, PerCore: []float64{50}, LogicalCPU: 1},
+		Memory: MemoryStatus{UsedPercent: 40, Total: 8 << 30},
+		GPU:    []GPUStatus{{Name: "M3", Usage: 30}},
+	}
+	var hist MetricsHistory
+	hist.Series[histCPU] = []float64{10, 90}
+	hist.Series[histMemory] = []float64{40, 40}
+	hist.Series[histGPU] = []float64{20, 40}
+
+	cards := buildCards(snapshot, hist, graphWindows[1], 0)
+	cpu := strings.Join(cards[0].lines, "\n")
+	if !strings.Contains(cpu, "Trend") || !strings.Contains(cpu, "avg 50.0% · 1h") {
+		t.Errorf("cpu card missing trend line:\n%s", cpu)
+	}
+	if mem := strings.Join(cards[1].lines, "\n"); !strings.Contains(mem, "avg 40.0% · 1h") {
+		t.Errorf("memory card missing trend line:\n%s", mem)
+	}
+	if gpu := strings.Join(cards[len(cards)-1].lines, "\n"); cards[len(cards)-1].id != "gpu" || !strings.Contains(gpu, "avg 30.0% · 1h") {
+		t.Errorf("gpu card missing trend line:\n%s", gpu)
+	}
+
+	cards = buildCards(snapshot, MetricsHistory{}, graphWindows[0], 0)
+	if cpu := strings.Join(cards[0].lines, "\n"); strings.Contains(cpu, "Trend") {
+		t.Errorf("trend line should need at least %d points:\n%s", minTrendPoints, cpu)
+	}
+}
+
+func TestLiveGraphWindowLabelFollowsRefreshInterval(t *testing.T) {
+	saved := refreshInterval
+	t.Cleanup(func() { refreshInterval = saved })
+
+	for interval, want := range map[time.Duration]string{
+		250 * time.Millisecond: "30s",
+		time.Second:            "2m",
+		10 * time.Minute:       "20h",
+	} {
+		refreshInterval = interval
+		if got := graphWindows[0].Label(); got != want {
+			t.Errorf("live window at %v = %q, want %q", interval, got, want)
+		}
+	}
+	if got := graphWindows[1].Label(); got != "1h" {
+		t.Errorf("history window = %q, want 1h", got)
+	}
+}
+
+func TestGraphWindowKeyNeedsHistory(t *testing.T) {
+	m := newModel(nil, nil, nil)
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if next.(model).graphWindow != 0 || !strings.Contains(next.(model).focusHint(), "--history") {
+		t.Errorf("window should stay live without a history store, with a hint why")
+	}
+
+	m = newModel(nil, &historyStore{tiers: defaultHistoryTiers()}, nil)
+	for range len(graphWindows) {
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+		m = next.(model)
+	}
+	if m.graphWindow != 0 {
+		t.Errorf("window should wrap around, got %d", m.graphWindow)
+	}
+}
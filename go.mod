@@ -5,6 +5,7 @@ go 1.24.2
 toolchain go1.24.6
 
 require (
+	github.com/Microsoft/go-winio v0.6.2
 	github.com/cespare/xxhash/v2 v2.3.0
 	github.com/charmbracelet/bubbletea v1.3.10
 	github.com/charmbracelet/lipgloss v1.1.0
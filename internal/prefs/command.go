@@ -0,0 +1,106 @@
+package prefs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const usage = `Usage: mole config <command>
+
+Commands:
+  list                  Show every key, its value and where it comes from
+  get KEY               Print one value
+  set KEY VALUE         Validate and save a value
+  unset KEY             Restore a key's default
+  path                  Print the preferences file
+  check                 Validate the file and MO_* overrides
+
+Keys:
+`
+
+// commands maps each subcommand to its arguments.
+var commands = map[string]string{
+	"list": "list", "get": "get KEY", "set": "set KEY VALUE", "unset": "unset KEY", "path": "path", "check": "check",
+}
+
+// RunCommand implements "mole config ..." and returns the process exit code.
+func RunCommand(args []string, out, errOut io.Writer) int {
+	return runCommand(args, Path(), os.Getenv, out, errOut)
+}
+
+func runCommand(args []string, path string, getenv func(string) string, out, errOut io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(out, usage)
+		for _, k := range keys {
+			fmt.Fprintf(out, "  %-27s%s\n", k.name, k.doc)
+		}
+		fmt.Fprintln(out, "\nAny key can be overridden for one run with MO_<KEY>, e.g. MO_UNITS=si.")
+		return 0
+	}
+
+	synopsis, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(errOut, "unknown config command %q\n", args[0])
+		return 2
+	}
+	if len(args) != len(strings.Fields(synopsis)) {
+		fmt.Fprintf(errOut, "usage: mole config %s\n", synopsis)
+		return 2
+	}
+	if args[0] == "path" {
+		fmt.Fprintln(out, path)
+		return 0
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		fmt.Fprintf(errOut, "config: %v\n", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "list":
+		for _, k := range keys {
+			source := ""
+			if v := getenv(k.env()); v != "" {
+				source = "  # " + k.env() + "=" + v
+			}
+			fmt.Fprintf(out, "%s = %s%s\n", k.name, k.get(&p), source)
+		}
+	case "get":
+		effective, err := ApplyEnv(p, getenv)
+		if err != nil {
+			fmt.Fprintf(errOut, "config: %v\n", err)
+			return 1
+		}
+		v, err := Get(effective, args[1])
+		if err != nil {
+			fmt.Fprintf(errOut, "config: %v\n", err)
+			return 2
+		}
+		fmt.Fprintln(out, v)
+	case "set", "unset":
+		if args[0] == "set" {
+			err = Set(&p, args[1], args[2])
+		} else {
+			err = Unset(&p, args[1])
+		}
+		if err != nil {
+			fmt.Fprintf(errOut, "config: %v\n", err)
+			return 2
+		}
+		if err := Save(path, p); err != nil {
+			fmt.Fprintf(errOut, "config: %v\n", err)
+			return 1
+		}
+	case "check":
+		if _, err := ApplyEnv(p, getenv); err != nil {
+			fmt.Fprintf(errOut, "config: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(out, "%s: ok\n", path)
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package prefs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Health holds the weights and thresholds behind the status health score.
+type Health struct {
+	CPU            HealthLimits   `json:"cpu"`
+	Memory         HealthLimits   `json:"memory"`
+	MemoryPressure MemoryPressure `json:"memory_pressure"`
+	Disk           HealthLimits   `json:"disk"` // Each mount is scored; the worst one counts
+	DiskRules      []DiskRule     `json:"disk_rules,omitempty"`
+	ExternalDisks  bool           `json:"external_disks"` // Score external disks too
+	Thermal        HealthLimits   `json:"thermal"`        // CPU °C
+	IO             HealthLimits   `json:"io"`             // Read+write MB/s
+}
+
+// HealthLimits is the penalty curve of one component. For percentages the
+// penalty grows to half the weight between Normal and High, then towards the
+// full weight at 100%. Other values ramp to the full weight at High.
+type HealthLimits struct {
+	Weight float64 `json:"weight"` // Most points the component can cost
+	Normal float64 `json:"normal"` // No penalty at or below
+	High   float64 `json:"high"`   // Flagged as an issue above
+}
+
+// MemoryPressure is what warn and critical memory pressure cost.
+type MemoryPressure struct {
+	Warn     float64 `json:"warn"`
+	Critical float64 `json:"critical"`
+}
+
+// DiskRule overrides the disk limits for one mount point.
+type DiskRule struct {
+	Mount  string  `json:"mount"`
+	Normal float64 `json:"normal"`
+	High   float64 `json:"high"`
+	Ignore bool    `json:"ignore,omitempty"`
+}
+
+func defaultHealth() Health {
+	return Health{
+		CPU:            HealthLimits{Weight: 30, Normal: 30, High: 70},
+		Memory:         HealthLimits{Weight: 25, Normal: 50, High: 80},
+		MemoryPressure: MemoryPressure{Warn: 5, Critical: 15},
+		Disk:           HealthLimits{Weight: 20, Normal: 70, High: 90},
+		Thermal:        HealthLimits{Weight: 15, Normal: 60, High: 85},
+		IO:             HealthLimits{Weight: 10, Normal: 50, High: 150},
+	}
+}
+
+// Validate checks that weights are not negative and every normal is below
+// its high.
+func (h Health) Validate() error {
+	limits := []struct {
+		name string
+		l    HealthLimits
+	}{{"cpu", h.CPU}, {"memory", h.Memory}, {"disk", h.Disk}, {"thermal", h.Thermal}, {"io", h.IO}}
+	for _, c := range limits {
+		if c.l.Weight < 0 {
+			return fmt.Errorf("health: %s: weight must not be negative", c.name)
+		}
+		if c.l.Normal >= c.l.High {
+			return fmt.Errorf("health: %s: normal (%g) must be below high (%g)", c.name, c.l.Normal, c.l.High)
+		}
+	}
+	if h.MemoryPressure.Warn < 0 || h.MemoryPressure.Critical < 0 {
+		return errors.New("health: memory_pressure: penalties must not be negative")
+	}
+	for _, r := range h.DiskRules {
+		if r.Mount == "" {
+			return errors.New("health: disk_rules: mount is required")
+		}
+		if !r.Ignore && r.Normal >= r.High {
+			return fmt.Errorf("health: disk_rules %s: normal (%g) must be below high (%g)", r.Mount, r.Normal, r.High)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package prefs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// key is one setting reachable by name from mole config and the environment.
+type key struct {
+	name string
+	doc  string
+	get  func(*Prefs) string
+	set  func(*Prefs, string) error
+}
+
+// env is the variable overriding the key, MO_ plus the name in capitals.
+func (k key) env() string {
+	return "MO_" + strings.ToUpper(strings.ReplaceAll(k.name, ".", "_"))
+}
+
+var keys = []key{
+	{
+		name: "refresh_interval",
+		doc:  "how often status collects, e.g. 2s",
+		get:  func(p *Prefs) string { return time.Duration(p.RefreshInterval).String() },
+		set:  func(p *Prefs, v string) error { return p.RefreshInterval.UnmarshalText([]byte(v)) },
+	},
+	{
+		name: "units",
+		doc:  "iec (powers of 1024) or si (powers of 1000)",
+		get:  func(p *Prefs) string { return p.Units },
+		set:  func(p *Prefs, v string) error { p.Units = strings.ToLower(v); return nil },
+	},
+	{
+		name: "theme",
+		doc:  "dark, light or mono",
+		get:  func(p *Prefs) string { return p.Theme },
+		set:  func(p *Prefs, v string) error { p.Theme = strings.ToLower(v); return nil },
+	},
+	floatKey("thresholds.warn_percent", "usage shown in yellow from here", func(p *Prefs) *float64 { return &p.Thresholds.WarnPercent }),
+	floatKey("thresholds.danger_percent", "usage shown in red from here", func(p *Prefs) *float64 { return &p.Thresholds.DangerPercent }),
+	floatKey("thresholds.warn_temp", "CPU °C shown in yellow from here", func(p *Prefs) *float64 { return &p.Thresholds.WarnTemp }),
+	floatKey("thresholds.danger_temp", "CPU °C shown in red from here", func(p *Prefs) *float64 { return &p.Thresholds.DangerTemp }),
+	{
+		name: "thresholds.health",
+		doc:  `health score weights and limits as JSON, e.g. {"cpu":{"weight":40,"normal":30,"high":70}}`,
+		get: func(p *Prefs) string {
+			data, _ := json.Marshal(p.Thresholds.Health)
+			return string(data)
+		},
+		set: func(p *Prefs, v string) error {
+			h := defaultHealth() // Components left out keep their defaults
+			dec := json.NewDecoder(strings.NewReader(v))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&h); err != nil {
+				return err
+			}
+			p.Thresholds.Health = h
+			return nil
+		},
+	},
+	{
+		name: "status.cat_hidden",
+		doc:  "hide the status cat (k)",
+		get:  func(p *Prefs) string { return strconv.FormatBool(p.Status.CatHidden) },
+		set: func(p *Prefs, v string) error {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("want true or false, got %q", v)
+			}
+			p.Status.CatHidden = b
+			return nil
+		},
+	},
+	{
+		name: "status.layout",
+		doc:  `dashboard cards as JSON, e.g. {"cards":[{"id":"gpu"}]}`,
+		get: func(p *Prefs) string {
+			data, _ := json.Marshal(p.Status.Layout)
+			return string(data)
+		},
+		set: func(p *Prefs, v string) error {
+			var l Layout
+			dec := json.NewDecoder(strings.NewReader(v))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&l); err != nil {
+				return err
+			}
+			p.Status.Layout = l
+			return nil
+		},
+	},
+}
+
+func floatKey(name, doc string, field func(*Prefs) *float64) key {
+	return key{
+		name: name,
+		doc:  doc,
+		get:  func(p *Prefs) string { return strconv.FormatFloat(*field(p), 'g', -1, 64) },
+		set: func(p *Prefs, v string) error {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return fmt.Errorf("want a number, got %q", v)
+			}
+			*field(p) = f
+			return nil
+		},
+	}
+}
+
+func lookup(name string) (key, error) {
+	for _, k := range keys {
+		if k.name == name {
+			return k, nil
+		}
+	}
+	var names bytes.Buffer
+	for i, k := range keys {
+		if i > 0 {
+			names.WriteString(", ")
+		}
+		names.WriteString(k.name)
+	}
+	return key{}, fmt.Errorf("unknown key %q (keys: %s)", name, names.String())
+}
+
+// Get returns a key's value as mole config prints it.
+func Get(p Prefs, name string) (string, error) {
+	k, err := lookup(name)
+	if err != nil {
+		return "", err
+	}
+	return k.get(&p), nil
+}
+
+// Set parses value into a key and validates the result.
+func Set(p *Prefs, name, value string) error {
+	k, err := lookup(name)
+	if err != nil {
+		return err
+	}
+	next := *p
+	if err := k.set(&next, value); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if err := next.Validate(); err != nil {
+		return err
+	}
+	*p = next
+	return nil
+}
+
+// Unset restores a key's default.
+func Unset(p *Prefs, name string) error {
+	k, err := lookup(name)
+	if err != nil {
+		return err
+	}
+	def := Defaults()
+	return Set(p, name, k.get(&def))
+}
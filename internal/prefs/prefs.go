@@ -0,0 +1,277 @@
+// Package prefs reads and writes the preferences shared by mole's Go tools.
+//
+// Preferences live in ~/.config/mole/config.json (MO_CONFIG_FILE overrides
+// the path):
+//
+//	{
+//	  "refresh_interval": "2s",
+//	  "units": "si",
+//	  "theme": "light",
+//	  "thresholds": {"warn_percent": 70, "danger_percent": 90, "health": {"cpu": {"weight": 40}}},
+//	  "status": {"cat_hidden": true, "layout": {"cards": [{"id": "gpu"}]}}
+//	}
+//
+// Missing keys keep their defaults and unknown keys are an error. Every key
+// can be overridden for one run with MO_<KEY>, dots becoming underscores:
+// MO_UNITS=si, MO_THRESHOLDS_WARN_PERCENT=70. Before the first save, the
+// older status_prefs file is read instead. Alert rules and probe targets stay in their own line-based
+// files (status_alerts, status_probes): they are lists edited by hand, not
+// settings with defaults.
+package prefs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const fileName = "config.json"
+
+// Prefs is the whole preferences file.
+type Prefs struct {
+	RefreshInterval Duration   `json:"refresh_interval"`
+	Units           string     `json:"units"` // iec (powers of 1024) or si (powers of 1000)
+	Theme           string     `json:"theme"` // dark, light or mono
+	Thresholds      Thresholds `json:"thresholds"`
+	Status          Status     `json:"status"`
+}
+
+// Thresholds are where usage and temperatures turn yellow and red.
+type Thresholds struct {
+	WarnPercent   float64 `json:"warn_percent"`
+	DangerPercent float64 `json:"danger_percent"`
+	WarnTemp      float64 `json:"warn_temp"`   // °C
+	DangerTemp    float64 `json:"danger_temp"` // °C
+	Health        Health  `json:"health"`
+}
+
+// Status holds state the status dashboard saves as it runs.
+type Status struct {
+	CatHidden bool   `json:"cat_hidden"`
+	Layout    Layout `json:"layout"`
+}
+
+// Layout places the status dashboard cards. Cards listed come first, in
+// order; the rest keep the dashboard's default order.
+type Layout struct {
+	Cards []Card `json:"cards,omitempty"`
+}
+
+// Card is one card's placement.
+type Card struct {
+	ID      string `json:"id"`
+	Column  string `json:"column,omitempty"`  // left, right or empty
+	Variant string `json:"variant,omitempty"` // compact, expanded or empty
+	Hidden  bool   `json:"hidden,omitempty"`
+}
+
+// Duration is a time.Duration written as "1s" or "500ms".
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Defaults returns the preferences used when nothing is set.
+func Defaults() Prefs {
+	return Prefs{
+		RefreshInterval: Duration(time.Second),
+		Units:           "iec",
+		Theme:           "dark",
+		Thresholds:      Thresholds{WarnPercent: 60, DangerPercent: 85, WarnTemp: 56, DangerTemp: 76, Health: defaultHealth()},
+	}
+}
+
+// ByteUnit is 1024 for iec units and 1000 for si.
+func (p Prefs) ByteUnit() int64 {
+	if p.Units == "si" {
+		return 1000
+	}
+	return 1024
+}
+
+// Validate reports the first value out of range.
+func (p Prefs) Validate() error {
+	if d := time.Duration(p.RefreshInterval); d < 250*time.Millisecond || d > 10*time.Minute {
+		return fmt.Errorf("refresh_interval must be between 250ms and 10m, got %s", d)
+	}
+	if p.Units != "iec" && p.Units != "si" {
+		return fmt.Errorf("units must be iec or si, got %q", p.Units)
+	}
+	if p.Theme != "dark" && p.Theme != "light" && p.Theme != "mono" {
+		return fmt.Errorf("theme must be dark, light or mono, got %q", p.Theme)
+	}
+	t := p.Thresholds
+	if t.WarnPercent <= 0 || t.WarnPercent >= t.DangerPercent || t.DangerPercent > 100 {
+		return fmt.Errorf("thresholds: need 0 < warn_percent < danger_percent <= 100, got %g and %g", t.WarnPercent, t.DangerPercent)
+	}
+	if t.WarnTemp <= 0 || t.WarnTemp >= t.DangerTemp {
+		return fmt.Errorf("thresholds: need 0 < warn_temp < danger_temp, got %g and %g", t.WarnTemp, t.DangerTemp)
+	}
+	if err := t.Health.Validate(); err != nil {
+		return err
+	}
+	return p.Status.Layout.Validate()
+}
+
+// Validate checks card ids, columns and variants. Ids are not checked
+// against the dashboard so cards from extensions can be placed too.
+func (l Layout) Validate() error {
+	seen := make(map[string]bool)
+	for i, c := range l.Cards {
+		switch {
+		case c.ID == "":
+			return fmt.Errorf("layout: cards[%d]: id is required", i)
+		case seen[c.ID]:
+			return fmt.Errorf("layout: cards[%d]: %q is listed twice", i, c.ID)
+		case c.Column != "" && c.Column != "left" && c.Column != "right":
+			return fmt.Errorf("layout: %s: column must be left or right, got %q", c.ID, c.Column)
+		case c.Variant != "" && c.Variant != "compact" && c.Variant != "expanded":
+			return fmt.Errorf("layout: %s: variant must be compact or expanded, got %q", c.ID, c.Variant)
+		}
+		seen[c.ID] = true
+	}
+	return nil
+}
+
+// Path returns the preferences file, ~/.config/mole/config.json unless
+// MO_CONFIG_FILE names another.
+func Path() string {
+	if path := os.Getenv("MO_CONFIG_FILE"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "mole", fileName)
+}
+
+// Load reads the preferences file without environment overrides. A missing
+// file gives the defaults, plus the older status_prefs file beside it.
+func Load(path string) (Prefs, error) {
+	p := Defaults()
+	if path == "" {
+		return p, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		loadLegacy(filepath.Dir(path), &p)
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return Defaults(), fmt.Errorf("%s: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return Defaults(), fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// loadLegacy reads status_prefs, which held cat_hidden=true.
+func loadLegacy(dir string, p *Prefs) {
+	f, err := os.Open(filepath.Join(dir, "status_prefs"))
+	if err != nil {
+		return
+	}
+	defer f.Close() //nolint:errcheck
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "cat_hidden=true" {
+			p.Status.CatHidden = true
+		}
+	}
+}
+
+// Save validates p and writes it as the whole file.
+func Save(path string, p Prefs) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Update applies change to the file's current contents and saves it, so
+// tools running side by side do not undo each other's edits.
+func Update(path string, change func(*Prefs)) error {
+	if path == "" {
+		return nil
+	}
+	p, err := Load(path)
+	if err != nil {
+		return err
+	}
+	change(&p)
+	return Save(path, p)
+}
+
+// ApplyEnv returns p with the MO_<KEY> overrides set in the environment.
+// An override that does not parse or leaves p invalid is skipped and named
+// in the error; the others still apply.
+func ApplyEnv(p Prefs, getenv func(string) string) (Prefs, error) {
+	pending := make(map[string]error)
+	var order []key
+	for _, k := range keys {
+		if getenv(k.env()) != "" {
+			order = append(order, k)
+		}
+	}
+	// Overrides are retried until none applies, so a pair such as the warn
+	// and danger percents can be raised together in either order.
+	for progress := true; progress; {
+		progress = false
+		var rest []key
+		for _, k := range order {
+			next := p
+			err := k.set(&next, getenv(k.env()))
+			if err == nil {
+				err = next.Validate()
+			}
+			if err != nil {
+				pending[k.name] = fmt.Errorf("%s: %w", k.env(), err)
+				rest = append(rest, k)
+				continue
+			}
+			p = next
+			progress = true
+		}
+		order = rest
+	}
+
+	var errs []error
+	for _, k := range order {
+		errs = append(errs, pending[k.name])
+	}
+	return p, errors.Join(errs...)
+}
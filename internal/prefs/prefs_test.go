@@ -0,0 +1,181 @@
+package prefs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if p, err := Load(path); err != nil || p.Units != "iec" || p.RefreshInterval != Duration(time.Second) {
+		t.Errorf("a missing file should be the defaults, got %+v, %v", p, err)
+	}
+
+	tests := []struct {
+		body    string
+		wantErr string
+	}{
+		{`{"units": "si", "thresholds": {"warn_percent": 70, "danger_percent": 90}}`, ""},
+		{`{"refresh_interval": "2s", "status": {"layout": {"cards": [{"id": "gpu", "column": "left"}]}}}`, ""},
+		{`{"refresh_interval": "soon"}`, "invalid duration"},
+		{`{"refresh_interval": "10ms"}`, "refresh_interval must be between"},
+		{`{"units": "kib"}`, "units must be iec or si"},
+		{`{"theme": "neon"}`, "theme must be dark, light or mono"},
+		{`{"thresholds": {"warn_percent": 90}}`, "warn_percent < danger_percent"},
+		{`{"status": {"layout": {"cards": [{"id": "cpu"}, {"id": "cpu"}]}}}`, "listed twice"},
+		{`{"status": {"layout": {"cards": [{"id": "cpu", "variant": "tiny"}]}}}`, "variant must be compact or expanded"},
+		{`{"thresholds": {"health": {"cpu": {"weight": 50, "normal": 50, "high": 90}}}}`, ""},
+		{`{"thresholds": {"health": {"disk": {"normal": 90, "high": 70}}}}`, "health: disk: normal (90) must be below high (70)"},
+		{`{"thresholds": {"health": {"disk_rules": [{"normal": 50, "high": 60}]}}}`, "mount is required"},
+		{`{"thresholds": {"health": {"cpu_weight": 10}}}`, "unknown field"},
+		{`{"colour": "blue"}`, "unknown field"},
+	}
+	for _, tt := range tests {
+		writeFile(t, path, tt.body)
+		p, err := Load(path)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: %v", tt.body, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: error = %v, want %q", tt.body, err, tt.wantErr)
+		case tt.wantErr == "" && (p.Thresholds.DangerTemp != 76 || p.Thresholds.Health.Memory != defaultHealth().Memory):
+			t.Errorf("%s: keys not in the file should keep their defaults, got %+v", tt.body, p.Thresholds)
+		}
+	}
+}
+
+func TestLoadReadsLegacyStatusPrefs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "status_prefs"), "cat_hidden=true\n")
+	path := filepath.Join(dir, "config.json")
+
+	p, err := Load(path)
+	if err != nil || !p.Status.CatHidden {
+		t.Fatalf("legacy prefs = %+v, %v", p.Status, err)
+	}
+
+	// The first save moves it into config.json, which wins from then on.
+	if err := Update(path, func(p *Prefs) { p.Theme = "mono" }); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "status_prefs"), "cat_hidden=false\n")
+	p, err = Load(path)
+	if err != nil || !p.Status.CatHidden || p.Theme != "mono" {
+		t.Errorf("after saving = %+v, %v", p, err)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{"MO_UNITS": "SI", "MO_THRESHOLDS_DANGER_PERCENT": "95", "MO_STATUS_CAT_HIDDEN": "1"}
+	p, err := ApplyEnv(Defaults(), func(k string) string { return env[k] })
+	if err != nil || p.Units != "si" || p.Thresholds.DangerPercent != 95 || !p.Status.CatHidden {
+		t.Errorf("ApplyEnv = %+v, %v", p, err)
+	}
+
+	env = map[string]string{"MO_REFRESH_INTERVAL": "fast"}
+	if _, err := ApplyEnv(Defaults(), func(k string) string { return env[k] }); err == nil || !strings.Contains(err.Error(), "MO_REFRESH_INTERVAL") {
+		t.Errorf("a bad override should name its variable, got %v", err)
+	}
+
+	base := Defaults()
+	base.Theme = "mono"
+	env = map[string]string{"MO_UNITS": "kb", "MO_STATUS_CAT_HIDDEN": "true",
+		"MO_THRESHOLDS_WARN_PERCENT": "95", "MO_THRESHOLDS_DANGER_PERCENT": "99"}
+	p, err = ApplyEnv(base, func(k string) string { return env[k] })
+	if err == nil || !strings.Contains(err.Error(), "MO_UNITS") {
+		t.Errorf("the invalid unit should be reported, got %v", err)
+	}
+	if p.Theme != "mono" || p.Units != Defaults().Units || !p.Status.CatHidden || p.Thresholds.WarnPercent != 95 || p.Thresholds.DangerPercent != 99 {
+		t.Errorf("valid overrides should apply over the file values, got %+v", p)
+	}
+}
+
+func TestSetAndUnset(t *testing.T) {
+	p := Defaults()
+	if err := Set(&p, "thresholds.warn_percent", "90"); err == nil {
+		t.Errorf("warn above danger should be rejected")
+	}
+	if p.Thresholds.WarnPercent != 60 {
+		t.Errorf("a rejected Set changed the prefs: %+v", p.Thresholds)
+	}
+	if err := Set(&p, "status.layout", `{"cards":[{"id":"gpu","variant":"compact"}]}`); err != nil {
+		t.Fatalf("Set layout: %v", err)
+	}
+	if v, _ := Get(p, "status.layout"); v != `{"cards":[{"id":"gpu","variant":"compact"}]}` {
+		t.Errorf("Get layout = %s", v)
+	}
+	if err := Unset(&p, "status.layout"); err != nil || len(p.Status.Layout.Cards) != 0 {
+		t.Errorf("Unset layout = %+v, %v", p.Status.Layout, err)
+	}
+	if err := Set(&p, "thresholds.health", `{"io":{"weight":0,"normal":100,"high":400},"disk_rules":[{"mount":"/data","ignore":true}]}`); err != nil {
+		t.Fatalf("Set health: %v", err)
+	}
+	if h := p.Thresholds.Health; h.IO.High != 400 || len(h.DiskRules) != 1 || h.CPU != defaultHealth().CPU {
+		t.Errorf("Set health = %+v", h)
+	}
+	if err := Set(&p, "thresholds.health", `{"cpu":{"weight":-1}}`); err == nil {
+		t.Errorf("a negative weight should be rejected")
+	}
+	if err := Unset(&p, "thresholds.health"); err != nil || len(p.Thresholds.Health.DiskRules) != 0 || p.Thresholds.Health.IO != defaultHealth().IO {
+		t.Errorf("Unset health = %+v, %v", p.Thresholds.Health, err)
+	}
+	if _, err := Get(p, "colour"); err == nil || !strings.Contains(err.Error(), "refresh_interval") {
+		t.Errorf("an unknown key should list the known ones, got %v", err)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mole", "config.json")
+	env := map[string]string{}
+	run := func(args ...string) (int, string, string) {
+		var out, errOut bytes.Buffer
+		code := runCommand(args, path, func(k string) string { return env[k] }, &out, &errOut)
+		return code, out.String(), errOut.String()
+	}
+
+	if code, out, _ := run("set", "refresh_interval", "3s"); code != 0 || out != "" {
+		t.Fatalf("set = %d %q", code, out)
+	}
+	if code, out, _ := run("get", "refresh_interval"); code != 0 || out != "3s\n" {
+		t.Errorf("get = %d %q", code, out)
+	}
+	if code, _, errOut := run("set", "units", "kib"); code != 2 || !strings.Contains(errOut, "units must be iec or si") {
+		t.Errorf("bad set = %d %q", code, errOut)
+	}
+	if code, _, errOut := run("get"); code != 2 || errOut != "usage: mole config get KEY\n" {
+		t.Errorf("get without a key = %d %q", code, errOut)
+	}
+
+	env["MO_THEME"] = "mono"
+	_, out, _ := run("list")
+	if !strings.Contains(out, "refresh_interval = 3s\n") || !strings.Contains(out, "theme = dark  # MO_THEME=mono\n") {
+		t.Errorf("list =\n%s", out)
+	}
+	if _, out, _ := run("get", "theme"); out != "mono\n" {
+		t.Errorf("get should include overrides, got %q", out)
+	}
+
+	if code, _, _ := run("unset", "refresh_interval"); code != 0 {
+		t.Errorf("unset = %d", code)
+	}
+	if p, err := Load(path); err != nil || p.RefreshInterval != Duration(time.Second) {
+		t.Errorf("after unset = %+v, %v", p, err)
+	}
+
+	env["MO_THEME"] = "neon"
+	if code, _, errOut := run("check"); code != 1 || !strings.Contains(errOut, "theme must be") {
+		t.Errorf("check = %d %q", code, errOut)
+	}
+}
@@ -6,7 +6,7 @@
     A safe and thorough system cleaner for Windows.
     Cleans temp files, browser caches, developer tool caches, and more.
 .PARAMETER Command
-    The command to run: clean, analyze, status, whitelist, purge, optimize, help, version
+    The command to run: clean, analyze, status, config, whitelist, purge, optimize, help, version
 .PARAMETER DryRun
     Preview what would be cleaned without deleting anything.
 .PARAMETER Quick
@@ -39,7 +39,7 @@
 [CmdletBinding()]
 param(
     [Parameter(Position = 0)]
-    [ValidateSet("clean", "analyze", "status", "whitelist", "purge", "optimize", "media", "dupes", "large", "help", "version", "")]
+    [ValidateSet("clean", "analyze", "status", "config", "whitelist", "purge", "optimize", "media", "dupes", "large", "help", "version", "")]
     [string]$Command = "",
 
     [Alias("n")]
@@ -228,6 +228,7 @@ COMMANDS:
     clean           Clean system caches and temp files
     analyze         Analyze disk usage (requires Go TUI)
     status          Show system status (requires Go TUI)
+    config          Get and set status/analyze preferences
     whitelist       Manage protected paths
     purge           Clean project build artifacts
     optimize        Optimize system (cache rebuild, service refresh)
@@ -466,6 +467,24 @@ function Invoke-StatusCommand {
     }
 }
 
+# ============================================================================
+# Config Command
+# ============================================================================
+function Invoke-ConfigCommand {
+    # Preferences are read and written by the status binary
+    $statusExe = Join-Path $MOLE_ROOT "bin\status.exe"
+
+    if (Test-Path $statusExe) {
+        & $statusExe config $RemainingArgs
+    }
+    else {
+        Write-Host "Status binary not found; it provides 'mole config'." -ForegroundColor Yellow
+        Write-Host ""
+        Write-Host "Build it with:"
+        Write-Host "  go build -o windows/bin/status.exe ./cmd/status" -ForegroundColor DarkGray
+    }
+}
+
 # ============================================================================
 # Purge Command
 # ============================================================================
@@ -1357,6 +1376,9 @@ function Main {
         "status" {
             Invoke-StatusCommand
         }
+        "config" {
+            Invoke-ConfigCommand
+        }
         "whitelist" {
             Invoke-WhitelistCommand
         }